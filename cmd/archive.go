@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/goreleaser/goreleaser/pkg/archive"
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
+	"github.com/spf13/cobra"
+)
+
+type archiveCmd struct {
+	cmd *cobra.Command
+}
+
+func newArchiveCmd() *archiveCmd {
+	root := &archiveCmd{}
+	cmd := &cobra.Command{
+		Use:           "archive",
+		Short:         "Inspects archives",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+	}
+	cmd.AddCommand(newArchiveLsCmd().cmd)
+
+	root.cmd = cmd
+	return root
+}
+
+type archiveLsCmd struct {
+	cmd *cobra.Command
+}
+
+func newArchiveLsCmd() *archiveLsCmd {
+	root := &archiveLsCmd{}
+	cmd := &cobra.Command{
+		Use:           "ls [archive...]",
+		Aliases:       []string{"list"},
+		Short:         "Lists the files inside archives",
+		Example:       "goreleaser archive ls dist/foo_1.0.0_linux_amd64.tar.gz",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for i, path := range args {
+				entries, err := archive.List(path)
+				if err != nil {
+					return err
+				}
+				if len(args) > 1 {
+					if i > 0 {
+						fmt.Fprintln(w)
+					}
+					fmt.Fprintf(w, "%s:\n", path)
+				}
+				for _, e := range entries {
+					fmt.Fprintln(w, formatEntry(e))
+				}
+			}
+			return w.Flush()
+		},
+	}
+
+	root.cmd = cmd
+	return root
+}
+
+func formatEntry(e entry.Entry) string {
+	owner := "-"
+	if e.Owner != "" || e.Group != "" {
+		owner = e.Owner + "/" + e.Group
+	}
+	mtime := "-"
+	if !e.MTime.IsZero() {
+		mtime = e.MTime.UTC().Format("2006-01-02 15:04")
+	}
+	name := e.Name
+	if e.Linkname != "" {
+		name += " -> " + e.Linkname
+	}
+	return fmt.Sprintf("%s\t%s\t%d\t%s\t%s", e.Mode, owner, e.Size, mtime, name)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/archive"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveLs(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, "foo.tar.gz")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	a := archive.New(file)
+	require.NoError(t, a.Add(config.File{
+		Source:      "testdata/good.yml",
+		Destination: "foo/good.yml",
+		Info: config.FileInfo{
+			Owner: "root",
+			Group: "root",
+			Mode:  0o644,
+			MTime: time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}))
+	require.NoError(t, a.Close())
+	require.NoError(t, file.Close())
+	info, err := os.Stat("testdata/good.yml")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	cmd := newArchiveCmd().cmd
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"ls", path})
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), "-rw-r--r--")
+	require.Contains(t, out.String(), "root/root")
+	require.Contains(t, out.String(), "2021-11-01 10:00")
+	require.Contains(t, out.String(), "foo/good.yml")
+	require.Contains(t, out.String(), strconv.FormatInt(info.Size(), 10))
+}
+
+func TestArchiveLsNotFound(t *testing.T) {
+	cmd := newArchiveCmd().cmd
+	cmd.SetArgs([]string{"ls", "testdata/nope.tar.gz"})
+	require.EqualError(t, cmd.Execute(), "open testdata/nope.tar.gz: no such file or directory")
+}
+
+func TestArchiveLsNoArgs(t *testing.T) {
+	cmd := newArchiveCmd().cmd
+	cmd.SetArgs([]string{"ls"})
+	require.Error(t, cmd.Execute())
+}
@@ -84,6 +84,7 @@ single .goreleaser.yaml file.
 		newInitCmd().cmd,
 		newDocsCmd().cmd,
 		newSchemaCmd().cmd,
+		newArchiveCmd().cmd,
 	)
 
 	root.cmd = cmd
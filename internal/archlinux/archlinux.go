@@ -0,0 +1,469 @@
+// Package archlinux implements the nfpm.Packager interface providing Arch
+// Linux packages (.pkg.tar.zst).
+//
+// Importing it registers the "archlinux" format within nfpm, the same way the
+// apk, deb and rpm packagers do.
+package archlinux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" // #nosec
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/klauspost/compress/zstd"
+)
+
+const packagerName = "archlinux"
+
+// nolint: gochecknoinits
+func init() {
+	nfpm.RegisterPackager(packagerName, Default)
+}
+
+// Default archlinux packager.
+// nolint: gochecknoglobals
+var Default = ArchLinux{}
+
+// ArchLinux is an Arch Linux packager implementation.
+type ArchLinux struct{}
+
+// Extension of Arch Linux packages.
+const Extension = ".pkg.tar.zst"
+
+// nolint: gochecknoglobals
+var archToArchLinux = map[string]string{
+	"all":     "any",
+	"amd64":   "x86_64",
+	"386":     "i686",
+	"arm64":   "aarch64",
+	"arm5":    "arm",
+	"arm6":    "armv6h",
+	"arm7":    "armv7h",
+	"ppc64le": "powerpc64le",
+	"riscv64": "riscv64",
+}
+
+func archlinuxArch(arch string) string {
+	if a, ok := archToArchLinux[arch]; ok {
+		return a
+	}
+	return arch
+}
+
+// ConventionalFileName returns a file name according to the conventions of
+// Arch Linux packages, e.g. foo-1.0.0-1-x86_64.pkg.tar.zst.
+func (ArchLinux) ConventionalFileName(info *nfpm.Info) string {
+	return fmt.Sprintf(
+		"%s-%s-%s-%s%s",
+		info.Name,
+		pkgver(info),
+		pkgrel(info),
+		archlinuxArch(info.Arch),
+		Extension,
+	)
+}
+
+// pkgver is the package version, which can't contain dashes.
+func pkgver(info *nfpm.Info) string {
+	version := info.Version
+	if info.Prerelease != "" {
+		version += info.Prerelease
+	}
+	if info.VersionMetadata != "" {
+		version += "+" + info.VersionMetadata
+	}
+	version = strings.ReplaceAll(version, "-", "_")
+	if info.Epoch != "" {
+		version = info.Epoch + ":" + version
+	}
+	return version
+}
+
+func pkgrel(info *nfpm.Info) string {
+	if info.Release == "" {
+		return "1"
+	}
+	return info.Release
+}
+
+// entry is something to be added to the package.
+type entry struct {
+	name    string // path inside the package, without the leading slash
+	source  string // source file, or link target for symlinks
+	typ     byte
+	mode    int64
+	owner   string
+	group   string
+	mtime   time.Time
+	size    int64
+	backup  bool
+	content []byte // set for the metadata files
+}
+
+// Package writes a new archlinux package to the given writer using the
+// given info.
+func (ArchLinux) Package(info *nfpm.Info, w io.Writer) error {
+	if err := info.Validate(); err != nil {
+		return err
+	}
+	entries, err := contents(info)
+	if err != nil {
+		return err
+	}
+
+	date := buildDate(entries)
+	pkginfo, err := pkginfo(info, entries, date)
+	if err != nil {
+		return err
+	}
+	meta := []*entry{metadata(".PKGINFO", pkginfo, date)}
+
+	install, err := installScript(info)
+	if err != nil {
+		return err
+	}
+	if install != nil {
+		meta = append(meta, metadata(".INSTALL", install, date))
+	}
+
+	mtree, err := mtree(append(meta, entries...))
+	if err != nil {
+		return err
+	}
+	meta = append(meta[:1], append([]*entry{metadata(".MTREE", mtree, date)}, meta[1:]...)...)
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+	for _, e := range append(meta, entries...) {
+		if err := write(tw, e); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// contents lists the entries of the package, including the parent
+// directories not explicitly added, sorted by path.
+func contents(info *nfpm.Info) ([]*entry, error) {
+	byName := map[string]*entry{}
+	for _, file := range info.Contents {
+		// only consider contents for this packager
+		if file.Packager != "" && file.Packager != packagerName {
+			continue
+		}
+
+		name := strings.Trim(files.ToNixPath(file.Destination), "/")
+		e := &entry{
+			name:   name,
+			source: file.Source,
+			mode:   int64(file.FileInfo.Mode.Perm()),
+			owner:  file.FileInfo.Owner,
+			group:  file.FileInfo.Group,
+			mtime:  file.FileInfo.MTime,
+		}
+		switch file.Type {
+		case "ghost":
+			// ghost files are not supported by pacman
+			continue
+		case "dir":
+			e.typ = tar.TypeDir
+		case "symlink":
+			e.typ = tar.TypeSymlink
+			e.mode = 0o777
+		case "config", "config|noreplace":
+			e.typ = tar.TypeReg
+			e.backup = true
+		default:
+			e.typ = tar.TypeReg
+		}
+		if e.typ == tar.TypeReg {
+			stat, err := os.Stat(file.Source)
+			if err != nil {
+				return nil, err
+			}
+			e.size = stat.Size()
+		}
+		byName[name] = e
+	}
+
+	for name, e := range byName {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if _, ok := byName[dir]; ok {
+				continue
+			}
+			byName[dir] = &entry{
+				name:  dir,
+				typ:   tar.TypeDir,
+				mode:  0o755,
+				owner: "root",
+				group: "root",
+				mtime: e.mtime,
+			}
+		}
+	}
+
+	result := make([]*entry, 0, len(byName))
+	for _, e := range byName {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result, nil
+}
+
+// metadata creates a metadata file entry, like the .PKGINFO.
+func metadata(name string, content []byte, mtime time.Time) *entry {
+	return &entry{
+		name:    name,
+		typ:     tar.TypeReg,
+		mode:    0o644,
+		owner:   "root",
+		group:   "root",
+		mtime:   mtime,
+		size:    int64(len(content)),
+		content: content,
+	}
+}
+
+// buildDate is the modification time of the newest file in the package, so
+// the same files always produce the same package.
+func buildDate(entries []*entry) time.Time {
+	var date time.Time
+	for _, e := range entries {
+		if e.mtime.After(date) {
+			date = e.mtime
+		}
+	}
+	if date.IsZero() {
+		return time.Now()
+	}
+	return date
+}
+
+// reference: https://wiki.archlinux.org/title/PKGINFO
+const pkginfoTemplate = `# Generated by GoReleaser
+pkgname = {{ .Info.Name }}
+pkgbase = {{ .Info.Name }}
+pkgver = {{ .Version }}-{{ .Release }}
+pkgdesc = {{ .Description }}
+{{- with .Info.Homepage }}
+url = {{ . }}
+{{- end }}
+builddate = {{ .BuildDate }}
+packager = {{ .Packager }}
+size = {{ .Size }}
+arch = {{ .Arch }}
+{{- with .Info.License }}
+license = {{ . }}
+{{- end }}
+{{- range .Info.Replaces }}
+replaces = {{ . }}
+{{- end }}
+{{- range .Info.Conflicts }}
+conflict = {{ . }}
+{{- end }}
+{{- range .Info.Provides }}
+provides = {{ . }}
+{{- end }}
+{{- range .Backup }}
+backup = {{ . }}
+{{- end }}
+{{- range .Info.Depends }}
+depend = {{ . }}
+{{- end }}
+{{- range .Info.Recommends }}
+optdepend = {{ . }}
+{{- end }}
+{{- range .Info.Suggests }}
+optdepend = {{ . }}
+{{- end }}
+`
+
+// nolint: gochecknoglobals
+var pkginfoTmpl = template.Must(template.New("pkginfo").Parse(pkginfoTemplate))
+
+func pkginfo(info *nfpm.Info, entries []*entry, date time.Time) ([]byte, error) {
+	var size int64
+	var backup []string
+	for _, e := range entries {
+		size += e.size
+		if e.backup {
+			backup = append(backup, e.name)
+		}
+	}
+	packager := info.Maintainer
+	if packager == "" {
+		packager = "Unknown Packager"
+	}
+
+	var out bytes.Buffer
+	err := pkginfoTmpl.Execute(&out, struct {
+		Info        *nfpm.Info
+		Version     string
+		Release     string
+		Description string
+		BuildDate   int64
+		Packager    string
+		Size        int64
+		Arch        string
+		Backup      []string
+	}{
+		Info:        info,
+		Version:     pkgver(info),
+		Release:     pkgrel(info),
+		Description: strings.Join(strings.Fields(info.Description), " "),
+		BuildDate:   date.Unix(),
+		Packager:    packager,
+		Size:        size,
+		Arch:        archlinuxArch(info.Arch),
+		Backup:      backup,
+	})
+	return out.Bytes(), err
+}
+
+// installScript creates the .INSTALL file from the package scripts, or
+// returns nil if there are none.
+func installScript(info *nfpm.Info) ([]byte, error) {
+	var out bytes.Buffer
+	for _, script := range []struct {
+		fn   string
+		path string
+	}{
+		{"pre_install", info.Scripts.PreInstall},
+		{"post_install", info.Scripts.PostInstall},
+		{"pre_remove", info.Scripts.PreRemove},
+		{"post_remove", info.Scripts.PostRemove},
+	} {
+		if script.path == "" {
+			continue
+		}
+		bts, err := os.ReadFile(script.path)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&out, "%s() {\n%s\n}\n\n", script.fn, strings.TrimRight(string(bts), "\n"))
+	}
+	if out.Len() == 0 {
+		return nil, nil
+	}
+	return out.Bytes(), nil
+}
+
+// mtree creates the gzipped .MTREE file, which pacman uses to validate the
+// installed files.
+func mtree(entries []*entry) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(gw, "#mtree\n/set type=file uid=0 gid=0 mode=644\n"); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		line := []string{"./" + e.name}
+		if e.owner != "" && e.owner != "root" {
+			line = append(line, "uname="+e.owner)
+		}
+		if e.group != "" && e.group != "root" {
+			line = append(line, "gname="+e.group)
+		}
+		line = append(line, fmt.Sprintf("time=%d.0", e.mtime.Unix()))
+		switch e.typ {
+		case tar.TypeDir:
+			line = append(line, fmt.Sprintf("mode=%o", e.mode), "type=dir")
+		case tar.TypeSymlink:
+			line = append(line, "mode=777", "type=link", "link="+e.source)
+		default:
+			md5sum, sha256sum, err := digests(e)
+			if err != nil {
+				return nil, err
+			}
+			if e.mode != 0o644 {
+				line = append(line, fmt.Sprintf("mode=%o", e.mode))
+			}
+			line = append(line,
+				fmt.Sprintf("size=%d", e.size),
+				"md5digest="+md5sum,
+				"sha256digest="+sha256sum,
+			)
+		}
+		if _, err := io.WriteString(gw, strings.Join(line, " ")+"\n"); err != nil {
+			return nil, err
+		}
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func digests(e *entry) (string, string, error) {
+	md5h := md5.New() // #nosec
+	sha256h := sha256.New()
+	if err := copyContent(io.MultiWriter(md5h, sha256h), e); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(md5h.Sum(nil)), hex.EncodeToString(sha256h.Sum(nil)), nil
+}
+
+func copyContent(w io.Writer, e *entry) error {
+	if e.content != nil {
+		_, err := w.Write(e.content)
+		return err
+	}
+	f, err := os.Open(e.source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func write(tw *tar.Writer, e *entry) error {
+	header := &tar.Header{
+		Name:     e.name,
+		Typeflag: e.typ,
+		Mode:     e.mode,
+		Uname:    e.owner,
+		Gname:    e.group,
+		ModTime:  e.mtime,
+		Format:   tar.FormatPAX,
+	}
+	switch e.typ {
+	case tar.TypeDir:
+		header.Name += "/"
+	case tar.TypeSymlink:
+		header.Linkname = e.source
+	default:
+		header.Size = e.size
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to the package: %w", e.name, err)
+	}
+	if e.typ != tar.TypeReg {
+		return nil
+	}
+	if err := copyContent(tw, e); err != nil {
+		return fmt.Errorf("failed to add %s to the package: %w", e.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package archlinux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistered(t *testing.T) {
+	packager, err := nfpm.Get("archlinux")
+	require.NoError(t, err)
+	require.Equal(t, Default, packager)
+}
+
+func TestConventionalFileName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		info     nfpm.Info
+		expected string
+	}{
+		"simple": {
+			info:     nfpm.Info{Name: "foo", Arch: "amd64", Version: "1.2.3"},
+			expected: "foo-1.2.3-1-x86_64.pkg.tar.zst",
+		},
+		"prerelease": {
+			info:     nfpm.Info{Name: "foo", Arch: "arm64", Version: "1.2.3-rc1", Release: "2"},
+			expected: "foo-1.2.3rc1-2-aarch64.pkg.tar.zst",
+		},
+		"epoch and metadata": {
+			info:     nfpm.Info{Name: "foo", Arch: "arm7", Version: "v1.2.3+git-abc", Epoch: "1"},
+			expected: "foo-1:1.2.3+git_abc-1-armv7h.pkg.tar.zst",
+		},
+		"unknown arch": {
+			info:     nfpm.Info{Name: "foo", Arch: "s390x", Version: "1.0.0"},
+			expected: "foo-1.0.0-1-s390x.pkg.tar.zst",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Default.ConventionalFileName(nfpm.WithDefaults(&tt.info)))
+		})
+	}
+}
+
+func TestPackage(t *testing.T) {
+	folder := t.TempDir()
+	binary := filepath.Join(folder, "foo")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\necho foo"), 0o755))
+	conf := filepath.Join(folder, "foo.conf")
+	require.NoError(t, os.WriteFile(conf, []byte("a=b"), 0o644))
+	script := filepath.Join(folder, "postinstall.sh")
+	require.NoError(t, os.WriteFile(script, []byte("echo installed\n"), 0o644))
+
+	mtime := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	info := nfpm.WithDefaults(&nfpm.Info{
+		Name:        "foo",
+		Arch:        "amd64",
+		Version:     "1.0.0",
+		Description: "foo does things\nreally well",
+		Maintainer:  "Foo <foo@bar.com>",
+		Homepage:    "https://foo.bar",
+		License:     "MIT",
+		Overridables: nfpm.Overridables{
+			Depends:    []string{"glibc"},
+			Recommends: []string{"git"},
+			Conflicts:  []string{"foo-git"},
+			Provides:   []string{"foo"},
+			Replaces:   []string{"bar"},
+			Contents: files.Contents{
+				{
+					Source:      binary,
+					Destination: "/usr/bin/foo",
+					FileInfo:    &files.ContentFileInfo{MTime: mtime},
+				},
+				{
+					Source:      conf,
+					Destination: "/etc/foo/foo.conf",
+					Type:        "config",
+					FileInfo:    &files.ContentFileInfo{MTime: mtime},
+				},
+				{
+					Source:      "/usr/bin/foo",
+					Destination: "/usr/bin/bar",
+					Type:        "symlink",
+					FileInfo:    &files.ContentFileInfo{MTime: mtime},
+				},
+				{
+					Destination: "/var/lib/foo",
+					Type:        "dir",
+					FileInfo:    &files.ContentFileInfo{MTime: mtime},
+				},
+				{
+					Source:      conf,
+					Destination: "/etc/foo/deb.conf",
+					Packager:    "deb",
+				},
+				{
+					Destination: "/var/log/foo.log",
+					Type:        "ghost",
+				},
+			},
+			Scripts: nfpm.Scripts{
+				PostInstall: script,
+			},
+		},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, Default.Package(info, &buf))
+
+	entries := readPackage(t, buf.Bytes())
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.header.Name)
+	}
+	require.Equal(t, []string{
+		".PKGINFO",
+		".MTREE",
+		".INSTALL",
+		"etc/",
+		"etc/foo/",
+		"etc/foo/foo.conf",
+		"usr/",
+		"usr/bin/",
+		"usr/bin/bar",
+		"usr/bin/foo",
+		"var/",
+		"var/lib/",
+		"var/lib/foo/",
+	}, names)
+
+	require.Equal(t, `# Generated by GoReleaser
+pkgname = foo
+pkgbase = foo
+pkgver = 1.0.0-1
+pkgdesc = foo does things really well
+url = https://foo.bar
+builddate = 1638352800
+packager = Foo <foo@bar.com>
+size = 21
+arch = x86_64
+license = MIT
+replaces = bar
+conflict = foo-git
+provides = foo
+backup = etc/foo/foo.conf
+depend = glibc
+optdepend = git
+`, string(entries[0].content))
+
+	gr, err := gzip.NewReader(bytes.NewReader(entries[1].content))
+	require.NoError(t, err)
+	mtree, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	require.Contains(t, string(mtree), "#mtree\n/set type=file uid=0 gid=0 mode=644\n")
+	require.Contains(t, string(mtree), "./.PKGINFO time=1638352800.0 size=")
+	require.Contains(t, string(mtree), "./usr/bin/foo time=1638352800.0 mode=755 size=18 md5digest=")
+	require.Contains(t, string(mtree), "./usr/bin/bar time=1638352800.0 mode=777 type=link link=/usr/bin/foo\n")
+	require.Contains(t, string(mtree), "./var/lib/foo time=1638352800.0 mode=755 type=dir\n")
+	require.NotContains(t, string(mtree), "deb.conf")
+
+	require.Equal(t, "post_install() {\necho installed\n}\n\n", string(entries[2].content))
+	require.Equal(t, "/usr/bin/foo", entries[8].header.Linkname)
+	require.Equal(t, "#!/bin/sh\necho foo", string(entries[9].content))
+	require.Equal(t, int64(0o755), entries[9].header.Mode)
+}
+
+func TestPackageReproducible(t *testing.T) {
+	folder := t.TempDir()
+	binary := filepath.Join(folder, "foo")
+	require.NoError(t, os.WriteFile(binary, []byte("foo"), 0o755))
+	info := nfpm.WithDefaults(&nfpm.Info{
+		Name:    "foo",
+		Arch:    "amd64",
+		Version: "1.0.0",
+		Overridables: nfpm.Overridables{
+			Contents: files.Contents{{
+				Source:      binary,
+				Destination: "/usr/bin/foo",
+				FileInfo:    &files.ContentFileInfo{MTime: time.Unix(1600000000, 0)},
+			}},
+		},
+	})
+	var first, second bytes.Buffer
+	require.NoError(t, Default.Package(info, &first))
+	require.NoError(t, Default.Package(info, &second))
+	require.Equal(t, first.Bytes(), second.Bytes())
+}
+
+func TestPackageErrors(t *testing.T) {
+	t.Run("invalid info", func(t *testing.T) {
+		require.EqualError(t, Default.Package(&nfpm.Info{}, io.Discard), "package name must be provided")
+	})
+	t.Run("missing script", func(t *testing.T) {
+		info := nfpm.WithDefaults(&nfpm.Info{
+			Name:    "foo",
+			Arch:    "amd64",
+			Version: "1.0.0",
+			Overridables: nfpm.Overridables{
+				Scripts: nfpm.Scripts{PreRemove: "/nope/preremove.sh"},
+			},
+		})
+		require.ErrorIs(t, Default.Package(info, io.Discard), os.ErrNotExist)
+	})
+}
+
+type tarEntry struct {
+	header  *tar.Header
+	content []byte
+}
+
+func readPackage(tb testing.TB, bts []byte) []tarEntry {
+	tb.Helper()
+	zr, err := zstd.NewReader(bytes.NewReader(bts))
+	require.NoError(tb, err)
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	var result []tarEntry
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(tb, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(tb, err)
+		result = append(result, tarEntry{header: header, content: content})
+	}
+	return result
+}
@@ -14,6 +14,7 @@ import (
 	"hash/crc32"
 	"io"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/apex/log"
@@ -63,6 +64,25 @@ const (
 	ScoopManifest
 	// SBOM is a Software Bill of Materials file.
 	SBOM
+	// DebugSymbols is a file with the debug symbols split out of a binary.
+	DebugSymbols
+	// UploadableDebugSymbols is a debug symbols file that should be uploaded
+	// along with the other release artifacts.
+	UploadableDebugSymbols
+	// LinuxRepository is a directory with an apt or yum repository.
+	LinuxRepository
+	// BrewCask is an uploadable homebrew cask file.
+	BrewCask
+	// WingetManifest is an uploadable winget manifest file.
+	WingetManifest
+	// PublishableChocolatey is a chocolatey package yet to be published.
+	PublishableChocolatey
+	// PkgBuild is an Arch Linux AUR PKGBUILD file.
+	PkgBuild
+	// SrcInfo is an Arch Linux AUR .SRCINFO file.
+	SrcInfo
+	// Nixpkg is a Nix derivation.
+	Nixpkg
 )
 
 func (t Type) String() string {
@@ -99,6 +119,24 @@ func (t Type) String() string {
 		return "Scoop Manifest"
 	case SBOM:
 		return "SBOM"
+	case DebugSymbols:
+		return "Debug Symbols"
+	case UploadableDebugSymbols:
+		return "Uploadable Debug Symbols"
+	case LinuxRepository:
+		return "Linux Repository"
+	case BrewCask:
+		return "Brew Cask"
+	case WingetManifest:
+		return "Winget Manifest"
+	case PublishableChocolatey:
+		return "Chocolatey"
+	case PkgBuild:
+		return "PKGBUILD"
+	case SrcInfo:
+		return "SRCINFO"
+	case Nixpkg:
+		return "Nixpkg"
 	default:
 		return "unknown"
 	}
@@ -118,6 +156,7 @@ const (
 	ExtraBinaries  = "Binaries"
 	ExtraRefresh   = "Refresh"
 	ExtraReplaces  = "Replaces"
+	ExtraSize      = "Size"
 )
 
 // Extras represents the extra fields in an artifact.
@@ -225,6 +264,32 @@ func (a Artifact) Format() string {
 	return a.ExtraOr(ExtraFormat, "").(string)
 }
 
+// Files returns an artifact for each file inside a directory artifact, such
+// as a LinuxRepository, named after the directory artifact name joined with
+// their relative path.
+// Other artifacts are returned as is.
+func (a *Artifact) Files() ([]*Artifact, error) {
+	if a.Type != LinuxRepository {
+		return []*Artifact{a}, nil
+	}
+	var result []*Artifact
+	err := filepath.Walk(a.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(a.Path, path)
+		if err != nil {
+			return err
+		}
+		file := *a
+		file.Name = filepath.ToSlash(filepath.Join(a.Name, rel))
+		file.Path = path
+		result = append(result, &file)
+		return nil
+	})
+	return result, err
+}
+
 // Artifacts is a list of artifacts.
 type Artifacts struct {
 	items []*Artifact
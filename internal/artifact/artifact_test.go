@@ -386,6 +386,15 @@ func TestTypeToString(t *testing.T) {
 		KrewPluginManifest,
 		ScoopManifest,
 		SBOM,
+		DebugSymbols,
+		UploadableDebugSymbols,
+		LinuxRepository,
+		BrewCask,
+		WingetManifest,
+		PublishableChocolatey,
+		PkgBuild,
+		SrcInfo,
+		Nixpkg,
 	} {
 		t.Run(a.String(), func(t *testing.T) {
 			require.NotEqual(t, "unknown", a.String())
@@ -539,3 +548,37 @@ func TestMarshalJSON(t *testing.T) {
 	require.NoError(t, err)
 	golden.RequireEqualJSON(t, bts)
 }
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "repo", "dists", "stable"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repo", "dists", "stable", "Release"), []byte("release"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repo", "index.html"), []byte("index"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.deb"), []byte("deb"), 0o644))
+
+	t.Run("directory", func(t *testing.T) {
+		files, err := (&Artifact{
+			Name: "myrepo",
+			Path: filepath.Join(dir, "repo"),
+			Type: LinuxRepository,
+		}).Files()
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		require.Equal(t, "myrepo/dists/stable/Release", files[0].Name)
+		require.Equal(t, filepath.Join(dir, "repo", "dists", "stable", "Release"), files[0].Path)
+		require.Equal(t, LinuxRepository, files[0].Type)
+		require.Equal(t, "myrepo/index.html", files[1].Name)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		a := &Artifact{Name: "foo.deb", Path: filepath.Join(dir, "foo.deb")}
+		files, err := a.Files()
+		require.NoError(t, err)
+		require.Equal(t, []*Artifact{a}, files)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := (&Artifact{Path: filepath.Join(dir, "nope"), Type: LinuxRepository}).Files()
+		require.Error(t, err)
+	})
+}
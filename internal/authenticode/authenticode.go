@@ -0,0 +1,504 @@
+// Package authenticode signs windows PE files (executables and libraries)
+// with Authenticode signatures, optionally timestamped by a RFC3161
+// timestamp authority.
+//
+// See https://download.microsoft.com/download/9/c/5/9c5b2167-8017-4bae-9fde-d599bac8184a/Authenticode_PE.docx
+package authenticode
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+)
+
+// nolint: gochecknoglobals
+var (
+	oidSHA256                 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSA                    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256        = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidSignedData             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttributeContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttributeMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSpcIndirectData        = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 4}
+	oidSpcSpOpusInfo          = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 12}
+	oidSpcStatementType       = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 11}
+	oidSpcIndividualCodeSign  = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 21}
+	oidSpcPeImageData         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 15}
+	oidRFC3161Timestamp       = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 3, 3, 1}
+	oidTSTInfo                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+// spcPeImageData is a DER-encoded SpcPeImageData with no flags and an empty
+// SpcLink, which is what signtool and osslsigncode emit.
+// nolint: gochecknoglobals
+var spcPeImageData = []byte{0x30, 0x09, 0x03, 0x01, 0x00, 0xa0, 0x04, 0xa2, 0x02, 0x80, 0x00}
+
+// Signer signs PE files.
+type Signer struct {
+	// Certificates is the certificate chain, leaf first.
+	Certificates []*x509.Certificate
+	// Key is the private key matching the leaf certificate.
+	Key crypto.Signer
+	// TimestampURL is the URL of a RFC3161 timestamp authority, optional.
+	TimestampURL string
+	// Description and URL are shown in the windows UAC prompt, optional.
+	Description string
+	URL         string
+	// Client is used to talk to the timestamp authority, defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// SignFile signs the PE file at the given path in place, keeping its
+// modification time.
+func (s Signer) SignFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	signed, err := s.Sign(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := os.WriteFile(path, signed, info.Mode()); err != nil {
+		return err
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// Sign returns a copy of the given PE file with an Authenticode signature.
+// Existing signatures are replaced.
+func (s Signer) Sign(data []byte) ([]byte, error) {
+	if len(s.Certificates) == 0 || s.Key == nil {
+		return nil, errors.New("a certificate and a key are required")
+	}
+	img, err := parsePE(data)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := img.digest()
+	content, err := spcIndirectDataContent(digest)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.signedData(content)
+	if err != nil {
+		return nil, err
+	}
+	return img.withCertificate(sig), nil
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// contentInfo is a PKCS#7 ContentInfo. Content holds the [0] EXPLICIT
+// wrapper, see explicitContent.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"`
+}
+
+// explicitContent wraps the given DER value in a [0] EXPLICIT tag.
+// encoding/asn1 does not add the tag itself when marshaling a RawValue with
+// FullBytes set.
+func explicitContent(der []byte) asn1.RawValue {
+	return asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      der,
+	}
+}
+
+type signerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     issuerAndSerial
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      contentInfo
+	Certificates     asn1.RawValue `asn1:"optional"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type digestInfo struct {
+	DigestAlgorithm pkix.AlgorithmIdentifier
+	Digest          []byte
+}
+
+type spcAttributeTypeAndOptionalValue struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+type spcIndirectData struct {
+	Data          spcAttributeTypeAndOptionalValue
+	MessageDigest digestInfo
+}
+
+func sha256Algorithm() pkix.AlgorithmIdentifier {
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidSHA256,
+		Parameters: asn1.NullRawValue,
+	}
+}
+
+func spcIndirectDataContent(digest []byte) ([]byte, error) {
+	return asn1.Marshal(spcIndirectData{
+		Data: spcAttributeTypeAndOptionalValue{
+			Type:  oidSpcPeImageData,
+			Value: asn1.RawValue{FullBytes: spcPeImageData},
+		},
+		MessageDigest: digestInfo{
+			DigestAlgorithm: sha256Algorithm(),
+			Digest:          digest,
+		},
+	})
+}
+
+// signedData creates the PKCS#7 SignedData wrapping the given
+// SpcIndirectDataContent.
+func (s Signer) signedData(content []byte) ([]byte, error) {
+	// the message digest is calculated over the content, without its
+	// outer tag and length.
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	contentDigest := sha256.Sum256(raw.Bytes)
+
+	opus, err := s.opusInfo()
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := attributes(
+		attributeOf(oidAttributeContentType, mustMarshal(oidSpcIndirectData)),
+		attributeOf(oidAttributeMessageDigest, mustMarshal(contentDigest[:])),
+		attributeOf(oidSpcStatementType, mustMarshal([]asn1.ObjectIdentifier{oidSpcIndividualCodeSign})),
+		attributeOf(oidSpcSpOpusInfo, opus),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the signature is over the DER encoding of the attributes as a SET.
+	attrsDigest := sha256.Sum256(mustMarshal(asn1.RawValue{
+		Tag:        asn1.TagSet,
+		IsCompound: true,
+		Bytes:      attrs,
+	}))
+	signature, err := s.Key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	sigAlg, err := signatureAlgorithm(s.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := s.Certificates[0]
+	info := signerInfo{
+		Version: 1,
+		IssuerAndSerialNumber: issuerAndSerial{
+			Issuer: asn1.RawValue{FullBytes: leaf.RawIssuer},
+			Serial: leaf.SerialNumber,
+		},
+		DigestAlgorithm: sha256Algorithm(),
+		AuthenticatedAttributes: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      attrs,
+		},
+		DigestEncryptionAlgorithm: sigAlg,
+		EncryptedDigest:           signature,
+	}
+
+	if s.TimestampURL != "" {
+		token, err := s.timestamp(signature)
+		if err != nil {
+			return nil, err
+		}
+		unauth, err := attributes(attributeOf(oidRFC3161Timestamp, token))
+		if err != nil {
+			return nil, err
+		}
+		info.UnauthenticatedAttributes = asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        1,
+			IsCompound: true,
+			Bytes:      unauth,
+		}
+	}
+
+	var certs []byte
+	for _, cert := range s.Certificates {
+		certs = append(certs, cert.Raw...)
+	}
+
+	sd, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Algorithm()},
+		ContentInfo: contentInfo{
+			ContentType: oidSpcIndirectData,
+			Content:     explicitContent(content),
+		},
+		Certificates: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      certs,
+		},
+		SignerInfos: []signerInfo{info},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     explicitContent(sd),
+	})
+}
+
+// opusInfo creates a SpcSpOpusInfo with the program name and URL.
+func (s Signer) opusInfo() ([]byte, error) {
+	var fields []byte
+	if s.Description != "" {
+		// programName [0] EXPLICIT SpcString, unicode [0] IMPLICIT BMPString
+		name := mustMarshal(asn1.RawValue{
+			Class: asn1.ClassContextSpecific,
+			Tag:   0,
+			Bytes: bmpString(s.Description),
+		})
+		fields = append(fields, mustMarshal(asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      name,
+		})...)
+	}
+	if s.URL != "" {
+		// moreInfo [1] EXPLICIT SpcLink, url [0] IMPLICIT IA5String
+		url := mustMarshal(asn1.RawValue{
+			Class: asn1.ClassContextSpecific,
+			Tag:   0,
+			Bytes: []byte(s.URL),
+		})
+		fields = append(fields, mustMarshal(asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        1,
+			IsCompound: true,
+			Bytes:      url,
+		})...)
+	}
+	return asn1.Marshal(asn1.RawValue{
+		Tag:        asn1.TagSequence,
+		IsCompound: true,
+		Bytes:      fields,
+	})
+}
+
+func signatureAlgorithm(key crypto.Signer) (pkix.AlgorithmIdentifier, error) {
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidRSA, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported key type: %T", key.Public())
+	}
+}
+
+func attributeOf(oid asn1.ObjectIdentifier, value []byte) attribute {
+	return attribute{
+		Type: oid,
+		Values: asn1.RawValue{
+			Tag:        asn1.TagSet,
+			IsCompound: true,
+			Bytes:      value,
+		},
+	}
+}
+
+// attributes encodes the given attributes in DER SET OF order.
+func attributes(attrs ...attribute) ([]byte, error) {
+	encoded := make([][]byte, 0, len(attrs))
+	for _, attr := range attrs {
+		b, err := asn1.Marshal(attr)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, b)
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+	return bytes.Join(encoded, nil), nil
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func bmpString(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		if r > 0xffff {
+			r = '?'
+		}
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return b
+}
+
+// image holds the offsets of a PE file needed for signing.
+type image struct {
+	data         []byte
+	checksumOff  int
+	certDirOff   int
+	certTableOff int // where the certificate table starts, or len(data)
+}
+
+func parsePE(data []byte) (*image, error) {
+	if len(data) < 0x40 || data[0] != 'M' || data[1] != 'Z' {
+		return nil, errors.New("not a PE file")
+	}
+	le := binary.LittleEndian
+	peOff := int(le.Uint32(data[0x3c:]))
+	if peOff+24 > len(data) || !bytes.Equal(data[peOff:peOff+4], []byte("PE\x00\x00")) {
+		return nil, errors.New("not a PE file")
+	}
+	optOff := peOff + 24
+	if optOff+2 > len(data) {
+		return nil, errors.New("invalid optional header")
+	}
+
+	var numDirsOff, dirsOff int
+	switch le.Uint16(data[optOff:]) {
+	case 0x10b: // PE32
+		numDirsOff, dirsOff = optOff+92, optOff+96
+	case 0x20b: // PE32+
+		numDirsOff, dirsOff = optOff+108, optOff+112
+	default:
+		return nil, errors.New("invalid optional header magic")
+	}
+	if dirsOff+5*8 > len(data) || le.Uint32(data[numDirsOff:]) < 5 {
+		return nil, errors.New("missing certificate table directory")
+	}
+
+	img := &image{
+		checksumOff:  optOff + 64,
+		certDirOff:   dirsOff + 4*8,
+		certTableOff: len(data),
+	}
+
+	certOff := int(le.Uint32(data[img.certDirOff:]))
+	certSize := int(le.Uint32(data[img.certDirOff+4:]))
+	if certSize > 0 {
+		if certOff+certSize != len(data) {
+			return nil, errors.New("certificate table is not at the end of the file")
+		}
+		img.certTableOff = certOff
+	}
+
+	// drop any existing signature and pad to 8 bytes, as the certificate
+	// table must be aligned.
+	img.data = append([]byte{}, data[:img.certTableOff]...)
+	le.PutUint64(img.data[img.certDirOff:], 0)
+	for len(img.data)%8 != 0 {
+		img.data = append(img.data, 0)
+	}
+	img.certTableOff = len(img.data)
+	return img, nil
+}
+
+// digest calculates the Authenticode SHA256 digest of the file: everything
+// but the checksum, the certificate table directory entry and the
+// certificate table itself.
+func (p *image) digest() []byte {
+	h := sha256.New()
+	h.Write(p.data[:p.checksumOff])
+	h.Write(p.data[p.checksumOff+4 : p.certDirOff])
+	h.Write(p.data[p.certDirOff+8 : p.certTableOff])
+	return h.Sum(nil)
+}
+
+// withCertificate appends the given PKCS#7 signature as a WIN_CERTIFICATE
+// and updates the certificate table directory entry and checksum.
+func (p *image) withCertificate(sig []byte) []byte {
+	le := binary.LittleEndian
+	length := 8 + len(sig)
+	padded := (length + 7) / 8 * 8
+
+	out := make([]byte, len(p.data), len(p.data)+padded)
+	copy(out, p.data)
+	hdr := make([]byte, 8)
+	le.PutUint32(hdr[0:], uint32(length))
+	le.PutUint16(hdr[4:], 0x0200) // WIN_CERT_REVISION_2_0
+	le.PutUint16(hdr[6:], 0x0002) // WIN_CERT_TYPE_PKCS_SIGNED_DATA
+	out = append(out, hdr...)
+	out = append(out, sig...)
+	out = append(out, make([]byte, padded-length)...)
+
+	le.PutUint32(out[p.certDirOff:], uint32(p.certTableOff))
+	le.PutUint32(out[p.certDirOff+4:], uint32(padded))
+	le.PutUint32(out[p.checksumOff:], checksum(out, p.checksumOff))
+	return out
+}
+
+// checksum calculates the PE image checksum.
+func checksum(data []byte, checksumOff int) uint32 {
+	var sum uint64
+	for i := 0; i < len(data); i += 2 {
+		if i == checksumOff || i == checksumOff+2 {
+			continue
+		}
+		var word uint64
+		if i+1 < len(data) {
+			word = uint64(binary.LittleEndian.Uint16(data[i:]))
+		} else {
+			word = uint64(data[i])
+		}
+		sum += word
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	sum = (sum & 0xffff) + (sum >> 16)
+	return uint32(sum) + uint32(len(data))
+}
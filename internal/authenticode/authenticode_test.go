@@ -0,0 +1,362 @@
+package authenticode
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"debug/pe"
+	"encoding/asn1"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func buildExe(tb testing.TB, goarch string) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "app.exe")
+	testlib.BuildBinary(tb, "windows", goarch, path, nil)
+	return path
+}
+
+func loadSigner(tb testing.TB, cert, key string) Signer {
+	tb.Helper()
+	certData, err := os.ReadFile(cert)
+	require.NoError(tb, err)
+	keyData, err := os.ReadFile(key)
+	require.NoError(tb, err)
+	chain, signer, err := LoadPEM(certData, keyData)
+	require.NoError(tb, err)
+	return Signer{Certificates: chain, Key: signer}
+}
+
+// verify checks the signature embedded in the given PE file and returns
+// its signer info.
+func verify(tb testing.TB, data []byte) signerInfo {
+	tb.Helper()
+
+	f, err := pe.NewFile(bytesReaderAt(data))
+	require.NoError(tb, err)
+	var dir pe.DataDirectory
+	switch oh := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dir = oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_SECURITY]
+	case *pe.OptionalHeader64:
+		dir = oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_SECURITY]
+	}
+	require.NotZero(tb, dir.Size)
+	require.Equal(tb, len(data), int(dir.VirtualAddress+dir.Size))
+	require.Zero(tb, dir.VirtualAddress%8)
+
+	cert := data[dir.VirtualAddress:]
+	length := binary.LittleEndian.Uint32(cert)
+	require.Equal(tb, uint16(0x0200), binary.LittleEndian.Uint16(cert[4:]))
+	require.Equal(tb, uint16(0x0002), binary.LittleEndian.Uint16(cert[6:]))
+
+	var ci contentInfo
+	_, err = asn1.Unmarshal(cert[8:length], &ci)
+	require.NoError(tb, err)
+	require.True(tb, ci.ContentType.Equal(oidSignedData))
+
+	var sd signedData
+	_, err = asn1.Unmarshal(ci.Content.Bytes, &sd)
+	require.NoError(tb, err)
+	require.True(tb, sd.ContentInfo.ContentType.Equal(oidSpcIndirectData))
+	require.Len(tb, sd.SignerInfos, 1)
+
+	// the indirect data must have the digest of the file
+	var indirect spcIndirectData
+	_, err = asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &indirect)
+	require.NoError(tb, err)
+	img, err := parsePE(data)
+	require.NoError(tb, err)
+	require.Equal(tb, img.digest(), indirect.MessageDigest.Digest)
+
+	// the message digest attribute must match the indirect data
+	info := sd.SignerInfos[0]
+	var attrs []attribute
+	_, err = asn1.UnmarshalWithParams(info.AuthenticatedAttributes.FullBytes, &attrs, "tag:0,set")
+	require.NoError(tb, err)
+	var content asn1.RawValue
+	_, err = asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &content)
+	require.NoError(tb, err)
+	contentDigest := sha256.Sum256(content.Bytes)
+	var found bool
+	for _, attr := range attrs {
+		if !attr.Type.Equal(oidAttributeMessageDigest) {
+			continue
+		}
+		var digest []byte
+		_, err := asn1.Unmarshal(attr.Values.Bytes, &digest)
+		require.NoError(tb, err)
+		require.Equal(tb, contentDigest[:], digest)
+		found = true
+	}
+	require.True(tb, found, "message digest attribute not found")
+
+	// the signature must be over the attributes
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	require.NoError(tb, err)
+	signed := mustMarshal(asn1.RawValue{
+		Tag:        asn1.TagSet,
+		IsCompound: true,
+		Bytes:      info.AuthenticatedAttributes.Bytes,
+	})
+	attrsDigest := sha256.Sum256(signed)
+	switch pub := certs[0].PublicKey.(type) {
+	case *rsa.PublicKey:
+		require.NoError(tb, rsa.VerifyPKCS1v15(pub, crypto.SHA256, attrsDigest[:], info.EncryptedDigest))
+	case *ecdsa.PublicKey:
+		require.True(tb, ecdsa.VerifyASN1(pub, attrsDigest[:], info.EncryptedDigest))
+	default:
+		tb.Fatalf("unexpected key type %T", pub)
+	}
+
+	// the checksum must be valid
+	require.Equal(tb, checksum(data, img.checksumOff), binary.LittleEndian.Uint32(data[img.checksumOff:]))
+	return info
+}
+
+type bytesReaderAt []byte
+
+func (b bytesReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestSign(t *testing.T) {
+	for _, goarch := range []string{"386", "amd64", "arm64"} {
+		goarch := goarch
+		t.Run(goarch, func(t *testing.T) {
+			path := buildExe(t, goarch)
+			signer := loadSigner(t, "testdata/cert.pem", "testdata/key.pem")
+			signer.Description = "My App"
+			signer.URL = "https://example.com"
+			require.NoError(t, signer.SignFile(path))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			verify(t, data)
+
+			// signing again replaces the existing signature
+			require.NoError(t, signer.SignFile(path))
+			resigned, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Len(t, resigned, len(data))
+			verify(t, resigned)
+		})
+	}
+}
+
+func TestSignFileKeepsModTime(t *testing.T) {
+	path := buildExe(t, "amd64")
+	modTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	signer := loadSigner(t, "testdata/cert.pem", "testdata/key.pem")
+	require.NoError(t, signer.SignFile(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.True(t, modTime.Equal(info.ModTime()), info.ModTime())
+}
+
+func TestSignECDSA(t *testing.T) {
+	path := buildExe(t, "amd64")
+	signer := loadSigner(t, "testdata/ec_cert.pem", "testdata/ec_key.pem")
+	require.NoError(t, signer.SignFile(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	verify(t, data)
+}
+
+func TestSignErrors(t *testing.T) {
+	t.Run("no certificate", func(t *testing.T) {
+		_, err := Signer{}.Sign([]byte("MZ"))
+		require.EqualError(t, err, "a certificate and a key are required")
+	})
+	t.Run("not a pe", func(t *testing.T) {
+		signer := loadSigner(t, "testdata/cert.pem", "testdata/key.pem")
+		_, err := signer.Sign([]byte("#!/bin/sh\necho hello world, this is not a windows binary\n"))
+		require.EqualError(t, err, "not a PE file")
+	})
+	t.Run("missing file", func(t *testing.T) {
+		signer := loadSigner(t, "testdata/cert.pem", "testdata/key.pem")
+		require.Error(t, signer.SignFile(filepath.Join(t.TempDir(), "nope.exe")))
+	})
+}
+
+func TestSignTimestamp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/timestamp-query", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req timeStampReq
+		_, err = asn1.Unmarshal(body, &req)
+		require.NoError(t, err)
+		require.True(t, req.CertReq)
+		_, _ = w.Write(timestampResponse(t, 0, req.MessageImprint.HashedMessage))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := buildExe(t, "amd64")
+	signer := loadSigner(t, "testdata/cert.pem", "testdata/key.pem")
+	signer.TimestampURL = srv.URL
+	require.NoError(t, signer.SignFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	info := verify(t, data)
+
+	var attrs []attribute
+	_, err = asn1.UnmarshalWithParams(info.UnauthenticatedAttributes.FullBytes, &attrs, "tag:1,set")
+	require.NoError(t, err)
+	require.Len(t, attrs, 1)
+	require.True(t, attrs[0].Type.Equal(oidRFC3161Timestamp))
+	digest := sha256.Sum256(info.EncryptedDigest)
+	require.NoError(t, checkToken(attrs[0].Values.Bytes, digest[:]))
+}
+
+func TestSignTimestampErrors(t *testing.T) {
+	for name, handler := range map[string]http.HandlerFunc{
+		"failed to timestamp: 500 Internal Server Error: boom": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		},
+		"failed to timestamp: request rejected with status 2": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(timestampResponse(t, 2, nil))
+		},
+		"failed to timestamp: invalid token: timestamp is for a different signature": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(timestampResponse(t, 0, []byte("something else")))
+		},
+	} {
+		handler := handler
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(handler)
+			t.Cleanup(srv.Close)
+
+			path := buildExe(t, "amd64")
+			signer := loadSigner(t, "testdata/cert.pem", "testdata/key.pem")
+			signer.TimestampURL = srv.URL
+			err := signer.SignFile(path)
+			require.EqualError(t, err, path+": "+name)
+		})
+	}
+}
+
+// timestampResponse creates a minimal TimeStampResp. The token is not
+// signed, which is fine as the signer only checks the message imprint.
+func timestampResponse(tb testing.TB, status int, digest []byte) []byte {
+	tb.Helper()
+	resp := struct {
+		Status         pkiStatusInfo
+		TimeStampToken asn1.RawValue `asn1:"optional"`
+	}{
+		Status: pkiStatusInfo{Status: status},
+	}
+	if digest != nil {
+		info, err := asn1.Marshal(tstInfo{
+			Version: 1,
+			Policy:  asn1.ObjectIdentifier{1, 2, 3},
+			MessageImprint: messageImprint{
+				HashAlgorithm: sha256Algorithm(),
+				HashedMessage: digest,
+			},
+			SerialNumber: big.NewInt(1),
+		})
+		require.NoError(tb, err)
+		sd, err := asn1.Marshal(struct {
+			Version          int
+			DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+			ContentInfo      encapsulatedContentInfo
+		}{
+			Version:          3,
+			DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Algorithm()},
+			ContentInfo: encapsulatedContentInfo{
+				ContentType: oidTSTInfo,
+				Content:     info,
+			},
+		})
+		require.NoError(tb, err)
+		token, err := asn1.Marshal(contentInfo{
+			ContentType: oidSignedData,
+			Content:     explicitContent(sd),
+		})
+		require.NoError(tb, err)
+		resp.TimeStampToken = asn1.RawValue{FullBytes: token}
+	}
+	b, err := asn1.Marshal(resp)
+	require.NoError(tb, err)
+	return b
+}
+
+func TestLoadPKCS12(t *testing.T) {
+	data, err := os.ReadFile("testdata/cert.p12")
+	require.NoError(t, err)
+
+	chain, key, err := LoadPKCS12(data, "secret")
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+	require.Equal(t, "GoReleaser Test", chain[0].Subject.CommonName)
+	require.IsType(t, &rsa.PrivateKey{}, key)
+
+	_, _, err = LoadPKCS12(data, "wrong")
+	require.Error(t, err)
+}
+
+func TestLoadPEM(t *testing.T) {
+	cert, err := os.ReadFile("testdata/cert.pem")
+	require.NoError(t, err)
+	key, err := os.ReadFile("testdata/key.pem")
+	require.NoError(t, err)
+	ecCert, err := os.ReadFile("testdata/ec_cert.pem")
+	require.NoError(t, err)
+	ecKey, err := os.ReadFile("testdata/ec_key.pem")
+	require.NoError(t, err)
+
+	t.Run("leaf is moved first", func(t *testing.T) {
+		chain, signer, err := LoadPEM(append(append([]byte{}, cert...), ecCert...), ecKey)
+		require.NoError(t, err)
+		require.Len(t, chain, 2)
+		require.Equal(t, "GoReleaser EC Test", chain[0].Subject.CommonName)
+		require.IsType(t, &ecdsa.PrivateKey{}, signer)
+	})
+
+	t.Run("same file", func(t *testing.T) {
+		both := append(append([]byte{}, key...), cert...)
+		chain, _, err := LoadPEM(both, both)
+		require.NoError(t, err)
+		require.Len(t, chain, 1)
+	})
+
+	t.Run("no certificates", func(t *testing.T) {
+		_, _, err := LoadPEM(key, key)
+		require.EqualError(t, err, "no certificates found")
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		_, _, err := LoadPEM(cert, cert)
+		require.EqualError(t, err, "no private key found")
+	})
+
+	t.Run("key does not match", func(t *testing.T) {
+		_, _, err := LoadPEM(cert, ecKey)
+		require.EqualError(t, err, "no certificate matches the private key")
+	})
+}
@@ -0,0 +1,103 @@
+package authenticode
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// LoadPKCS12 loads the certificate chain and private key from a PKCS#12
+// (.p12/.pfx) file.
+func LoadPKCS12(data []byte, password string) ([]*x509.Certificate, crypto.Signer, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode pkcs12: %w", err)
+	}
+	var pemData []byte
+	for _, block := range blocks {
+		pemData = append(pemData, pem.EncodeToMemory(block)...)
+	}
+	return LoadPEM(pemData, pemData)
+}
+
+// LoadPEM loads the certificate chain and private key from PEM encoded
+// data. Both can be in the same file.
+//
+// The certificate matching the private key is put first in the chain.
+func LoadPEM(certs, key []byte) ([]*x509.Certificate, crypto.Signer, error) {
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(certs); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, nil, errors.New("no certificates found")
+	}
+
+	var signer crypto.Signer
+	for block, rest := pem.Decode(key); block != nil; block, rest = pem.Decode(rest) {
+		k, err := parseKey(block)
+		if err != nil {
+			return nil, nil, err
+		}
+		if k != nil {
+			signer = k
+			break
+		}
+	}
+	if signer == nil {
+		return nil, nil, errors.New("no private key found")
+	}
+
+	for i, cert := range chain {
+		if publicKeyEqual(cert.PublicKey, signer.Public()) {
+			chain[0], chain[i] = chain[i], chain[0]
+			return chain, signer, nil
+		}
+	}
+	return nil, nil, errors.New("no certificate matches the private key")
+}
+
+func parseKey(block *pem.Block) (crypto.Signer, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			// pkcs12.ToPEM encodes RSA keys as PKCS#1 in a "PRIVATE KEY" block
+			if rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes); rsaErr == nil {
+				key, err = rsaKey, nil
+			}
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", key)
+	}
+	return signer, nil
+}
+
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
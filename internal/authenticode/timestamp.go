@@ -0,0 +1,138 @@
+package authenticode
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString asn1.RawValue  `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type tokenSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      encapsulatedContentInfo
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+}
+
+// timestamp requests a RFC3161 timestamp token for the given signature from
+// the timestamp authority.
+func (s Signer) timestamp(signature []byte) ([]byte, error) {
+	digest := sha256.Sum256(signature)
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: sha256Algorithm(),
+			HashedMessage: digest[:],
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(s.TimestampURL, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to timestamp: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to timestamp: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to timestamp: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var tsResp timeStampResp
+	if _, err := asn1.Unmarshal(body, &tsResp); err != nil {
+		return nil, fmt.Errorf("failed to timestamp: invalid response: %w", err)
+	}
+	// 0 is granted, 1 is granted with modifications
+	if tsResp.Status.Status > 1 {
+		return nil, fmt.Errorf("failed to timestamp: request rejected with status %d", tsResp.Status.Status)
+	}
+	token := tsResp.TimeStampToken.FullBytes
+	if len(token) == 0 {
+		return nil, errors.New("failed to timestamp: response has no token")
+	}
+	if err := checkToken(token, digest[:]); err != nil {
+		return nil, fmt.Errorf("failed to timestamp: %w", err)
+	}
+	return token, nil
+}
+
+// checkToken checks that the given timestamp token is for the given digest.
+func checkToken(token, digest []byte) error {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return errors.New("invalid token: not a signed data")
+	}
+	var sd tokenSignedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	if !sd.ContentInfo.ContentType.Equal(oidTSTInfo) {
+		return errors.New("invalid token: not a timestamp")
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.ContentInfo.Content, &info); err != nil {
+		return fmt.Errorf("invalid token: %w", err)
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, digest) {
+		return errors.New("invalid token: timestamp is for a different signature")
+	}
+	return nil
+}
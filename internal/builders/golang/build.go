@@ -1,6 +1,7 @@
 package golang
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -10,12 +11,15 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/builders/buildtarget"
+	"github.com/goreleaser/goreleaser/internal/debugsym"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/internal/winres"
 	api "github.com/goreleaser/goreleaser/pkg/build"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -47,6 +51,17 @@ func (*Builder) WithDefaults(build config.Build) (config.Build, error) {
 	if len(build.Ldflags) == 0 {
 		build.Ldflags = []string{"-s -w -X main.version={{.Version}} -X main.commit={{.Commit}} -X main.date={{.Date}} -X main.builtBy=goreleaser"}
 	}
+	if build.WindowsResources != (config.WindowsResources{}) {
+		if build.WindowsResources.ProductName == "" {
+			build.WindowsResources.ProductName = "{{ .ProjectName }}"
+		}
+		if build.WindowsResources.ProductVersion == "" {
+			build.WindowsResources.ProductVersion = "{{ .Version }}"
+		}
+		if build.WindowsResources.FileVersion == "" {
+			build.WindowsResources.FileVersion = "{{ .Version }}"
+		}
+	}
 	if len(build.Targets) == 0 {
 		if len(build.Goos) == 0 {
 			build.Goos = []string{"linux", "darwin"}
@@ -121,6 +136,14 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 		return err
 	}
 
+	if options.Goos == "windows" && build.WindowsResources != (config.WindowsResources{}) {
+		cleanup, err := writeWindowsResources(ctx, build, options, artifact, env)
+		if err != nil {
+			return fmt.Errorf("failed to create windows resources for %s: %w", options.Target, err)
+		}
+		defer cleanup()
+	}
+
 	if err := run(ctx, cmd, env, build.Dir); err != nil {
 		return fmt.Errorf("failed to build for %s: %w", options.Target, err)
 	}
@@ -181,6 +204,10 @@ func buildGoBuildLine(ctx *context.Context, build config.Build, options api.Opti
 		if err != nil {
 			return cmd, err
 		}
+		if build.DebugSymbols.Enabled && debugsym.Supported(options.Goos) {
+			// symbols are needed so they can be split later on
+			ldflags = withoutStripFlags(ldflags)
+		}
 		// ldflags need to be single string in order to apply correctly
 		cmd = append(cmd, "-ldflags="+strings.Join(ldflags, " "))
 	}
@@ -189,6 +216,24 @@ func buildGoBuildLine(ctx *context.Context, build config.Build, options api.Opti
 	return cmd, nil
 }
 
+// withoutStripFlags removes the -s and -w flags from the given ldflags.
+func withoutStripFlags(ldflags []string) []string {
+	var result []string
+	for _, flag := range ldflags {
+		var fields []string
+		for _, field := range strings.Fields(flag) {
+			if field == "-s" || field == "-w" {
+				continue
+			}
+			fields = append(fields, field)
+		}
+		if len(fields) > 0 {
+			result = append(result, strings.Join(fields, " "))
+		}
+	}
+	return result
+}
+
 func processFlags(ctx *context.Context, a *artifact.Artifact, env, flags []string, flagPrefix string) ([]string, error) {
 	processed := make([]string, 0, len(flags))
 	for _, rawFlag := range flags {
@@ -218,6 +263,102 @@ func run(ctx *context.Context, command, env []string, dir string) error {
 	return nil
 }
 
+// sysoLocks holds a mutex for each main package directory, as the go linker
+// picks up all the .syso files in it, including those of other targets being
+// built at the same time.
+// nolint: gochecknoglobals
+var sysoLocks sync.Map
+
+// writeWindowsResources writes a .syso file with the windows resources into
+// the main package directory, so the go linker picks it up.
+// No other build can write windows resources into the same directory until
+// the returned cleanup function, which also removes the file, is called.
+func writeWindowsResources(ctx *context.Context, build config.Build, options api.Options, a *artifact.Artifact, env []string) (func(), error) {
+	if !winres.Supported(options.Goarch) {
+		log.WithField("target", options.Target).Warn("windows resources are not supported for this target, skipping")
+		return func() {}, nil
+	}
+	if build.UnproxiedMain != "" {
+		return nil, errors.New("windows_resources can't be used with gomod.proxy")
+	}
+
+	conf := build.WindowsResources
+	tpl := tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{})
+	var res winres.Resources
+	for _, field := range []struct {
+		value string
+		dst   *string
+	}{
+		{conf.ProductName, &res.Version.ProductName},
+		{conf.ProductVersion, &res.Version.ProductVersion},
+		{conf.FileVersion, &res.Version.FileVersion},
+		{conf.FileDescription, &res.Version.FileDescription},
+		{conf.CompanyName, &res.Version.CompanyName},
+		{conf.Copyright, &res.Version.LegalCopyright},
+	} {
+		value, err := tpl.Apply(field.value)
+		if err != nil {
+			return nil, err
+		}
+		*field.dst = value
+	}
+	res.Version.InternalName = strings.TrimSuffix(options.Name, options.Ext)
+	res.Version.OriginalFilename = filepath.Base(options.Path)
+
+	for _, file := range []struct {
+		path string
+		dst  *[]byte
+	}{
+		{conf.Icon, &res.Icon},
+		{conf.Manifest, &res.Manifest},
+	} {
+		if file.path == "" {
+			continue
+		}
+		path, err := tpl.Apply(file.path)
+		if err != nil {
+			return nil, err
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		*file.dst = bts
+	}
+
+	obj, err := winres.COFF(options.Goarch, res)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(build.Dir, build.Main)
+	if stat, err := os.Stat(dir); err == nil && !stat.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	// the go linker only picks up the file when building for the os and arch
+	// it ends with.
+	name := strings.Join([]string{"goreleaser", build.ID, options.Target, "windows", options.Goarch}, "_")
+	path := filepath.Join(dir, name+".syso")
+
+	lock, _ := sysoLocks.LoadOrStore(filepath.Clean(dir), &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	cleanup := func() {
+		_ = os.Remove(path)
+		mu.Unlock()
+	}
+	if _, err := os.Stat(path); err == nil {
+		mu.Unlock()
+		return nil, fmt.Errorf("%s already exists", path)
+	}
+	log.WithField("file", path).Debug("writing windows resources")
+	if err := os.WriteFile(path, obj, 0o644); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return cleanup, nil
+}
+
 func checkMain(build config.Build) error {
 	main := build.Main
 	if build.UnproxiedMain != "" {
@@ -1,6 +1,7 @@
 package golang
 
 import (
+	"debug/pe"
 	"fmt"
 	"os"
 	"os/exec"
@@ -17,6 +18,7 @@ import (
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 )
 
 var runtimeTarget = runtime.GOOS + "_" + runtime.GOARCH
@@ -141,6 +143,7 @@ func TestWithDefaults(t *testing.T) {
 // createFakeGoBinaryWithVersion creates a temporary executable with the
 // given name, which will output a go version string with the given version.
 //  The temporary directory created by this function will be placed in the PATH
+//
 // variable for the duration of (and cleaned up at the end of) the
 // current test run.
 func createFakeGoBinaryWithVersion(tb testing.TB, name, version string) {
@@ -914,6 +917,169 @@ func TestBuildModTimestamp(t *testing.T) {
 	}
 }
 
+func TestBuildWindowsResources(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	writeGoodMain(t, folder)
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "app.manifest"), []byte("<assembly/>"), 0o644))
+
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Builds: []config.Build{
+			{
+				ID:       "foo",
+				Env:      []string{"GO111MODULE=off"},
+				Binary:   "foo",
+				Targets:  []string{"windows_amd64", "windows_arm64"},
+				GoBinary: "go",
+				WindowsResources: config.WindowsResources{
+					Manifest:    "app.manifest",
+					CompanyName: "{{ .Env.COMPANY }}",
+				},
+			},
+		},
+	})
+	ctx.Env["COMPANY"] = "ACME"
+	ctx.Version = "1.2.3"
+	build, err := Default.WithDefaults(ctx.Config.Builds[0])
+	require.NoError(t, err)
+	require.Equal(t, config.WindowsResources{
+		Manifest:       "app.manifest",
+		CompanyName:    "{{ .Env.COMPANY }}",
+		ProductName:    "{{ .ProjectName }}",
+		ProductVersion: "{{ .Version }}",
+		FileVersion:    "{{ .Version }}",
+	}, build.WindowsResources)
+
+	for _, target := range build.Targets {
+		parts := strings.Split(target, "_")
+		err := Default.Build(ctx, build, api.Options{
+			Target: target,
+			Name:   "foo.exe",
+			Path:   filepath.Join(folder, "dist", target, "foo.exe"),
+			Ext:    ".exe",
+			Goos:   parts[0],
+			Goarch: parts[1],
+		})
+		require.NoError(t, err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(folder, "*.syso"))
+	require.NoError(t, err)
+	require.Empty(t, matches, "syso files should have been cleaned up")
+
+	for _, target := range build.Targets {
+		f, err := pe.Open(filepath.Join(folder, "dist", target, "foo.exe"))
+		require.NoError(t, err)
+		rsrc := f.Section(".rsrc")
+		require.NotNil(t, rsrc, target)
+		data, err := rsrc.Data()
+		require.NoError(t, err)
+		require.Contains(t, string(data), "<assembly/>")
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestBuildWindowsResourcesConcurrently(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	writeGoodMain(t, folder)
+	ctx := context.New(config.Project{ProjectName: "foo"})
+
+	var g errgroup.Group
+	for _, id := range []string{"foo", "bar"} {
+		id := id
+		manifest := id + ".manifest"
+		require.NoError(t, os.WriteFile(filepath.Join(folder, manifest), []byte(`<assembly name="`+id+`"/>`), 0o644))
+		build, err := Default.WithDefaults(config.Build{
+			ID:               id,
+			Env:              []string{"GO111MODULE=off"},
+			Binary:           id,
+			Targets:          []string{"windows_amd64"},
+			GoBinary:         "go",
+			WindowsResources: config.WindowsResources{Manifest: manifest},
+		})
+		require.NoError(t, err)
+		g.Go(func() error {
+			return Default.Build(ctx, build, api.Options{
+				Target: "windows_amd64",
+				Name:   id + ".exe",
+				Path:   filepath.Join(folder, "dist", id+".exe"),
+				Ext:    ".exe",
+				Goos:   "windows",
+				Goarch: "amd64",
+			})
+		})
+	}
+	require.NoError(t, g.Wait())
+
+	matches, err := filepath.Glob(filepath.Join(folder, "*.syso"))
+	require.NoError(t, err)
+	require.Empty(t, matches, "syso files should have been cleaned up")
+
+	for id, other := range map[string]string{"foo": "bar", "bar": "foo"} {
+		f, err := pe.Open(filepath.Join(folder, "dist", id+".exe"))
+		require.NoError(t, err)
+		data, err := f.Section(".rsrc").Data()
+		require.NoError(t, err)
+		require.Contains(t, string(data), `<assembly name="`+id+`"/>`)
+		require.NotContains(t, string(data), `<assembly name="`+other+`"/>`)
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestBuildWindowsResourcesErrors(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	writeGoodMain(t, folder)
+
+	for name, tt := range map[string]struct {
+		build  config.Build
+		expect string
+	}{
+		"missing icon": {
+			build: config.Build{
+				WindowsResources: config.WindowsResources{Icon: "nope.ico"},
+			},
+			expect: "failed to create windows resources for windows_amd64: open nope.ico",
+		},
+		"bad template": {
+			build: config.Build{
+				WindowsResources: config.WindowsResources{ProductName: "{{ .Nope }"},
+			},
+			expect: "failed to create windows resources for windows_amd64: template:",
+		},
+		"bad version": {
+			build: config.Build{
+				WindowsResources: config.WindowsResources{FileVersion: "a.b"},
+			},
+			expect: "failed to create windows resources for windows_amd64: invalid file version",
+		},
+		"proxied": {
+			build: config.Build{
+				UnproxiedMain:    ".",
+				WindowsResources: config.WindowsResources{ProductName: "foo"},
+			},
+			expect: "failed to create windows resources for windows_amd64: windows_resources can't be used with gomod.proxy",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			build := tt.build
+			build.ID = "foo"
+			build.Binary = "foo"
+			build.GoBinary = "go"
+			build.Targets = []string{"windows_amd64"}
+			err := Default.Build(context.New(config.Project{}), build, api.Options{
+				Target: "windows_amd64",
+				Name:   "foo.exe",
+				Path:   filepath.Join(folder, "dist", "foo.exe"),
+				Ext:    ".exe",
+				Goos:   "windows",
+				Goarch: "amd64",
+			})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expect)
+		})
+	}
+}
+
 func TestBuildGoBuildLine(t *testing.T) {
 	requireEqualCmd := func(tb testing.TB, build config.Build, expected []string) {
 		tb.Helper()
@@ -968,6 +1134,25 @@ func TestBuildGoBuildLine(t *testing.T) {
 		})
 	})
 
+	t.Run("debug symbols", func(t *testing.T) {
+		build := config.Build{
+			Main:         ".",
+			Ldflags:      []string{"-s -w -X main.version={{.Version}}", "-w"},
+			GoBinary:     "go",
+			DebugSymbols: config.DebugSymbols{Enabled: true},
+		}
+		ctx := context.New(config.Project{Builds: []config.Build{build}})
+		ctx.Version = "1.2.3"
+
+		line, err := buildGoBuildLine(ctx, build, api.Options{Path: "foo", Goos: "linux"}, &artifact.Artifact{}, []string{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"go", "build", "-ldflags=-X main.version=1.2.3", "-o", "foo", "."}, line)
+
+		line, err = buildGoBuildLine(ctx, build, api.Options{Path: "foo", Goos: "windows"}, &artifact.Artifact{}, []string{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"go", "build", "-ldflags=-s -w -X main.version=1.2.3 -w", "-o", "foo", "."}, line)
+	})
+
 	t.Run("ldflags2", func(t *testing.T) {
 		requireEqualCmd(t, config.Build{
 			Main:     ".",
@@ -0,0 +1,132 @@
+package client
+
+import (
+	stdctx "context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+type batchKey struct{}
+
+// batch collects the files published while it is started, grouped by
+// repository, branch and commit author.
+type batch struct {
+	lock      sync.Mutex
+	committed bool
+	groups    []*batchGroup
+}
+
+type batchGroup struct {
+	cli          Client
+	ref          config.RepoRef
+	commitAuthor config.CommitAuthor
+	files        []RepoFile
+	messages     []string
+}
+
+// StartBatch makes PublishFile collect the files instead of committing them,
+// until CommitBatch is called.
+func StartBatch(ctx *context.Context) {
+	ctx.Context = stdctx.WithValue(ctx.Context, batchKey{}, &batch{})
+}
+
+// CommitBatch commits the files collected since StartBatch, creating a single
+// commit per repository and branch, and stops collecting them.
+func CommitBatch(ctx *context.Context) error {
+	b := batchFrom(ctx)
+	if b == nil {
+		return nil
+	}
+
+	b.lock.Lock()
+	b.committed = true
+	groups := b.groups
+	b.groups = nil
+	b.lock.Unlock()
+
+	for _, g := range groups {
+		repo := RepoFromRef(g.ref)
+		log.WithField("repo", repo.String()).
+			WithField("files", len(g.files)).
+			Info("committing")
+		if err := publishFiles(ctx, g.cli, g.ref, g.commitAuthor, g.files, batchMessage(ctx, g.messages)); err != nil {
+			return fmt.Errorf("failed to publish files to %s: %w", repo, err)
+		}
+	}
+	return nil
+}
+
+func batchFrom(ctx *context.Context) *batch {
+	if ctx == nil || ctx.Context == nil {
+		return nil
+	}
+	b, ok := ctx.Value(batchKey{}).(*batch)
+	if !ok {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.committed {
+		return nil
+	}
+	return b
+}
+
+func (b *batch) add(cli Client, ref config.RepoRef, commitAuthor config.CommitAuthor, file RepoFile, message string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	group := b.groupFor(cli, ref, commitAuthor)
+	group.messages = append(group.messages, message)
+	for i := range group.files {
+		if group.files[i].Path == file.Path {
+			log.WithField("file", file.Path).Warn("file published more than once, using the last one")
+			group.files[i] = file
+			return
+		}
+	}
+	group.files = append(group.files, file)
+}
+
+func (b *batch) groupFor(cli Client, ref config.RepoRef, commitAuthor config.CommitAuthor) *batchGroup {
+	for _, g := range b.groups {
+		if g.ref == ref && g.commitAuthor == commitAuthor {
+			return g
+		}
+	}
+	g := &batchGroup{
+		cli:          cli,
+		ref:          ref,
+		commitAuthor: commitAuthor,
+	}
+	b.groups = append(b.groups, g)
+	return g
+}
+
+// batchMessage aggregates the commit messages of the files of a group.
+func batchMessage(ctx *context.Context, messages []string) string {
+	var unique []string
+	seen := map[string]bool{}
+	for _, msg := range messages {
+		if seen[msg] {
+			continue
+		}
+		seen[msg] = true
+		unique = append(unique, msg)
+	}
+	if len(unique) == 1 {
+		return unique[0]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Package manager updates for %s version %s\n", ctx.Config.ProjectName, ctx.Git.CurrentTag)
+	for _, msg := range unique {
+		fmt.Fprintf(&sb, "\n- %s", strings.SplitN(msg, "\n", 2)[0])
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitBatchWithoutStart(t *testing.T) {
+	require.NoError(t, CommitBatch(context.New(config.Project{})))
+}
+
+func TestCommitBatch(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "proj"})
+	ctx.Git.CurrentTag = "v1.2.3"
+	StartBatch(ctx)
+
+	cli := NewMock()
+	other := NewMock()
+	ref := config.RepoRef{Owner: "foo", Name: "bar", Branch: "main"}
+	otherRef := config.RepoRef{Owner: "foo", Name: "other"}
+	author := config.CommitAuthor{Name: "bot", Email: "bot@example.com"}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, author, []byte("formula"), "Formula/proj.rb", "Brew formula update for proj version v1.2.3"))
+	require.NoError(t, PublishFile(ctx, cli, ref, author, []byte("manifest"), "bucket/proj.json", "Scoop update for proj version v1.2.3"))
+	require.NoError(t, PublishFile(ctx, other, otherRef, author, []byte("derivation"), "pkgs/proj/default.nix", "Nix update"))
+	require.False(t, cli.CreatedFile)
+	require.False(t, other.CreatedFile)
+
+	require.NoError(t, CommitBatch(ctx))
+	require.Equal(t, 1, cli.Commits)
+	require.Equal(t, Repo{Owner: "foo", Name: "bar", Branch: "main"}, cli.FileRepo)
+	require.Equal(t, []RepoFile{
+		{Path: "Formula/proj.rb", Content: []byte("formula")},
+		{Path: "bucket/proj.json", Content: []byte("manifest")},
+	}, cli.Files)
+	require.Equal(t, "Package manager updates for proj version v1.2.3\n\n- Brew formula update for proj version v1.2.3\n- Scoop update for proj version v1.2.3", cli.CommitMessage)
+
+	require.Equal(t, 1, other.Commits)
+	require.Equal(t, "pkgs/proj/default.nix", other.Path)
+	require.Equal(t, "Nix update", other.CommitMessage)
+
+	// files published after the commit are not batched anymore
+	require.NoError(t, PublishFile(ctx, cli, ref, author, []byte("formula v2"), "Formula/proj.rb", "again"))
+	require.Equal(t, 2, cli.Commits)
+	require.Equal(t, "again", cli.CommitMessage)
+}
+
+func TestCommitBatchSameMessage(t *testing.T) {
+	ctx := context.New(config.Project{})
+	StartBatch(ctx)
+	cli := NewMock()
+	ref := config.RepoRef{Owner: "foo", Name: "bar"}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("a"), "a.txt", "update"))
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("b"), "b.txt", "update"))
+	require.NoError(t, CommitBatch(ctx))
+	require.Equal(t, 1, cli.Commits)
+	require.Len(t, cli.Files, 2)
+	require.Equal(t, "update", cli.CommitMessage)
+}
+
+func TestCommitBatchDifferentAuthors(t *testing.T) {
+	ctx := context.New(config.Project{})
+	StartBatch(ctx)
+	cli := NewMock()
+	ref := config.RepoRef{Owner: "foo", Name: "bar"}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{Name: "a"}, []byte("a"), "a.txt", "update"))
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{Name: "b"}, []byte("b"), "b.txt", "update"))
+	require.NoError(t, CommitBatch(ctx))
+	require.Equal(t, 2, cli.Commits)
+}
+
+func TestCommitBatchDuplicatedPath(t *testing.T) {
+	ctx := context.New(config.Project{})
+	StartBatch(ctx)
+	cli := NewMock()
+	ref := config.RepoRef{Owner: "foo", Name: "bar"}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("first"), "a.txt", "update"))
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("second"), "a.txt", "update"))
+	require.NoError(t, CommitBatch(ctx))
+	require.Equal(t, 1, cli.Commits)
+	require.Equal(t, []RepoFile{{Path: "a.txt", Content: []byte("second")}}, cli.Files)
+}
+
+func TestCommitBatchPullRequest(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "proj"})
+	ctx.Version = "1.2.3"
+	ctx.Git.CurrentTag = "v1.2.3"
+	StartBatch(ctx)
+	cli := NewMock()
+	ref := config.RepoRef{
+		Owner:  "foo",
+		Name:   "bar",
+		Branch: "main",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+		},
+	}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("a"), "a.txt", "update a"))
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("b"), "b.txt", "update b"))
+	require.False(t, cli.OpenedPullRequest)
+	require.NoError(t, CommitBatch(ctx))
+
+	head := Repo{Owner: "foo", Name: "bar", Branch: "proj-1.2.3"}
+	require.Equal(t, head, cli.CreatedBranch)
+	require.Equal(t, head, cli.FileRepo)
+	require.Equal(t, 1, cli.Commits)
+	require.True(t, cli.OpenedPullRequest)
+	require.Equal(t, "Package manager updates for proj version v1.2.3", cli.PullRequestTitle)
+}
+
+func TestCommitBatchFails(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "proj"})
+	StartBatch(ctx)
+	cli := NewMock()
+	cli.FailToOpenPR = true
+	ref := config.RepoRef{
+		Owner: "foo",
+		Name:  "bar",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+			Branch:  "update",
+		},
+	}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("a"), "a.txt", "update a"))
+	err := CommitBatch(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to publish files to foo/bar: could not open pull request")
+}
@@ -42,12 +42,21 @@ func (r Repo) String() string {
 	return r.Owner + "/" + r.Name
 }
 
+// RepoFile is a file to be committed to a repository.
+type RepoFile struct {
+	Path    string
+	Content []byte
+}
+
 // Client interface.
 type Client interface {
 	CloseMilestone(ctx *context.Context, repo Repo, title string) (err error)
 	CreateRelease(ctx *context.Context, body string) (releaseID string, err error)
 	ReleaseURLTemplate(ctx *context.Context) (string, error)
 	CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo Repo, content []byte, path, message string) (err error)
+	CreateFiles(ctx *context.Context, commitAuthor config.CommitAuthor, repo Repo, files []RepoFile, message string) (err error)
+	CreateBranch(ctx *context.Context, base, head Repo) (err error)
+	OpenPullRequest(ctx *context.Context, base, head Repo, title, body string, draft bool) (err error)
 	Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) (err error)
 	GetDefaultBranch(ctx *context.Context, repo Repo) (string, error)
 	Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error)
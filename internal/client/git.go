@@ -0,0 +1,371 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	defaultGitSSHCommand = "ssh -i {{ .KeyPath }} -o StrictHostKeyChecking=accept-new -F /dev/null"
+	gitPushAttempts      = 3
+)
+
+var errPushRejected = errors.New("push rejected")
+
+var _ Client = &gitClient{}
+
+type gitClient struct {
+	repo config.GitRepoRef
+}
+
+// NewGit returns a client that publishes files to a repository in any git
+// server, over ssh or https, with git itself.
+// It can only create files.
+func NewGit(repo config.GitRepoRef) Client {
+	return &gitClient{repo: repo}
+}
+
+// CreateFile creates or updates a file in the repository.
+func (c *gitClient) CreateFile(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	content []byte,
+	path,
+	message string,
+) error {
+	return c.CreateFiles(ctx, commitAuthor, repo, []RepoFile{{Path: path, Content: content}}, message)
+}
+
+// CreateFiles creates or updates all the given files in a single commit.
+func (c *gitClient) CreateFiles(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	files []RepoFile,
+	message string,
+) error {
+	remote, cleanup, err := c.remote(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return commitWithGit(ctx, remote, commitAuthor, repo.Branch, files, message)
+}
+
+// remote returns the remote of the repository, and a function to clean up
+// the private key file it might have created.
+// With a private key, git uses ssh with it, otherwise it uses whatever is
+// set up in the environment, e.g. an ssh agent or a credential helper.
+func (c *gitClient) remote(ctx *context.Context) (gitRemote, func(), error) {
+	cleanup := func() {}
+	repo := c.repo
+	t := tmpl.New(ctx)
+	for _, field := range []*string{&repo.URL, &repo.PrivateKey} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return gitRemote{}, cleanup, err
+		}
+	}
+	if repo.URL == "" {
+		return gitRemote{}, cleanup, errors.New("git url is not set")
+	}
+
+	remote := gitRemote{url: repo.URL, name: repo.URL}
+	if repo.PrivateKey == "" {
+		return remote, cleanup, nil
+	}
+
+	keyPath, cleanup, err := keyFile(repo.PrivateKey)
+	if err != nil {
+		return gitRemote{}, cleanup, fmt.Errorf("failed to write private key: %w", err)
+	}
+	if repo.SSHCommand == "" {
+		repo.SSHCommand = defaultGitSSHCommand
+	}
+	cmd, err := t.WithExtraFields(tmpl.Fields{
+		"KeyPath": keyPath,
+	}).Apply(repo.SSHCommand)
+	if err != nil {
+		return gitRemote{}, cleanup, err
+	}
+	remote.env = []string{"GIT_SSH_COMMAND=" + cmd}
+	return remote, cleanup, nil
+}
+
+func (c *gitClient) CloseMilestone(ctx *context.Context, repo Repo, title string) error {
+	return ErrNotImplemented
+}
+
+func (c *gitClient) CreateRelease(ctx *context.Context, body string) (string, error) {
+	return "", ErrNotImplemented
+}
+
+func (c *gitClient) ReleaseURLTemplate(ctx *context.Context) (string, error) {
+	return "", ErrNotImplemented
+}
+
+func (c *gitClient) CreateBranch(ctx *context.Context, base, head Repo) error {
+	return ErrNotImplemented
+}
+
+func (c *gitClient) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string, draft bool) error {
+	return ErrNotImplemented
+}
+
+func (c *gitClient) Upload(ctx *context.Context, releaseID string, artifact *artifact.Artifact, file *os.File) error {
+	return ErrNotImplemented
+}
+
+func (c *gitClient) GetDefaultBranch(ctx *context.Context, repo Repo) (string, error) {
+	return "", ErrNotImplemented
+}
+
+func (c *gitClient) Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error) {
+	return "", ErrNotImplemented
+}
+
+// gitRemote is a repository the files are committed to with git itself,
+// instead of through the API of its host.
+// That is needed to sign the commits, as the APIs sign them with the keys of
+// the host, if at all.
+type gitRemote struct {
+	url  string
+	name string
+	env  []string
+}
+
+// httpsRemote returns the remote of the repo in the given server, using the
+// token to authenticate.
+// The token is passed to git as an extra header, so it doesn't end up in
+// the remote configuration or in error messages.
+func httpsRemote(server, username, token string, repo Repo) gitRemote {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+	return gitRemote{
+		url:  strings.TrimSuffix(server, "/") + "/" + repo.Owner + "/" + repo.Name + ".git",
+		name: repo.String(),
+		env: []string{
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+		},
+	}
+}
+
+// commitWithGit clones the remote, commits the files to the given branch, or
+// the default one if empty, and pushes it back.
+// The branch is created from the default one if it does not exist.
+// If the push is rejected because the branch changed in the meantime, it
+// starts over, a few times.
+func commitWithGit(
+	ctx *context.Context,
+	remote gitRemote,
+	commitAuthor config.CommitAuthor,
+	branch string,
+	files []RepoFile,
+	message string,
+) error {
+	signing, cleanup, err := signingArgs(ctx, commitAuthor.Signing)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	for attempt := 1; ; attempt++ {
+		err := pushWithGit(ctx, remote, commitAuthor, signing, branch, files, message)
+		if !errors.Is(err, errPushRejected) || attempt == gitPushAttempts {
+			return err
+		}
+		log.WithField("repo", remote.name).
+			WithField("attempt", attempt).
+			Warn("push rejected, trying again")
+	}
+}
+
+func pushWithGit(
+	ctx *context.Context,
+	remote gitRemote,
+	commitAuthor config.CommitAuthor,
+	signing []string,
+	branch string,
+	files []RepoFile,
+	message string,
+) error {
+	dir, err := os.MkdirTemp("", "goreleaser-git-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	log.WithField("repo", remote.name).
+		WithField("branch", branch).
+		WithField("files", len(files)).
+		Debug("committing with git")
+
+	if _, err := runGit(ctx, "", remote.env, "clone", "--depth", "1", remote.url, dir); err != nil {
+		return fmt.Errorf("could not clone %s: %w", remote.name, err)
+	}
+
+	push := "HEAD"
+	if branch != "" {
+		push = "HEAD:refs/heads/" + branch
+		if err := checkoutBranch(ctx, dir, remote, branch); err != nil {
+			return fmt.Errorf("could not checkout branch %s of %s: %w", branch, remote.name, err)
+		}
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, file.Content, 0o644); err != nil { //nolint: gosec
+			return err
+		}
+	}
+
+	if _, err := runGit(ctx, dir, remote.env, "add", "-A", "."); err != nil {
+		return fmt.Errorf("could not commit to %s: %w", remote.name, err)
+	}
+	status, err := runGit(ctx, dir, remote.env, "status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("could not commit to %s: %w", remote.name, err)
+	}
+	if strings.TrimSpace(status) == "" {
+		log.WithField("repo", remote.name).Info("files are already up to date")
+		return nil
+	}
+
+	args := append([]string{
+		"-c", "user.name=" + commitAuthor.Name,
+		"-c", "user.email=" + commitAuthor.Email,
+	}, signing...)
+	args = append(args, "commit", "-m", message)
+	if _, err := runGit(ctx, dir, remote.env, args...); err != nil {
+		return fmt.Errorf("could not commit to %s: %w", remote.name, err)
+	}
+	if _, err := runGit(ctx, dir, remote.env, "push", "origin", push); err != nil {
+		if strings.Contains(err.Error(), "(fetch first)") || strings.Contains(err.Error(), "(non-fast-forward)") {
+			return fmt.Errorf("could not push to %s: %w: %s", remote.name, errPushRejected, err)
+		}
+		return fmt.Errorf("could not push to %s: %w", remote.name, err)
+	}
+	return nil
+}
+
+// checkoutBranch checks out the given branch, creating it from the current
+// one if it does not exist in the remote yet.
+func checkoutBranch(ctx *context.Context, dir string, remote gitRemote, branch string) error {
+	heads, err := runGit(ctx, dir, remote.env, "ls-remote", "--heads", "origin", branch)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(heads) == "" {
+		_, err := runGit(ctx, dir, remote.env, "checkout", "-B", branch)
+		return err
+	}
+	if _, err := runGit(ctx, dir, remote.env, "fetch", "--depth", "1", "origin", "refs/heads/"+branch); err != nil {
+		return err
+	}
+	_, err = runGit(ctx, dir, remote.env, "checkout", "-B", branch, "FETCH_HEAD")
+	return err
+}
+
+// signingArgs returns the git configuration needed to sign the commits, and
+// a function to clean up the files it might have created.
+// If the format is ssh, the key can either be a path to a key file or the key
+// itself.
+func signingArgs(ctx *context.Context, signing config.CommitSigning) ([]string, func(), error) {
+	cleanup := func() {}
+	if !signing.Enabled {
+		return []string{"-c", "commit.gpgSign=false"}, cleanup, nil
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{&signing.Key, &signing.Program} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return nil, cleanup, err
+		}
+	}
+
+	var programKey string
+	switch signing.Format {
+	case "", "openpgp":
+		signing.Format = "openpgp"
+		programKey = "gpg.program"
+	case "x509":
+		programKey = "gpg.x509.program"
+	case "ssh":
+		programKey = "gpg.ssh.program"
+	default:
+		return nil, cleanup, fmt.Errorf("invalid commit signing format: %s", signing.Format)
+	}
+
+	if signing.Format == "ssh" && signing.Key != "" && !strings.HasPrefix(signing.Key, "key::") {
+		var err error
+		if signing.Key, cleanup, err = keyFile(signing.Key); err != nil {
+			return nil, cleanup, fmt.Errorf("failed to write signing key: %w", err)
+		}
+	}
+
+	args := []string{
+		"-c", "commit.gpgSign=true",
+		"-c", "gpg.format=" + signing.Format,
+	}
+	if signing.Key != "" {
+		args = append(args, "-c", "user.signingKey="+signing.Key)
+	}
+	if signing.Program != "" {
+		args = append(args, "-c", programKey+"="+signing.Program)
+	}
+	return args, cleanup, nil
+}
+
+// keyFile returns the path of the given key, writing it to a temporary file
+// if it is not a path already, and a function to remove that file.
+func keyFile(key string) (string, func(), error) {
+	cleanup := func() {}
+	if _, err := os.Stat(key); err == nil {
+		return key, cleanup, nil
+	}
+	f, err := os.CreateTemp("", "goreleaser-key-*")
+	if err != nil {
+		return "", cleanup, err
+	}
+	cleanup = func() { _ = os.Remove(f.Name()) }
+	if _, err := f.WriteString(strings.TrimSpace(key) + "\n"); err != nil {
+		_ = f.Close()
+		return "", cleanup, err
+	}
+	return f.Name(), cleanup, f.Close()
+}
+
+func runGit(ctx *context.Context, dir string, env []string, args ...string) (string, error) {
+	/* #nosec */
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	log.WithField("args", args).Debug("running git")
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
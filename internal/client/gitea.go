@@ -1,9 +1,12 @@
 package client
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,6 +22,12 @@ import (
 
 type giteaClient struct {
 	client *gitea.Client
+
+	// the sdk does not support all the endpoints we need, so we keep what
+	// we need to call them directly.
+	url   string
+	token string
+	http  *http.Client
 }
 
 func getInstanceURL(ctx *context.Context) (string, error) {
@@ -63,7 +72,12 @@ func NewGitea(ctx *context.Context, token string) (Client, error) {
 	if ctx != nil {
 		gitea.SetContext(ctx)(client)
 	}
-	return &giteaClient{client: client}, nil
+	return &giteaClient{
+		client: client,
+		url:    instanceURL,
+		token:  token,
+		http:   httpClient,
+	}, nil
 }
 
 func (c *giteaClient) Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error) {
@@ -109,6 +123,10 @@ func (c *giteaClient) CreateFile(
 	path,
 	message string,
 ) error {
+	if commitAuthor.Signing.Enabled {
+		return c.CreateFiles(ctx, commitAuthor, repo, []RepoFile{{Path: path, Content: content}}, message)
+	}
+
 	// use default branch
 	var branch string
 	var err error
@@ -163,6 +181,236 @@ func (c *giteaClient) CreateFile(
 	return err
 }
 
+type giteaChangeFileOperation struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	SHA       string `json:"sha,omitempty"`
+}
+
+type giteaChangeFilesOptions struct {
+	gitea.FileOptions
+	Files []giteaChangeFileOperation `json:"files"`
+}
+
+// CreateFiles creates or updates all the given files in a single commit,
+// using the change files API, available since Gitea 1.20.
+// Older instances get one commit per file.
+// Signed commits are pushed with git, as the API can't create them.
+func (c *giteaClient) CreateFiles(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	files []RepoFile,
+	message string,
+) error {
+	if commitAuthor.Signing.Enabled {
+		remote, err := c.gitRemote(ctx, repo)
+		if err != nil {
+			return err
+		}
+		return commitWithGit(ctx, remote, commitAuthor, repo.Branch, files, message)
+	}
+
+	if err := c.client.CheckServerVersionConstraint(">=1.20"); err != nil {
+		log.WithField("repo", repo.String()).
+			Warn("gitea instance can't commit multiple files at once, creating one commit per file")
+		for _, file := range files {
+			if err := c.CreateFile(ctx, commitAuthor, repo, file.Content, file.Path, message); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	branch := repo.Branch
+	if branch == "" {
+		var err error
+		branch, err = c.GetDefaultBranch(ctx, repo)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"projectID": repo.String(),
+				"err":       err.Error(),
+			}).Warn("error checking for default branch, using master")
+			branch = "master"
+		}
+	}
+
+	identity := gitea.Identity{
+		Name:  commitAuthor.Name,
+		Email: commitAuthor.Email,
+	}
+	opts := giteaChangeFilesOptions{
+		FileOptions: gitea.FileOptions{
+			Message:    message,
+			BranchName: branch,
+			Author:     identity,
+			Committer:  identity,
+		},
+	}
+	for _, file := range files {
+		op := giteaChangeFileOperation{
+			Operation: "create",
+			Path:      file.Path,
+			Content:   base64.StdEncoding.EncodeToString(file.Content),
+		}
+		current, resp, err := c.client.GetContents(repo.Owner, repo.Name, branch, file.Path)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return err
+		}
+		if err == nil {
+			op.Operation = "update"
+			op.SHA = current.SHA
+		}
+		opts.Files = append(opts.Files, op)
+	}
+
+	body, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/contents", c.url, url.PathEscape(repo.Owner), url.PathEscape(repo.Name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "token "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		bts, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("could not commit files to %s: %s: %s", repo, resp.Status, bytes.TrimSpace(bts))
+	}
+	return nil
+}
+
+func (c *giteaClient) gitRemote(ctx *context.Context, repo Repo) (gitRemote, error) {
+	server, err := tmpl.New(ctx).Apply(ctx.Config.GiteaURLs.Download)
+	if err != nil {
+		return gitRemote{}, fmt.Errorf("templating Gitea download URL: %w", err)
+	}
+	if server == "" {
+		server = c.url
+	}
+	return httpsRemote(server, "oauth2", c.token, repo), nil
+}
+
+// giteaCreateBranchOptions adds the commit to create the branch from,
+// available since Gitea 1.21, to the options of the sdk.
+type giteaCreateBranchOptions struct {
+	gitea.CreateBranchOption
+	OldRefName string `json:"old_ref_name,omitempty"`
+}
+
+// CreateBranch creates the head branch from the base branch.
+// In a fork, the branch is created from the commit the base branch points
+// to upstream, as the branch of the same name in the fork might be behind.
+// If the fork is too far behind to have that commit, or if the instance is
+// older than Gitea 1.21, which can't create branches from commits, the branch
+// is created from the default branch of the fork instead.
+func (c *giteaClient) CreateBranch(ctx *context.Context, base, head Repo) error {
+	_, res, err := c.client.GetRepoBranch(head.Owner, head.Name, head.Branch)
+	if err == nil {
+		log.WithField("branch", head.Branch).Debug("branch already exists")
+		return nil
+	}
+	if res == nil || res.StatusCode != http.StatusNotFound {
+		return err
+	}
+	opts := gitea.CreateBranchOption{
+		BranchName:    head.Branch,
+		OldBranchName: base.Branch,
+	}
+	if head.String() == base.String() {
+		_, _, err = c.client.CreateBranch(head.Owner, head.Name, opts)
+		return err
+	}
+	if err := c.client.CheckServerVersionConstraint(">=1.21"); err != nil {
+		log.WithField("repo", head.String()).
+			Warn("gitea instance can't create branches from commits, creating the branch from the default branch of the fork")
+		return c.createForkBranch(ctx, head)
+	}
+
+	branch, _, err := c.client.GetRepoBranch(base.Owner, base.Name, base.Branch)
+	if err != nil {
+		return fmt.Errorf("could not get branch %s of %s: %w", base.Branch, base, err)
+	}
+	body, err := json.Marshal(giteaCreateBranchOptions{
+		CreateBranchOption: opts,
+		OldRefName:         branch.Commit.ID,
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/repos/%s/%s/branches", c.url, url.PathEscape(head.Owner), url.PathEscape(head.Name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "token "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		log.WithField("repo", head.String()).
+			Warn("fork is behind, creating the branch from its default branch")
+		return c.createForkBranch(ctx, head)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		bts, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("could not create branch %s in %s: %s: %s", head.Branch, head, resp.Status, bytes.TrimSpace(bts))
+	}
+	return nil
+}
+
+// createForkBranch creates the head branch from the default branch of the
+// fork.
+func (c *giteaClient) createForkBranch(ctx *context.Context, head Repo) error {
+	ref, err := c.GetDefaultBranch(ctx, head)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.client.CreateBranch(head.Owner, head.Name, gitea.CreateBranchOption{
+		BranchName:    head.Branch,
+		OldBranchName: ref,
+	})
+	return err
+}
+
+func (c *giteaClient) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string, draft bool) error {
+	if draft {
+		title = "WIP: " + title
+	}
+	branch := head.Branch
+	if head.String() != base.String() {
+		branch = head.Owner + ":" + head.Branch
+	}
+	pr, res, err := c.client.CreatePullRequest(base.Owner, base.Name, gitea.CreatePullRequestOption{
+		Head:  branch,
+		Base:  base.Branch,
+		Title: title,
+		Body:  body,
+	})
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusConflict {
+			log.WithField("branch", head.Branch).Info("pull request already exists")
+			return nil
+		}
+		return err
+	}
+	log.WithField("url", pr.HTMLURL).Info("pull request opened")
+	return nil
+}
+
 func (c *giteaClient) createRelease(ctx *context.Context, title, body string) (*gitea.Release, error) {
 	releaseConfig := ctx.Config.Release
 	owner := releaseConfig.Gitea.Owner
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -627,3 +628,322 @@ func TestGiteaChangelog(t *testing.T) {
 	_, err = client.Changelog(ctx, repo, "v1.0.0", "v1.1.0")
 	require.EqualError(t, err, ErrNotImplemented.Error())
 }
+
+func TestGiteaCreateBranch(t *testing.T) {
+	var created string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "api/v1/version"):
+			fmt.Fprint(w, `{"version":"1.15.0"}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "repos/someone/something/branches/update"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "repos/someone/something/branches"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			created = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"name": "update"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "someone", Name: "something", Branch: "update"}
+	require.NoError(t, client.CreateBranch(ctx, base, head))
+	require.JSONEq(t, `{"new_branch_name": "update", "old_branch_name": "main"}`, created)
+}
+
+func TestGiteaCreateBranchFork(t *testing.T) {
+	for name, tt := range map[string]struct {
+		version  string
+		behind   bool
+		expected []string
+	}{
+		"from upstream commit": {
+			version: "1.21.0",
+			expected: []string{
+				`{"new_branch_name": "update", "old_branch_name": "main", "old_ref_name": "abc123"}`,
+			},
+		},
+		"fork behind": {
+			version: "1.21.0",
+			behind:  true,
+			expected: []string{
+				`{"new_branch_name": "update", "old_branch_name": "main", "old_ref_name": "abc123"}`,
+				`{"new_branch_name": "update", "old_branch_name": "trunk"}`,
+			},
+		},
+		"old instance": {
+			version: "1.20.0",
+			expected: []string{
+				`{"new_branch_name": "update", "old_branch_name": "trunk"}`,
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var created []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				switch {
+				case strings.HasSuffix(r.URL.Path, "api/v1/version"):
+					fmt.Fprintf(w, `{"version":"%s"}`, tt.version)
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "repos/me/something/branches/update"):
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, "{}")
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "repos/someone/something/branches/main"):
+					fmt.Fprint(w, `{"name": "main", "commit": {"id": "abc123"}}`)
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "repos/me/something"):
+					fmt.Fprint(w, `{"default_branch": "trunk"}`)
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "repos/me/something/branches"):
+					require.Equal(t, "token test-token", r.Header.Get("Authorization"))
+					bts, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					created = append(created, string(bts))
+					if tt.behind && strings.Contains(string(bts), "abc123") {
+						w.WriteHeader(http.StatusNotFound)
+						fmt.Fprint(w, `{"message": "The old branch does not exist"}`)
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{"name": "update"}`)
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer srv.Close()
+
+			ctx := context.New(config.Project{
+				GiteaURLs: config.GiteaURLs{
+					API: srv.URL,
+				},
+			})
+			client, err := NewGitea(ctx, "test-token")
+			require.NoError(t, err)
+
+			base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+			head := Repo{Owner: "me", Name: "something", Branch: "update"}
+			require.NoError(t, client.CreateBranch(ctx, base, head))
+			require.Len(t, created, len(tt.expected))
+			for i, expected := range tt.expected {
+				require.JSONEq(t, expected, created[i])
+			}
+		})
+	}
+}
+
+func TestGiteaOpenPullRequest(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "api/v1/version"):
+			fmt.Fprint(w, `{"version":"1.15.0"}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "repos/someone/something/pulls"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"html_url": "https://gitea.com/someone/something/pulls/1"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", true))
+	require.Contains(t, body, `"head":"me:update"`)
+	require.Contains(t, body, `"base":"main"`)
+	require.Contains(t, body, `"title":"WIP: the title"`)
+	require.Contains(t, body, `"body":"the body"`)
+}
+
+func TestGiteaOpenPullRequestExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if strings.HasSuffix(r.URL.Path, "api/v1/version") {
+			fmt.Fprint(w, `{"version":"1.15.0"}`)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"message": "pull request already exists for these targets"}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "someone", Name: "something", Branch: "update"}
+	require.NoError(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", false))
+}
+
+func TestGiteaCreateFiles(t *testing.T) {
+	var body, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "api/v1/version"):
+			fmt.Fprint(w, `{"version":"1.20.0"}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "repos/someone/something/contents/existing.rb"):
+			fmt.Fprint(w, `{"path": "existing.rb", "sha": "abc123"}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "repos/someone/something/contents/new.json"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "repos/someone/something/contents"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(bts)
+			auth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, "{}")
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFiles(ctx, config.CommitAuthor{Name: "bot", Email: "bot@example.com"}, repo, []RepoFile{
+		{Path: "existing.rb", Content: []byte("formula")},
+		{Path: "new.json", Content: []byte("manifest")},
+	}, "update foo"))
+	require.Equal(t, "token test-token", auth)
+	require.Contains(t, body, `"message":"update foo"`)
+	require.Contains(t, body, `"branch":"main"`)
+	require.Contains(t, body, `"author":{"name":"bot","email":"bot@example.com"}`)
+	require.Contains(t, body, `"files":[{"operation":"update","path":"existing.rb","content":"Zm9ybXVsYQ==","sha":"abc123"},{"operation":"create","path":"new.json","content":"bWFuaWZlc3Q="}]`)
+}
+
+func TestGiteaCreateFilesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "api/v1/version"):
+			fmt.Fprint(w, `{"version":"1.20.0"}`)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		default:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message": "sha does not match"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.EqualError(t, client.CreateFiles(ctx, config.CommitAuthor{}, repo, []RepoFile{
+		{Path: "new.json", Content: []byte("manifest")},
+	}, "update foo"), `could not commit files to someone/something: 409 Conflict: {"message": "sha does not match"}`)
+}
+
+func TestGiteaCreateFilesOldVersion(t *testing.T) {
+	var created []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "api/v1/version"):
+			fmt.Fprint(w, `{"version":"1.15.0"}`)
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "repos/someone/something/contents/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "repos/someone/something/contents/"):
+			created = append(created, strings.TrimPrefix(r.URL.Path, "/api/v1/repos/someone/something/contents/"))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, "{}")
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFiles(ctx, config.CommitAuthor{}, repo, []RepoFile{
+		{Path: "existing.rb", Content: []byte("formula")},
+		{Path: "new.json", Content: []byte("manifest")},
+	}, "update foo"))
+	require.Equal(t, []string{"existing.rb", "new.json"}, created)
+}
+
+func TestGiteaCreateFilesSigned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if strings.HasSuffix(r.URL.Path, "api/v1/version") {
+			fmt.Fprint(w, `{"version":"1.20.0"}`)
+			return
+		}
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	bare := newBareRepo(t, dir, "someone", "something")
+	ctx := context.New(config.Project{
+		GiteaURLs: config.GiteaURLs{
+			API:      srv.URL,
+			Download: "file://" + dir,
+		},
+	})
+	client, err := NewGitea(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFiles(ctx, sshSigningAuthor(t), repo, []RepoFile{
+		{Path: "Formula/foo.rb", Content: []byte("formula")},
+		{Path: "bucket/foo.json", Content: []byte("manifest")},
+	}, "update foo"))
+	require.Equal(t, "formula", gitCmd(t, "-C", bare, "show", "main:Formula/foo.rb"))
+	require.Equal(t, "manifest", gitCmd(t, "-C", bare, "show", "main:bucket/foo.json"))
+	require.Contains(t, gitCmd(t, "-C", bare, "cat-file", "commit", "main"), "-----BEGIN SSH SIGNATURE-----")
+}
@@ -23,6 +23,7 @@ const DefaultGitHubDownloadURL = "https://github.com"
 
 type githubClient struct {
 	client *github.Client
+	token  string
 }
 
 // NewGitHub returns a github client implementation.
@@ -49,7 +50,7 @@ func NewGitHub(ctx *context.Context, token string) (GitHubClient, error) {
 		return &githubClient{}, err
 	}
 
-	return &githubClient{client: client}, nil
+	return &githubClient{client: client, token: token}, nil
 }
 
 func (c *githubClient) GenerateReleaseNotes(ctx *context.Context, repo Repo, prev, current string) (string, error) {
@@ -137,6 +138,10 @@ func (c *githubClient) CreateFile(
 	path,
 	message string,
 ) error {
+	if commitAuthor.Signing.Enabled {
+		return c.CreateFiles(ctx, commitAuthor, repo, []RepoFile{{Path: path, Content: content}}, message)
+	}
+
 	var branch string
 	var err error
 	if repo.Branch != "" {
@@ -173,7 +178,7 @@ func (c *githubClient) CreateFile(
 		repo.Owner,
 		repo.Name,
 		path,
-		&github.RepositoryContentGetOptions{},
+		&github.RepositoryContentGetOptions{Ref: branch},
 	)
 	if err != nil && (res == nil || res.StatusCode != 404) {
 		return err
@@ -200,6 +205,137 @@ func (c *githubClient) CreateFile(
 	return err
 }
 
+// CreateFiles creates or updates all the given files in a single commit,
+// using the git trees API.
+// Signed commits are pushed with git instead.
+func (c *githubClient) CreateFiles(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	files []RepoFile,
+	message string,
+) error {
+	if commitAuthor.Signing.Enabled {
+		remote, err := c.gitRemote(ctx, repo)
+		if err != nil {
+			return err
+		}
+		return commitWithGit(ctx, remote, commitAuthor, repo.Branch, files, message)
+	}
+
+	branch := repo.Branch
+	if branch == "" {
+		var err error
+		branch, err = c.GetDefaultBranch(ctx, repo)
+		if err != nil {
+			log.WithField("projectID", repo.String()).
+				WithField("err", err.Error()).
+				Warn("error checking for default branch, using master")
+			branch = "master"
+		}
+	}
+
+	ref, _, err := c.client.Git.GetRef(ctx, repo.Owner, repo.Name, "heads/"+branch)
+	if err != nil {
+		return fmt.Errorf("could not get branch %s of %s: %w", branch, repo, err)
+	}
+	parent, _, err := c.client.Git.GetCommit(ctx, repo.Owner, repo.Name, ref.Object.GetSHA())
+	if err != nil {
+		return fmt.Errorf("could not get commit %s of %s: %w", ref.Object.GetSHA(), repo, err)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(file.Path),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(string(file.Content)),
+		})
+	}
+	tree, _, err := c.client.Git.CreateTree(ctx, repo.Owner, repo.Name, parent.Tree.GetSHA(), entries)
+	if err != nil {
+		return fmt.Errorf("could not create tree in %s: %w", repo, err)
+	}
+	if tree.GetSHA() == parent.Tree.GetSHA() {
+		log.WithField("repo", repo.String()).Info("files are already up to date")
+		return nil
+	}
+
+	author := &github.CommitAuthor{
+		Name:  github.String(commitAuthor.Name),
+		Email: github.String(commitAuthor.Email),
+		Date:  &ctx.Date,
+	}
+	commit, _, err := c.client.Git.CreateCommit(ctx, repo.Owner, repo.Name, &github.Commit{
+		Message:   github.String(message),
+		Tree:      &github.Tree{SHA: tree.SHA},
+		Parents:   []*github.Commit{{SHA: parent.SHA}},
+		Author:    author,
+		Committer: author,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create commit in %s: %w", repo, err)
+	}
+
+	ref.Object.SHA = commit.SHA
+	if _, _, err := c.client.Git.UpdateRef(ctx, repo.Owner, repo.Name, ref, false); err != nil {
+		return fmt.Errorf("could not update branch %s of %s: %w", branch, repo, err)
+	}
+	return nil
+}
+
+func (c *githubClient) gitRemote(ctx *context.Context, repo Repo) (gitRemote, error) {
+	server := ctx.Config.GitHubURLs.Download
+	if server == "" {
+		server = DefaultGitHubDownloadURL
+	}
+	server, err := tmpl.New(ctx).Apply(server)
+	if err != nil {
+		return gitRemote{}, fmt.Errorf("templating GitHub download URL: %w", err)
+	}
+	return httpsRemote(server, "x-access-token", c.token, repo), nil
+}
+
+func (c *githubClient) CreateBranch(ctx *context.Context, base, head Repo) error {
+	_, res, err := c.client.Git.GetRef(ctx, head.Owner, head.Name, "heads/"+head.Branch)
+	if err == nil {
+		log.WithField("branch", head.Branch).Debug("branch already exists")
+		return nil
+	}
+	if res == nil || res.StatusCode != http.StatusNotFound {
+		return err
+	}
+	ref, _, err := c.client.Git.GetRef(ctx, base.Owner, base.Name, "heads/"+base.Branch)
+	if err != nil {
+		return fmt.Errorf("could not get branch %s of %s: %w", base.Branch, base, err)
+	}
+	_, _, err = c.client.Git.CreateRef(ctx, head.Owner, head.Name, &github.Reference{
+		Ref:    github.String("refs/heads/" + head.Branch),
+		Object: &github.GitObject{SHA: ref.Object.SHA},
+	})
+	return err
+}
+
+func (c *githubClient) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string, draft bool) error {
+	pr, res, err := c.client.PullRequests.Create(ctx, base.Owner, base.Name, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head.Owner + ":" + head.Branch),
+		Base:  github.String(base.Branch),
+		Body:  github.String(body),
+		Draft: github.Bool(draft),
+	})
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusUnprocessableEntity && strings.Contains(err.Error(), "already exists") {
+			log.WithField("branch", head.Branch).Info("pull request already exists")
+			return nil
+		}
+		return err
+	}
+	log.WithField("url", pr.GetHTMLURL()).Info("pull request opened")
+	return nil
+}
+
 func (c *githubClient) CreateRelease(ctx *context.Context, body string) (string, error) {
 	var release *github.RepositoryRelease
 	title, err := tmpl.New(ctx).Apply(ctx.Config.Release.NameTemplate)
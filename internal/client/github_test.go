@@ -8,6 +8,7 @@ import (
 	"os"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -323,3 +324,268 @@ func TestReleaseNotes(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "**Full Changelog**: https://github.com/someone/something/compare/v1.0.0...v1.1.0", log)
 }
+
+func TestGitHubCreateBranch(t *testing.T) {
+	var created string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/me/something/git/ref/heads/update":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "abc123"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/me/something/git/refs":
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			created = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"ref": "refs/heads/update", "object": {"sha": "abc123"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.CreateBranch(ctx, base, head))
+	require.JSONEq(t, `{"ref": "refs/heads/update", "sha": "abc123"}`, created)
+}
+
+func TestGitHubCreateBranchExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/ref/heads/update" {
+			fmt.Fprint(w, `{"ref": "refs/heads/update", "object": {"sha": "abc123"}}`)
+			return
+		}
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "someone", Name: "something", Branch: "update"}
+	require.NoError(t, client.CreateBranch(ctx, base, head))
+}
+
+func TestGitHubOpenPullRequest(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if r.Method == http.MethodPost && r.URL.Path == "/repos/someone/something/pulls" {
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"html_url": "https://github.com/someone/something/pull/1"}`)
+			return
+		}
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", true))
+	require.JSONEq(t, `{"title": "the title", "head": "me:update", "base": "main", "body": "the body", "draft": true}`, body)
+}
+
+func TestGitHubOpenPullRequestExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "PullRequest", "code": "custom", "message": "A pull request already exists for me:update."}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", false))
+}
+
+func TestGitHubOpenPullRequestErr(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "PullRequest", "field": "base", "code": "invalid"}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.Error(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", false))
+}
+
+func TestGitHubCreateFiles(t *testing.T) {
+	var tree, commit, ref string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "parent"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/commits/parent":
+			fmt.Fprint(w, `{"sha": "parent", "tree": {"sha": "base-tree"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/someone/something/git/trees":
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			tree = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sha": "new-tree"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/someone/something/git/commits":
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			commit = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sha": "new-commit"}`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/someone/something/git/refs/heads/main":
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			ref = string(bts)
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "new-commit"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFiles(ctx, config.CommitAuthor{Name: "bot", Email: "bot@example.com"}, repo, []RepoFile{
+		{Path: "Formula/foo.rb", Content: []byte("formula")},
+		{Path: "bucket/foo.json", Content: []byte("manifest")},
+	}, "update foo"))
+
+	require.JSONEq(t, `{"base_tree": "base-tree", "tree": [
+		{"path": "Formula/foo.rb", "mode": "100644", "type": "blob", "content": "formula"},
+		{"path": "bucket/foo.json", "mode": "100644", "type": "blob", "content": "manifest"}
+	]}`, tree)
+	require.JSONEq(t, `{
+		"message": "update foo",
+		"tree": "new-tree",
+		"parents": ["parent"],
+		"author": {"name": "bot", "email": "bot@example.com", "date": "2022-01-02T03:04:05Z"},
+		"committer": {"name": "bot", "email": "bot@example.com", "date": "2022-01-02T03:04:05Z"}
+	}`, commit)
+	require.JSONEq(t, `{"sha": "new-commit", "force": false}`, ref)
+}
+
+func TestGitHubCreateFilesUpToDate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "parent"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/someone/something/git/commits/parent":
+			fmt.Fprint(w, `{"sha": "parent", "tree": {"sha": "base-tree"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/someone/something/git/trees":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sha": "base-tree"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFiles(ctx, config.CommitAuthor{}, repo, []RepoFile{
+		{Path: "Formula/foo.rb", Content: []byte("formula")},
+	}, "update foo"))
+}
+
+func TestGitHubCreateFilesNoBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			API: srv.URL + "/",
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something"}
+	err = client.CreateFiles(ctx, config.CommitAuthor{}, repo, []RepoFile{
+		{Path: "Formula/foo.rb", Content: []byte("formula")},
+	}, "update foo")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "could not get branch master of someone/something")
+}
+
+func TestGitHubCreateFileSigned(t *testing.T) {
+	dir := t.TempDir()
+	bare := newBareRepo(t, dir, "someone", "something")
+	ctx := context.New(config.Project{
+		GitHubURLs: config.GitHubURLs{
+			Download: "file://" + dir,
+		},
+	})
+	client, err := NewGitHub(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFile(ctx, sshSigningAuthor(t), repo, []byte("formula"), "Formula/foo.rb", "update foo"))
+	require.Equal(t, "formula", gitCmd(t, "-C", bare, "show", "main:Formula/foo.rb"))
+	require.Contains(t, gitCmd(t, "-C", bare, "cat-file", "commit", "main"), "-----BEGIN SSH SIGNATURE-----")
+}
@@ -19,6 +19,7 @@ const DefaultGitLabDownloadURL = "https://gitlab.com"
 
 type gitlabClient struct {
 	client *gitlab.Client
+	token  string
 }
 
 // NewGitLab returns a gitlab client implementation.
@@ -47,7 +48,7 @@ func NewGitLab(ctx *context.Context, token string) (Client, error) {
 	if err != nil {
 		return &gitlabClient{}, err
 	}
-	return &gitlabClient{client: client}, nil
+	return &gitlabClient{client: client, token: token}, nil
 }
 
 func (c *gitlabClient) Changelog(ctx *context.Context, repo Repo, prev, current string) (string, error) {
@@ -128,6 +129,10 @@ func (c *gitlabClient) CreateFile(
 	path, // the path to the formula.rb
 	message string, // the commit msg
 ) error {
+	if commitAuthor.Signing.Enabled {
+		return c.CreateFiles(ctx, commitAuthor, repo, []RepoFile{{Path: path, Content: content}}, message)
+	}
+
 	fileName := path
 	projectID := repo.String()
 
@@ -251,6 +256,159 @@ func (c *gitlabClient) CreateFile(
 	return nil
 }
 
+// CreateFiles creates or updates all the given files in a single commit.
+// Signed commits are pushed with git, as the API can't create them.
+func (c *gitlabClient) CreateFiles(
+	ctx *context.Context,
+	commitAuthor config.CommitAuthor,
+	repo Repo,
+	files []RepoFile,
+	message string,
+) error {
+	if commitAuthor.Signing.Enabled {
+		remote, err := c.gitRemote(ctx, repo)
+		if err != nil {
+			return err
+		}
+		return commitWithGit(ctx, remote, commitAuthor, repo.Branch, files, message)
+	}
+
+	projectID := repo.String()
+	branch := repo.Branch
+	if branch == "" {
+		var err error
+		branch, err = c.GetDefaultBranch(ctx, repo)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"projectID": projectID,
+				"err":       err.Error(),
+			}).Warn("error checking for default branch, using master")
+			branch = "master"
+		}
+	}
+
+	actions := make([]*gitlab.CommitActionOptions, 0, len(files))
+	for _, file := range files {
+		action := gitlab.FileCreate
+		_, res, err := c.client.RepositoryFiles.GetFileMetaData(projectID, file.Path, &gitlab.GetFileMetaDataOptions{
+			Ref: &branch,
+		})
+		if err != nil && (res == nil || res.StatusCode != http.StatusNotFound) {
+			return err
+		}
+		if err == nil {
+			action = gitlab.FileUpdate
+		}
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:   gitlab.FileAction(action),
+			FilePath: gitlab.String(file.Path),
+			Content:  gitlab.String(string(file.Content)),
+		})
+	}
+
+	commit, _, err := c.client.Commits.CreateCommit(projectID, &gitlab.CreateCommitOptions{
+		Branch:        &branch,
+		CommitMessage: &message,
+		AuthorName:    &commitAuthor.Name,
+		AuthorEmail:   &commitAuthor.Email,
+		Actions:       actions,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"projectID": projectID,
+		"branch":    branch,
+		"commit":    commit.ShortID,
+	}).Debug("committed files")
+	return nil
+}
+
+func (c *gitlabClient) gitRemote(ctx *context.Context, repo Repo) (gitRemote, error) {
+	server := ctx.Config.GitLabURLs.Download
+	if server == "" {
+		server = DefaultGitLabDownloadURL
+	}
+	server, err := tmpl.New(ctx).Apply(server)
+	if err != nil {
+		return gitRemote{}, fmt.Errorf("templating GitLab download URL: %w", err)
+	}
+	return httpsRemote(server, "oauth2", c.token, repo), nil
+}
+
+// CreateBranch creates the head branch from the commit the base branch
+// points to, which, in a fork, might be ahead of the branch of the same name
+// in the fork.
+// If the fork is too far behind to have that commit, the branch is created
+// from the default branch of the fork instead.
+func (c *gitlabClient) CreateBranch(ctx *context.Context, base, head Repo) error {
+	projectID := head.String()
+	_, res, err := c.client.Branches.GetBranch(projectID, head.Branch)
+	if err == nil {
+		log.WithField("branch", head.Branch).Debug("branch already exists")
+		return nil
+	}
+	if res == nil || res.StatusCode != http.StatusNotFound {
+		return err
+	}
+	branch, _, err := c.client.Branches.GetBranch(base.String(), base.Branch)
+	if err != nil {
+		return fmt.Errorf("could not get branch %s of %s: %w", base.Branch, base, err)
+	}
+	_, res, err = c.client.Branches.CreateBranch(projectID, &gitlab.CreateBranchOptions{
+		Branch: &head.Branch,
+		Ref:    &branch.Commit.ID,
+	})
+	if err == nil || head.String() == base.String() || res == nil || res.StatusCode != http.StatusBadRequest {
+		return err
+	}
+
+	ref, err := c.GetDefaultBranch(ctx, head)
+	if err != nil {
+		return err
+	}
+	log.WithField("repo", head.String()).
+		WithField("branch", ref).
+		Warn("fork is behind, creating the branch from its default branch")
+	_, _, err = c.client.Branches.CreateBranch(projectID, &gitlab.CreateBranchOptions{
+		Branch: &head.Branch,
+		Ref:    &ref,
+	})
+	return err
+}
+
+func (c *gitlabClient) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string, draft bool) error {
+	if draft {
+		title = "Draft: " + title
+	}
+	opts := &gitlab.CreateMergeRequestOptions{
+		Title:        &title,
+		Description:  &body,
+		SourceBranch: &head.Branch,
+		TargetBranch: &base.Branch,
+	}
+	if head.String() != base.String() {
+		// merge requests are created in the source project, pointing to
+		// the target one
+		p, _, err := c.client.Projects.GetProject(base.String(), nil)
+		if err != nil {
+			return err
+		}
+		opts.TargetProjectID = &p.ID
+	}
+	mr, res, err := c.client.MergeRequests.CreateMergeRequest(head.String(), opts)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusConflict {
+			log.WithField("branch", head.Branch).Info("merge request already exists")
+			return nil
+		}
+		return err
+	}
+	log.WithField("url", mr.WebURL).Info("merge request opened")
+	return nil
+}
+
 // CreateRelease creates a new release or updates it by keeping
 // the release notes if it exists.
 func (c *gitlabClient) CreateRelease(ctx *context.Context, body string) (releaseID string, err error) {
@@ -535,3 +535,248 @@ func TestCloseMileston(t *testing.T) {
 	err = client.CloseMilestone(ctx, repo, "never-will-exist")
 	require.Error(t, err)
 }
+
+func TestGitlabCreateBranch(t *testing.T) {
+	var created string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/me/something/repository/branches/update"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/branches/main"):
+			fmt.Fprint(w, `{"name": "main", "commit": {"id": "abc123"}}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "projects/me/something/repository/branches"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			created = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"name": "update"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.CreateBranch(ctx, base, head))
+	require.JSONEq(t, `{"branch": "update", "ref": "abc123"}`, created)
+}
+
+func TestGitlabCreateBranchForkBehind(t *testing.T) {
+	var created []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/me/something/repository/branches/update"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/branches/main"):
+			fmt.Fprint(w, `{"name": "main", "commit": {"id": "abc123"}}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/me/something"):
+			fmt.Fprint(w, `{"default_branch": "trunk"}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "projects/me/something/repository/branches"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			created = append(created, string(bts))
+			if strings.Contains(string(bts), "abc123") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"message": "Invalid reference name: abc123"}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"name": "update"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.CreateBranch(ctx, base, head))
+	require.Len(t, created, 2)
+	require.JSONEq(t, `{"branch": "update", "ref": "abc123"}`, created[0])
+	require.JSONEq(t, `{"branch": "update", "ref": "trunk"}`, created[1])
+}
+
+func TestGitlabCreateBranchInvalidRef(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/branches/update"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "{}")
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/branches/main"):
+			fmt.Fprint(w, `{"name": "main", "commit": {"id": "abc123"}}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/branches"):
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"message": "Invalid reference name: abc123"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	// without a fork, there is nothing to fall back to.
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "someone", Name: "something", Branch: "update"}
+	require.Error(t, client.CreateBranch(ctx, base, head))
+}
+func TestGitlabOpenPullRequest(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "projects/someone/something"):
+			fmt.Fprint(w, `{"id": 42}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "projects/me/something/merge_requests"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"web_url": "https://gitlab.com/someone/something/-/merge_requests/1"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "me", Name: "something", Branch: "update"}
+	require.NoError(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", true))
+	require.JSONEq(t, `{"title": "Draft: the title", "description": "the body", "source_branch": "update", "target_branch": "main", "target_project_id": 42}`, body)
+}
+
+func TestGitlabOpenPullRequestExists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"message": ["Another open merge request already exists for this source branch: !1"]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	base := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	head := Repo{Owner: "someone", Name: "something", Branch: "update"}
+	require.NoError(t, client.OpenPullRequest(ctx, base, head, "the title", "the body", false))
+}
+
+func TestGitlabCreateFiles(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		switch {
+		case r.URL.Path == "/api/v4/":
+			// rate limit detection
+		case r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/files/existing.rb"):
+			w.Header().Set("X-Gitlab-File-Path", "existing.rb")
+		case r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/files/new.json"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "projects/someone/something/repository/commits"):
+			bts, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(bts)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "short_id": "6104942"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFiles(ctx, config.CommitAuthor{Name: "bot", Email: "bot@example.com"}, repo, []RepoFile{
+		{Path: "existing.rb", Content: []byte("formula")},
+		{Path: "new.json", Content: []byte("manifest")},
+	}, "update foo"))
+	require.JSONEq(t, `{
+		"branch": "main",
+		"commit_message": "update foo",
+		"author_name": "bot",
+		"author_email": "bot@example.com",
+		"actions": [
+			{"action": "update", "file_path": "existing.rb", "content": "formula"},
+			{"action": "create", "file_path": "new.json", "content": "manifest"}
+		]
+	}`, body)
+}
+
+func TestGitlabCreateFilesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "403 Forbidden"}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			API: srv.URL,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.Error(t, client.CreateFiles(ctx, config.CommitAuthor{}, repo, []RepoFile{
+		{Path: "existing.rb", Content: []byte("formula")},
+	}, "update foo"))
+}
+
+func TestGitlabCreateFileSigned(t *testing.T) {
+	dir := t.TempDir()
+	bare := newBareRepo(t, dir, "someone", "something")
+	ctx := context.New(config.Project{
+		GitLabURLs: config.GitLabURLs{
+			Download: "file://" + dir,
+		},
+	})
+	client, err := NewGitLab(ctx, "test-token")
+	require.NoError(t, err)
+
+	repo := Repo{Owner: "someone", Name: "something", Branch: "main"}
+	require.NoError(t, client.CreateFile(ctx, sshSigningAuthor(t), repo, []byte("formula"), "Formula/foo.rb", "update foo"))
+	require.Equal(t, "formula", gitCmd(t, "-C", bare, "show", "main:Formula/foo.rb"))
+	require.Contains(t, gitCmd(t, "-C", bare, "cat-file", "commit", "main"), "-----BEGIN SSH SIGNATURE-----")
+}
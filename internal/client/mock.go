@@ -25,6 +25,18 @@ type Mock struct {
 	CreatedFile          bool
 	Content              string
 	Path                 string
+	FileRepo             Repo
+	Files                []RepoFile
+	Commits              int
+	CommitMessage        string
+	CreatedBranch        Repo
+	OpenedPullRequest    bool
+	PullRequestBase      Repo
+	PullRequestHead      Repo
+	PullRequestTitle     string
+	PullRequestBody      string
+	PullRequestDraft     bool
+	FailToOpenPR         bool
 	FailToCreateRelease  bool
 	FailToUpload         bool
 	CreatedRelease       bool
@@ -80,9 +92,35 @@ func (c *Mock) ReleaseURLTemplate(ctx *context.Context) (string, error) {
 }
 
 func (c *Mock) CreateFile(ctx *context.Context, commitAuthor config.CommitAuthor, repo Repo, content []byte, path, msg string) error {
+	return c.CreateFiles(ctx, commitAuthor, repo, []RepoFile{{Path: path, Content: content}}, msg)
+}
+
+func (c *Mock) CreateFiles(ctx *context.Context, commitAuthor config.CommitAuthor, repo Repo, files []RepoFile, msg string) error {
 	c.CreatedFile = true
-	c.Content = string(content)
-	c.Path = path
+	c.Files = append(c.Files, files...)
+	c.Content = string(files[len(files)-1].Content)
+	c.Path = files[len(files)-1].Path
+	c.FileRepo = repo
+	c.Commits++
+	c.CommitMessage = msg
+	return nil
+}
+
+func (c *Mock) CreateBranch(ctx *context.Context, base, head Repo) error {
+	c.CreatedBranch = head
+	return nil
+}
+
+func (c *Mock) OpenPullRequest(ctx *context.Context, base, head Repo, title, body string, draft bool) error {
+	if c.FailToOpenPR {
+		return errors.New("pull request failed")
+	}
+	c.OpenedPullRequest = true
+	c.PullRequestBase = base
+	c.PullRequestHead = head
+	c.PullRequestTitle = title
+	c.PullRequestBody = body
+	c.PullRequestDraft = draft
 	return nil
 }
 
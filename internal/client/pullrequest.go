@@ -0,0 +1,128 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	defaultPullRequestBranch = "{{ .ProjectName }}-{{ .Version }}"
+	defaultPullRequestBody   = "Automated changes by [GoReleaser](https://github.com/goreleaser/goreleaser)."
+)
+
+// PublishFile creates or updates a file in the repository of the given ref.
+//
+// If the ref has pull requests enabled, the file is committed to a new branch,
+// in the fork if one is set, and a pull request is opened against the branch
+// of the ref. Otherwise, the file is committed to the branch directly.
+//
+// If the ref has a git url, the file is pushed to it with git instead of the
+// given client.
+//
+// While a batch is started, the file is only collected, and committed
+// alongside the other files of the same repository by CommitBatch.
+func PublishFile(
+	ctx *context.Context,
+	cli Client,
+	ref config.RepoRef,
+	commitAuthor config.CommitAuthor,
+	content []byte,
+	path,
+	message string,
+) error {
+	if b := batchFrom(ctx); b != nil {
+		b.add(cli, ref, commitAuthor, RepoFile{Path: path, Content: content}, message)
+		return nil
+	}
+	return publishFiles(ctx, cli, ref, commitAuthor, []RepoFile{{Path: path, Content: content}}, message)
+}
+
+func publishFiles(
+	ctx *context.Context,
+	cli Client,
+	ref config.RepoRef,
+	commitAuthor config.CommitAuthor,
+	files []RepoFile,
+	message string,
+) error {
+	base := RepoFromRef(ref)
+	pr := ref.PullRequest
+	if ref.Git.URL != "" {
+		if pr.Enabled {
+			return fmt.Errorf("can't open pull requests in git repository %s", ref.Git.URL)
+		}
+		cli = NewGit(ref.Git)
+	}
+	if !pr.Enabled {
+		return createFiles(ctx, cli, commitAuthor, base, files, message)
+	}
+
+	if pr.Branch == "" {
+		pr.Branch = defaultPullRequestBranch
+	}
+	if pr.Title == "" {
+		pr.Title = strings.SplitN(message, "\n", 2)[0]
+	}
+	if pr.Body == "" {
+		pr.Body = defaultPullRequestBody
+	}
+	t := tmpl.New(ctx)
+	for _, field := range []*string{&pr.Branch, &pr.Title, &pr.Body, &pr.Fork.Owner, &pr.Fork.Name} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return err
+		}
+	}
+
+	if base.Branch == "" {
+		branch, err := cli.GetDefaultBranch(ctx, base)
+		if err != nil {
+			log.WithField("repo", base.String()).Warn("error checking for default branch, using master")
+			branch = "master"
+		}
+		base.Branch = branch
+	}
+
+	head := Repo{
+		Owner:  base.Owner,
+		Name:   base.Name,
+		Branch: pr.Branch,
+	}
+	if pr.Fork.Owner != "" {
+		head.Owner = pr.Fork.Owner
+	}
+	if pr.Fork.Name != "" {
+		head.Name = pr.Fork.Name
+	}
+	if head.String() == base.String() && head.Branch == base.Branch {
+		return fmt.Errorf("pull request branch %s is the same as the base branch of %s", head.Branch, base)
+	}
+
+	log.WithField("repo", head.String()).
+		WithField("branch", head.Branch).
+		Debug("creating pull request branch")
+	if err := cli.CreateBranch(ctx, base, head); err != nil {
+		return fmt.Errorf("could not create branch %s in %s: %w", head.Branch, head, err)
+	}
+	if err := createFiles(ctx, cli, commitAuthor, head, files, message); err != nil {
+		return err
+	}
+	if err := cli.OpenPullRequest(ctx, base, head, pr.Title, pr.Body, pr.Draft); err != nil {
+		return fmt.Errorf("could not open pull request against %s: %w", base, err)
+	}
+	return nil
+}
+
+// createFiles commits the files, using the single file API if there is only
+// one of them.
+func createFiles(ctx *context.Context, cli Client, commitAuthor config.CommitAuthor, repo Repo, files []RepoFile, message string) error {
+	if len(files) == 1 {
+		return cli.CreateFile(ctx, commitAuthor, repo, files[0].Content, files[0].Path, message)
+	}
+	return cli.CreateFiles(ctx, commitAuthor, repo, files, message)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishFileDirectly(t *testing.T) {
+	ctx := context.New(config.Project{})
+	cli := NewMock()
+	ref := config.RepoRef{Owner: "foo", Name: "bar", Branch: "main"}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update"))
+	require.True(t, cli.CreatedFile)
+	require.Equal(t, Repo{Owner: "foo", Name: "bar", Branch: "main"}, cli.FileRepo)
+	require.Empty(t, cli.CreatedBranch)
+	require.False(t, cli.OpenedPullRequest)
+}
+
+func TestPublishFilePullRequest(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "proj"})
+	ctx.Version = "1.2.3"
+	cli := NewMock()
+	ref := config.RepoRef{
+		Owner:  "foo",
+		Name:   "bar",
+		Branch: "main",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+		},
+	}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update proj"))
+	head := Repo{Owner: "foo", Name: "bar", Branch: "proj-1.2.3"}
+	require.Equal(t, head, cli.CreatedBranch)
+	require.Equal(t, head, cli.FileRepo)
+	require.Equal(t, "hi", cli.Content)
+	require.True(t, cli.OpenedPullRequest)
+	require.Equal(t, Repo{Owner: "foo", Name: "bar", Branch: "main"}, cli.PullRequestBase)
+	require.Equal(t, head, cli.PullRequestHead)
+	require.Equal(t, "update proj", cli.PullRequestTitle)
+	require.Equal(t, defaultPullRequestBody, cli.PullRequestBody)
+	require.False(t, cli.PullRequestDraft)
+}
+
+func TestPublishFilePullRequestFork(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "proj"})
+	ctx.Version = "1.2.3"
+	ctx.Env = map[string]string{"FORK_OWNER": "me"}
+	cli := NewMock()
+	ref := config.RepoRef{
+		Owner: "foo",
+		Name:  "bar",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+			Branch:  "update-{{ .ProjectName }}",
+			Fork: config.PullRequestFork{
+				Owner: "{{ .Env.FORK_OWNER }}",
+			},
+			Title: "{{ .ProjectName }} {{ .Version }}",
+			Body:  "Updates {{ .ProjectName }}.",
+			Draft: true,
+		},
+	}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update"))
+	head := Repo{Owner: "me", Name: "bar", Branch: "update-proj"}
+	require.Equal(t, head, cli.CreatedBranch)
+	require.Equal(t, head, cli.FileRepo)
+	// the mock has no default branch
+	require.Equal(t, Repo{Owner: "foo", Name: "bar", Branch: "master"}, cli.PullRequestBase)
+	require.Equal(t, head, cli.PullRequestHead)
+	require.Equal(t, "proj 1.2.3", cli.PullRequestTitle)
+	require.Equal(t, "Updates proj.", cli.PullRequestBody)
+	require.True(t, cli.PullRequestDraft)
+}
+
+func TestPublishFilePullRequestSameBranch(t *testing.T) {
+	ctx := context.New(config.Project{})
+	cli := NewMock()
+	ref := config.RepoRef{
+		Owner:  "foo",
+		Name:   "bar",
+		Branch: "main",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+			Branch:  "main",
+		},
+	}
+
+	err := PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update")
+	require.EqualError(t, err, "pull request branch main is the same as the base branch of foo/bar")
+	require.False(t, cli.CreatedFile)
+}
+
+func TestPublishFilePullRequestInvalidTemplate(t *testing.T) {
+	ctx := context.New(config.Project{})
+	cli := NewMock()
+	ref := config.RepoRef{
+		Owner: "foo",
+		Name:  "bar",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+			Title:   "{{ .Nope }",
+		},
+	}
+
+	require.Error(t, PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update"))
+	require.False(t, cli.CreatedFile)
+}
+
+func TestPublishFilePullRequestFails(t *testing.T) {
+	ctx := context.New(config.Project{})
+	cli := NewMock()
+	cli.FailToOpenPR = true
+	ref := config.RepoRef{
+		Owner:  "foo",
+		Name:   "bar",
+		Branch: "main",
+		PullRequest: config.PullRequest{
+			Enabled: true,
+			Branch:  "update",
+		},
+	}
+
+	err := PublishFile(ctx, cli, ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update")
+	require.EqualError(t, err, "could not open pull request against foo/bar: pull request failed")
+	require.True(t, cli.CreatedFile)
+}
+
+func TestPublishFileGit(t *testing.T) {
+	bare := newBareRepo(t, t.TempDir(), "foo", "bar")
+	ctx := context.New(config.Project{})
+	cli := NewMock()
+	ref := config.RepoRef{
+		Git: config.GitRepoRef{
+			URL: "file://" + bare,
+		},
+	}
+
+	require.NoError(t, PublishFile(ctx, cli, ref, config.CommitAuthor{Name: "bot", Email: "bot@example.com"}, []byte("hi"), "file.txt", "update"))
+	require.False(t, cli.CreatedFile)
+	require.Equal(t, "hi", gitCmd(t, "-C", bare, "show", "main:file.txt"))
+}
+
+func TestPublishFileGitPullRequest(t *testing.T) {
+	ctx := context.New(config.Project{})
+	ref := config.RepoRef{
+		PullRequest: config.PullRequest{
+			Enabled: true,
+		},
+		Git: config.GitRepoRef{
+			URL: "git@example.com:foo/bar.git",
+		},
+	}
+
+	require.EqualError(t, PublishFile(ctx, NewMock(), ref, config.CommitAuthor{}, []byte("hi"), "file.txt", "update"), "can't open pull requests in git repository git@example.com:foo/bar.git")
+}
@@ -0,0 +1,471 @@
+// Package debugsym splits debug information out of compiled binaries, much
+// like `objcopy --only-keep-debug` followed by `strip --strip-debug` and
+// `objcopy --add-gnu-debuglink` would do.
+package debugsym
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported returns true if debug symbols can be split out of binaries
+// built for the given GOOS.
+//
+// Only ELF binaries are supported for now.
+func Supported(goos string) bool {
+	switch goos {
+	case "linux", "android", "freebsd", "netbsd", "openbsd", "dragonfly", "solaris", "illumos":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDebugSection returns true if the given section name holds debug
+// information that should be moved into the debug file.
+func IsDebugSection(name string) bool {
+	return strings.HasPrefix(name, ".debug_") ||
+		strings.HasPrefix(name, ".zdebug_") ||
+		name == ".symtab" ||
+		name == ".strtab"
+}
+
+// Split moves the debug information of the ELF binary at path into a new file
+// at debugPath, strips it from the binary and adds a .gnu_debuglink section
+// to the binary pointing to the debug file.
+func Split(path, debugPath string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read binary: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read binary: %w", err)
+	}
+
+	file, err := parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	debug, err := file.debugFile()
+	if err != nil {
+		return fmt.Errorf("%s: failed to extract debug symbols: %w", path, err)
+	}
+	if err := os.WriteFile(debugPath, debug, 0o644); err != nil {
+		return fmt.Errorf("failed to write debug symbols: %w", err)
+	}
+
+	stripped, err := file.strippedFile(filepath.Base(debugPath), crc32.ChecksumIEEE(debug))
+	if err != nil {
+		return fmt.Errorf("%s: failed to strip debug symbols: %w", path, err)
+	}
+	if err := os.WriteFile(path, stripped, info.Mode()); err != nil {
+		return fmt.Errorf("failed to write stripped binary: %w", err)
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// section is a class-independent ELF section header.
+type section struct {
+	name string
+	hdr  elf.Section64
+}
+
+type file struct {
+	data     []byte
+	class    elf.Class
+	order    binary.ByteOrder
+	header   elf.Header64
+	sections []section
+}
+
+func parse(data []byte) (*file, error) {
+	ef, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("not an ELF binary: %w", err)
+	}
+	defer ef.Close()
+
+	f := &file{
+		data:  data,
+		class: ef.Class,
+		order: ef.ByteOrder,
+	}
+
+	r := bytes.NewReader(data)
+	switch f.class {
+	case elf.ELFCLASS64:
+		if err := binary.Read(r, f.order, &f.header); err != nil {
+			return nil, err
+		}
+	case elf.ELFCLASS32:
+		var h32 elf.Header32
+		if err := binary.Read(r, f.order, &h32); err != nil {
+			return nil, err
+		}
+		f.header = elf.Header64{
+			Ident:     h32.Ident,
+			Type:      h32.Type,
+			Machine:   h32.Machine,
+			Version:   h32.Version,
+			Entry:     uint64(h32.Entry),
+			Phoff:     uint64(h32.Phoff),
+			Shoff:     uint64(h32.Shoff),
+			Flags:     h32.Flags,
+			Ehsize:    h32.Ehsize,
+			Phentsize: h32.Phentsize,
+			Phnum:     h32.Phnum,
+			Shentsize: h32.Shentsize,
+			Shnum:     h32.Shnum,
+			Shstrndx:  h32.Shstrndx,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ELF class: %s", f.class)
+	}
+
+	if int(f.header.Shnum) != len(ef.Sections) {
+		return nil, fmt.Errorf("unsupported number of sections: %d", len(ef.Sections))
+	}
+
+	for i, s := range ef.Sections {
+		off := int64(f.header.Shoff) + int64(i)*int64(f.header.Shentsize)
+		hdr, err := f.readSectionHeader(off)
+		if err != nil {
+			return nil, err
+		}
+		f.sections = append(f.sections, section{name: s.Name, hdr: hdr})
+	}
+	return f, nil
+}
+
+func (f *file) readSectionHeader(off int64) (elf.Section64, error) {
+	r := bytes.NewReader(f.data)
+	if _, err := r.Seek(off, 0); err != nil {
+		return elf.Section64{}, err
+	}
+	if f.class == elf.ELFCLASS64 {
+		var s elf.Section64
+		err := binary.Read(r, f.order, &s)
+		return s, err
+	}
+	var s elf.Section32
+	if err := binary.Read(r, f.order, &s); err != nil {
+		return elf.Section64{}, err
+	}
+	return elf.Section64{
+		Name:      s.Name,
+		Type:      s.Type,
+		Flags:     uint64(s.Flags),
+		Addr:      uint64(s.Addr),
+		Off:       uint64(s.Off),
+		Size:      uint64(s.Size),
+		Link:      s.Link,
+		Info:      s.Info,
+		Addralign: uint64(s.Addralign),
+		Entsize:   uint64(s.Entsize),
+	}, nil
+}
+
+// contents returns the raw contents of the given section as stored in the
+// file.
+func (f *file) contents(s section) ([]byte, error) {
+	if elf.SectionType(s.hdr.Type) == elf.SHT_NOBITS || s.hdr.Size == 0 {
+		return nil, nil
+	}
+	end := s.hdr.Off + s.hdr.Size
+	if end > uint64(len(f.data)) || end < s.hdr.Off {
+		return nil, fmt.Errorf("section %s is out of bounds", s.name)
+	}
+	return f.data[s.hdr.Off:end], nil
+}
+
+// debugFile builds an ELF file that only contains the debug sections, in the
+// same fashion `objcopy --only-keep-debug` does: every section header is
+// kept so section indexes on the symbol table still match, but all the
+// non-debug and non-note sections are turned into SHT_NOBITS.
+func (f *file) debugFile() ([]byte, error) {
+	var body bytes.Buffer
+	var headers []elf.Section64
+	offset := uint64(f.headerSize())
+	for i, s := range f.sections {
+		hdr := s.hdr
+		if i == 0 {
+			headers = append(headers, hdr)
+			continue
+		}
+		// notes are kept as well, so the build ids can be matched.
+		keep := IsDebugSection(s.name) ||
+			elf.SectionType(hdr.Type) == elf.SHT_NOTE ||
+			i == int(f.header.Shstrndx)
+		if !keep {
+			if elf.SectionType(hdr.Type) != elf.SHT_NOBITS {
+				hdr.Type = uint32(elf.SHT_NOBITS)
+			}
+			hdr.Off = offset
+			headers = append(headers, hdr)
+			continue
+		}
+		data, err := f.contents(s)
+		if err != nil {
+			return nil, err
+		}
+		offset = align(offset, hdr.Addralign, &body)
+		hdr.Off = offset
+		body.Write(data)
+		offset += uint64(len(data))
+		headers = append(headers, hdr)
+	}
+
+	header := f.header
+	header.Phoff = 0
+	header.Phnum = 0
+	return f.assemble(header, body.Bytes(), offset, headers)
+}
+
+// strippedFile builds the given ELF file without the debug sections, and
+// with an extra .gnu_debuglink section pointing to the given debug file.
+//
+// Sections which are part of the loadable segments are not moved around, so
+// the program headers remain valid.
+func (f *file) strippedFile(debugLink string, crc uint32) ([]byte, error) {
+	// the end of whatever must be kept in place: the headers, the segments
+	// and the sections which are not being removed.
+	end := uint64(f.headerSize())
+	if phEnd := f.header.Phoff + uint64(f.header.Phnum)*uint64(f.header.Phentsize); phEnd > end {
+		end = phEnd
+	}
+	for _, s := range f.sections {
+		if IsDebugSection(s.name) || elf.SectionFlag(s.hdr.Flags)&elf.SHF_ALLOC == 0 {
+			continue
+		}
+		if elf.SectionType(s.hdr.Type) == elf.SHT_NOBITS {
+			continue
+		}
+		if e := s.hdr.Off + s.hdr.Size; e > end {
+			end = e
+		}
+	}
+	for _, p := range f.programs() {
+		if e := p.off + p.filesz; e > end {
+			end = e
+		}
+	}
+	if end > uint64(len(f.data)) {
+		return nil, fmt.Errorf("segments are out of bounds")
+	}
+
+	// maps old section indexes to new ones.
+	indexes := map[uint32]uint32{}
+	var kept []int
+	for i, s := range f.sections {
+		if i != 0 && (IsDebugSection(s.name) ||
+			s.name == ".gnu_debuglink" ||
+			i == int(f.header.Shstrndx)) {
+			continue
+		}
+		indexes[uint32(i)] = uint32(len(kept))
+		kept = append(kept, i)
+	}
+
+	var body bytes.Buffer
+	body.Write(f.data[:end])
+	offset := end
+
+	var names bytes.Buffer
+	names.WriteByte(0)
+	addName := func(name string) uint32 {
+		if name == "" {
+			return 0
+		}
+		idx := uint32(names.Len())
+		names.WriteString(name)
+		names.WriteByte(0)
+		return idx
+	}
+
+	var headers []elf.Section64
+	for _, i := range kept {
+		s := f.sections[i]
+		hdr := s.hdr
+		hdr.Name = addName(s.name)
+		if i != 0 {
+			hdr.Link = indexes[hdr.Link]
+			if elf.SectionFlag(hdr.Flags)&elf.SHF_INFO_LINK != 0 {
+				hdr.Info = indexes[hdr.Info]
+			}
+		}
+		if i != 0 && elf.SectionType(hdr.Type) != elf.SHT_NOBITS && hdr.Off+hdr.Size > end {
+			// non-allocated section that lived after the segments, e.g.
+			// .comment: move it after the kept data.
+			data, err := f.contents(s)
+			if err != nil {
+				return nil, err
+			}
+			offset = align(offset, hdr.Addralign, &body)
+			hdr.Off = offset
+			body.Write(data)
+			offset += uint64(len(data))
+		}
+		headers = append(headers, hdr)
+	}
+
+	var link bytes.Buffer
+	link.WriteString(debugLink)
+	link.WriteByte(0)
+	for link.Len()%4 != 0 {
+		link.WriteByte(0)
+	}
+	crcBytes := make([]byte, 4)
+	f.order.PutUint32(crcBytes, crc)
+	link.Write(crcBytes)
+
+	offset = align(offset, 4, &body)
+	headers = append(headers, elf.Section64{
+		Name:      addName(".gnu_debuglink"),
+		Type:      uint32(elf.SHT_PROGBITS),
+		Off:       offset,
+		Size:      uint64(link.Len()),
+		Addralign: 4,
+	})
+	body.Write(link.Bytes())
+	offset += uint64(link.Len())
+
+	shstrndx := len(headers)
+	shstrtabName := addName(".shstrtab")
+	headers = append(headers, elf.Section64{
+		Name:      shstrtabName,
+		Type:      uint32(elf.SHT_STRTAB),
+		Off:       offset,
+		Size:      uint64(names.Len()),
+		Addralign: 1,
+	})
+	body.Write(names.Bytes())
+	offset += uint64(names.Len())
+
+	header := f.header
+	header.Shstrndx = uint16(shstrndx)
+	return f.assemble(header, body.Bytes()[f.headerSize():], offset, headers)
+}
+
+type program struct {
+	off    uint64
+	filesz uint64
+}
+
+func (f *file) programs() []program {
+	var result []program
+	for i := 0; i < int(f.header.Phnum); i++ {
+		off := f.header.Phoff + uint64(i)*uint64(f.header.Phentsize)
+		if f.class == elf.ELFCLASS64 {
+			var p elf.Prog64
+			if err := binary.Read(bytes.NewReader(f.data[off:]), f.order, &p); err != nil {
+				continue
+			}
+			result = append(result, program{off: p.Off, filesz: p.Filesz})
+			continue
+		}
+		var p elf.Prog32
+		if err := binary.Read(bytes.NewReader(f.data[off:]), f.order, &p); err != nil {
+			continue
+		}
+		result = append(result, program{off: uint64(p.Off), filesz: uint64(p.Filesz)})
+	}
+	return result
+}
+
+func (f *file) headerSize() int {
+	if f.class == elf.ELFCLASS64 {
+		return binary.Size(elf.Header64{})
+	}
+	return binary.Size(elf.Header32{})
+}
+
+func (f *file) sectionHeaderSize() int {
+	if f.class == elf.ELFCLASS64 {
+		return binary.Size(elf.Section64{})
+	}
+	return binary.Size(elf.Section32{})
+}
+
+// assemble writes the ELF header, the given body (which must start right
+// after the ELF header) and the section header table.
+func (f *file) assemble(header elf.Header64, body []byte, offset uint64, sections []elf.Section64) ([]byte, error) {
+	var out bytes.Buffer
+	tail := bytes.NewBuffer(body)
+	offset = align(offset, 8, tail)
+
+	header.Shoff = offset
+	header.Shnum = uint16(len(sections))
+	header.Shentsize = uint16(f.sectionHeaderSize())
+
+	if err := f.writeHeader(&out, header); err != nil {
+		return nil, err
+	}
+	out.Write(tail.Bytes())
+	for _, s := range sections {
+		if err := f.writeSectionHeader(&out, s); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func (f *file) writeHeader(w *bytes.Buffer, h elf.Header64) error {
+	if f.class == elf.ELFCLASS64 {
+		return binary.Write(w, f.order, h)
+	}
+	return binary.Write(w, f.order, elf.Header32{
+		Ident:     h.Ident,
+		Type:      h.Type,
+		Machine:   h.Machine,
+		Version:   h.Version,
+		Entry:     uint32(h.Entry),
+		Phoff:     uint32(h.Phoff),
+		Shoff:     uint32(h.Shoff),
+		Flags:     h.Flags,
+		Ehsize:    h.Ehsize,
+		Phentsize: h.Phentsize,
+		Phnum:     h.Phnum,
+		Shentsize: h.Shentsize,
+		Shnum:     h.Shnum,
+		Shstrndx:  h.Shstrndx,
+	})
+}
+
+func (f *file) writeSectionHeader(w *bytes.Buffer, s elf.Section64) error {
+	if f.class == elf.ELFCLASS64 {
+		return binary.Write(w, f.order, s)
+	}
+	return binary.Write(w, f.order, elf.Section32{
+		Name:      s.Name,
+		Type:      s.Type,
+		Flags:     uint32(s.Flags),
+		Addr:      uint32(s.Addr),
+		Off:       uint32(s.Off),
+		Size:      uint32(s.Size),
+		Link:      s.Link,
+		Info:      s.Info,
+		Addralign: uint32(s.Addralign),
+		Entsize:   uint32(s.Entsize),
+	})
+}
+
+// align pads buf with zeroes until offset is aligned to the given alignment,
+// returning the new offset.
+func align(offset, alignment uint64, buf *bytes.Buffer) uint64 {
+	if alignment <= 1 {
+		return offset
+	}
+	for offset%alignment != 0 {
+		buf.WriteByte(0)
+		offset++
+	}
+	return offset
+}
@@ -0,0 +1,99 @@
+package debugsym
+
+import (
+	"debug/elf"
+	"hash/crc32"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupported(t *testing.T) {
+	require.True(t, Supported("linux"))
+	require.True(t, Supported("freebsd"))
+	require.False(t, Supported("darwin"))
+	require.False(t, Supported("windows"))
+	require.False(t, Supported("js"))
+}
+
+func TestSplit(t *testing.T) {
+	for _, goarch := range []string{"amd64", "386", "arm64"} {
+		goarch := goarch
+		t.Run(goarch, func(t *testing.T) {
+			bin := filepath.Join(t.TempDir(), "app")
+			testlib.BuildBinary(t, "linux", goarch, bin, nil)
+			debug := bin + ".debug"
+			require.NoError(t, Split(bin, debug))
+
+			stripped, err := elf.Open(bin)
+			require.NoError(t, err)
+			defer stripped.Close()
+			for _, s := range stripped.Sections {
+				require.False(t, IsDebugSection(s.Name), s.Name)
+			}
+			_, err = stripped.Symbols()
+			require.ErrorIs(t, err, elf.ErrNoSymbols)
+
+			link := stripped.Section(".gnu_debuglink")
+			require.NotNil(t, link)
+			data, err := link.Data()
+			require.NoError(t, err)
+			require.Equal(t, "app.debug", strings.TrimRight(string(data[:len(data)-4]), "\x00"))
+			debugData, err := os.ReadFile(debug)
+			require.NoError(t, err)
+			require.Equal(t, crc32.ChecksumIEEE(debugData), stripped.ByteOrder.Uint32(data[len(data)-4:]))
+
+			// the debug file should still have the same section layout, with
+			// the symbols and the DWARF data.
+			df, err := elf.Open(debug)
+			require.NoError(t, err)
+			defer df.Close()
+			require.Len(t, df.Sections, len(stripped.Sections)+len(debugSections(t, df))-1)
+			syms, err := df.Symbols()
+			require.NoError(t, err)
+			var found bool
+			for _, sym := range syms {
+				if sym.Name == "main.main" {
+					found = true
+				}
+			}
+			require.True(t, found, "main.main symbol not found")
+			_, err = df.DWARF()
+			require.NoError(t, err)
+			require.Equal(t, elf.SHT_NOBITS, df.Section(".text").Type)
+
+			if runtime.GOOS == "linux" && runtime.GOARCH == goarch {
+				out, err := exec.Command(bin).CombinedOutput()
+				require.NoError(t, err)
+				require.Equal(t, "hello\n", string(out))
+			}
+		})
+	}
+}
+
+func TestSplitNotELF(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "app")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\necho hello"), 0o755))
+	require.Error(t, Split(bin, bin+".debug"))
+}
+
+func TestSplitMissingFile(t *testing.T) {
+	require.Error(t, Split(filepath.Join(t.TempDir(), "nope"), "nope.debug"))
+}
+
+func debugSections(tb testing.TB, f *elf.File) []string {
+	tb.Helper()
+	var result []string
+	for _, s := range f.Sections {
+		if IsDebugSection(s.Name) {
+			result = append(result, s.Name)
+		}
+	}
+	return result
+}
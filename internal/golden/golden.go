@@ -39,6 +39,11 @@ func RequireEqualLua(tb testing.TB, out []byte) {
 	doRequireEqual(tb, out, ".lua", golden)
 }
 
+func RequireEqualNix(tb testing.TB, out []byte) {
+	tb.Helper()
+	doRequireEqual(tb, out, ".nix", golden)
+}
+
 func RequireEqualYaml(tb testing.TB, out []byte) {
 	tb.Helper()
 	doRequireEqual(tb, out, ".yml", golden)
@@ -160,6 +160,7 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 			filters = append(filters,
 				artifact.ByType(artifact.UploadableArchive),
 				artifact.ByType(artifact.LinuxPackage),
+				artifact.ByType(artifact.LinuxRepository),
 			)
 		case ModeBinary:
 			filters = append(filters, artifact.ByType(artifact.UploadableBinary))
@@ -185,7 +186,14 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 }
 
 func uploadWithFilter(ctx *context.Context, upload *config.Upload, filter artifact.Filter, kind string, check ResponseChecker) error {
-	artifacts := ctx.Artifacts.Filter(filter).List()
+	var artifacts []*artifact.Artifact
+	for _, a := range ctx.Artifacts.Filter(filter).List() {
+		files, err := a.Files()
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, files...)
+	}
 	log.Debugf("will upload %d artifacts", len(artifacts))
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, artifact := range artifacts {
@@ -0,0 +1,141 @@
+package linuxpkg
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/blakesmith/ar"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// ErrNoControl happens when a deb package has no control file.
+var ErrNoControl = errors.New("control file not found")
+
+// Deb is the metadata and file list of a deb package.
+type Deb struct {
+	Control   *Stanza
+	Conffiles []string
+	Files     []File
+}
+
+// ReadDeb reads a deb package.
+func ReadDeb(file string) (*Deb, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	deb := &Deb{}
+	reader := ar.NewReader(f)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(header.Name, "/")
+		switch {
+		case strings.HasPrefix(name, "control.tar"):
+			r, err := decompress(reader, strings.TrimPrefix(name, "control.tar"))
+			if err != nil {
+				return nil, err
+			}
+			if err := readDebControl(tar.NewReader(r), deb); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(name, "data.tar"):
+			r, err := decompress(reader, strings.TrimPrefix(name, "data.tar"))
+			if err != nil {
+				return nil, err
+			}
+			if deb.Files, err = readDebData(tar.NewReader(r)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if deb.Control == nil {
+		return nil, ErrNoControl
+	}
+	return deb, nil
+}
+
+func readDebControl(tr *tar.Reader, deb *Deb) error {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch path.Clean(header.Name) {
+		case "control":
+			stanzas, err := ParseStanzas(tr)
+			if err != nil {
+				return err
+			}
+			if len(stanzas) != 1 || stanzas[0].Get("Package") == "" {
+				return ErrNoControl
+			}
+			deb.Control = stanzas[0]
+		case "conffiles":
+			bts, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			for _, line := range strings.Split(string(bts), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					deb.Conffiles = append(deb.Conffiles, line)
+				}
+			}
+		}
+	}
+}
+
+func readDebData(tr *tar.Reader) ([]File, error) {
+	var files []File
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean("/" + header.Name)
+		if name == "/" {
+			continue
+		}
+		files = append(files, File{
+			Path: name,
+			Mode: header.FileInfo().Mode(),
+		})
+	}
+}
+
+func decompress(r io.Reader, ext string) (io.Reader, error) {
+	switch ext {
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".xz":
+		return xz.NewReader(r)
+	case ".zst":
+		return zstd.NewReader(r)
+	case ".bz2":
+		return bzip2.NewReader(r), nil
+	case "":
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive compression: %s", ext)
+	}
+}
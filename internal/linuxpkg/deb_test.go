@@ -0,0 +1,36 @@
+package linuxpkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDeb(t *testing.T) {
+	deb, err := ReadDeb(createPackage(t, "deb"))
+	require.NoError(t, err)
+	require.Equal(t, "foo", deb.Control.Get("Package"))
+	require.Equal(t, "1.0.0", deb.Control.Get("Version"))
+	require.Equal(t, "Foo <foo@bar.com>", deb.Control.Get("Maintainer"))
+	require.Equal(t, []string{"/etc/foo.conf"}, deb.Conffiles)
+
+	require.Equal(t, os.FileMode(0o755), file(t, deb.Files, "/usr/bin/foo").Mode)
+	require.Equal(t, os.FileMode(0o666), file(t, deb.Files, "/etc/foo.conf").Mode)
+	require.True(t, file(t, deb.Files, "/var/lib/foo").Mode.IsDir())
+	require.True(t, file(t, deb.Files, "/usr/bin").Mode.IsDir())
+}
+
+func TestReadDebErrors(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		_, err := ReadDeb(filepath.Join(t.TempDir(), "nope.deb"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("no control", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "foo.deb")
+		require.NoError(t, os.WriteFile(path, []byte("!<arch>\n"), 0o644))
+		_, err := ReadDeb(path)
+		require.ErrorIs(t, err, ErrNoControl)
+	})
+}
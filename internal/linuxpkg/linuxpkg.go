@@ -0,0 +1,11 @@
+// Package linuxpkg reads the metadata and file lists of deb and rpm packages.
+package linuxpkg
+
+import "os"
+
+// File is a file inside a package.
+type File struct {
+	Path   string
+	Mode   os.FileMode
+	Config bool // only set for rpm packages, debs list them in Deb.Conffiles
+}
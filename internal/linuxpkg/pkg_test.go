@@ -0,0 +1,60 @@
+package linuxpkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/nfpm/v2"
+	_ "github.com/goreleaser/nfpm/v2/deb" // register the deb packager
+	"github.com/goreleaser/nfpm/v2/files"
+	_ "github.com/goreleaser/nfpm/v2/rpm" // register the rpm packager
+	"github.com/stretchr/testify/require"
+)
+
+// createPackage creates a package with nfpm, returning its path.
+func createPackage(tb testing.TB, format string) string {
+	tb.Helper()
+	folder := tb.TempDir()
+	binary := filepath.Join(folder, "foo")
+	require.NoError(tb, os.WriteFile(binary, []byte("#!/bin/sh"), 0o755))
+	mtime := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	info := nfpm.WithDefaults(&nfpm.Info{
+		Name:        "foo",
+		Arch:        "amd64",
+		Platform:    "linux",
+		Version:     "1.0.0",
+		Maintainer:  "Foo <foo@bar.com>",
+		Description: "foo does things",
+		License:     "MIT",
+		Overridables: nfpm.Overridables{
+			Depends: []string{"bash"},
+			Contents: files.Contents{
+				{Source: binary, Destination: "/usr/bin/foo", FileInfo: &files.ContentFileInfo{MTime: mtime, Mode: 0o755}},
+				{Source: binary, Destination: "/etc/foo.conf", Type: "config", FileInfo: &files.ContentFileInfo{MTime: mtime, Mode: 0o666}},
+				{Destination: "/var/lib/foo", Type: "dir", FileInfo: &files.ContentFileInfo{MTime: mtime}},
+			},
+		},
+	})
+	packager, err := nfpm.Get(format)
+	require.NoError(tb, err)
+	path := filepath.Join(folder, packager.ConventionalFileName(info))
+	f, err := os.Create(path)
+	require.NoError(tb, err)
+	require.NoError(tb, packager.Package(info, f))
+	require.NoError(tb, f.Close())
+	return path
+}
+
+// file returns the file at the given path, failing if it doesn't exist.
+func file(tb testing.TB, files []File, path string) File {
+	tb.Helper()
+	for _, f := range files {
+		if f.Path == path {
+			return f
+		}
+	}
+	require.FailNow(tb, path+" not found")
+	return File{}
+}
@@ -0,0 +1,303 @@
+package linuxpkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// rpm header tags, see https://rpm-software-management.github.io/rpm/manual/tags.html
+const (
+	TagName            = 1000
+	TagVersion         = 1001
+	TagRelease         = 1002
+	TagEpoch           = 1003
+	TagSummary         = 1004
+	TagDescription     = 1005
+	TagBuildTime       = 1006
+	TagBuildHost       = 1007
+	TagSize            = 1009
+	TagVendor          = 1011
+	TagLicense         = 1014
+	TagPackager        = 1015
+	TagGroup           = 1016
+	TagURL             = 1020
+	TagArch            = 1022
+	TagOldFilenames    = 1027
+	TagFileModes       = 1030
+	TagFileFlags       = 1037
+	TagSourceRPM       = 1044
+	TagArchiveSize     = 1046
+	TagProvideName     = 1047
+	TagRequireFlags    = 1048
+	TagRequireName     = 1049
+	TagRequireVersion  = 1050
+	TagConflictFlags   = 1053
+	TagConflictName    = 1054
+	TagConflictVersion = 1055
+	TagObsoleteName    = 1090
+	TagProvideFlags    = 1112
+	TagProvideVersion  = 1113
+	TagObsoleteFlags   = 1114
+	TagObsoleteVersion = 1115
+	TagDirIndexes      = 1116
+	TagBaseNames       = 1117
+	TagDirNames        = 1118
+
+	SigTagPayloadSize = 1007
+)
+
+// rpm header entry types.
+const (
+	typeInt16       = 3
+	typeInt32       = 4
+	typeString      = 6
+	typeStringArray = 8
+	typeI18NString  = 9
+)
+
+// rpm dependency flags.
+const (
+	senseLess    = 1 << 1
+	senseGreater = 1 << 2
+	senseEqual   = 1 << 3
+	senseRPMLib  = 1 << 24
+)
+
+// fileFlagConfig is the file flag of %config files.
+const fileFlagConfig = 1 << 0
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	RPMHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+var ErrInvalidRPM = errors.New("not a valid rpm package")
+
+// RPMHeader is a parsed rpm header.
+type RPMHeader struct {
+	strings map[int][]string
+	ints    map[int][]int64
+
+	Start, End int64 // byte range of the header inside the package
+}
+
+// GetString returns the first string value of a tag.
+func (h RPMHeader) GetString(tag int) string {
+	if v := h.strings[tag]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// GetInt returns the first integer value of a tag.
+func (h RPMHeader) GetInt(tag int) int64 {
+	if v := h.ints[tag]; len(v) > 0 {
+		return v[0]
+	}
+	return 0
+}
+
+// RPMDependency is a requires, provides, conflicts or obsoletes entry.
+type RPMDependency struct {
+	Name    string
+	Flags   string
+	Epoch   string
+	Version string
+	Release string
+}
+
+// Dependencies returns the entries of the given dependency kind, except for
+// the rpmlib() ones.
+func (h RPMHeader) Dependencies(nameTag, flagsTag, versionTag int) []RPMDependency {
+	names := h.strings[nameTag]
+	flags := h.ints[flagsTag]
+	versions := h.strings[versionTag]
+	var result []RPMDependency
+	for i, name := range names {
+		dep := RPMDependency{Name: name}
+		var flag int64
+		if i < len(flags) {
+			flag = flags[i]
+		}
+		if flag&senseRPMLib != 0 {
+			continue
+		}
+		switch flag & (senseLess | senseGreater | senseEqual) {
+		case senseEqual:
+			dep.Flags = "EQ"
+		case senseLess:
+			dep.Flags = "LT"
+		case senseGreater:
+			dep.Flags = "GT"
+		case senseLess | senseEqual:
+			dep.Flags = "LE"
+		case senseGreater | senseEqual:
+			dep.Flags = "GE"
+		}
+		if i < len(versions) && versions[i] != "" && dep.Flags != "" {
+			dep.Epoch, dep.Version, dep.Release = splitEVR(versions[i])
+		}
+		result = append(result, dep)
+	}
+	return result
+}
+
+// splitEVR splits an [epoch:]version[-release] string.
+func splitEVR(evr string) (string, string, string) {
+	epoch := "0"
+	if i := strings.IndexByte(evr, ':'); i >= 0 {
+		epoch, evr = evr[:i], evr[i+1:]
+	}
+	release := ""
+	if i := strings.LastIndexByte(evr, '-'); i >= 0 {
+		evr, release = evr[:i], evr[i+1:]
+	}
+	return epoch, evr, release
+}
+
+// Files lists the files of the package.
+func (h RPMHeader) Files() []File {
+	names := h.strings[TagOldFilenames]
+	if len(names) == 0 {
+		dirs := h.strings[TagDirNames]
+		indexes := h.ints[TagDirIndexes]
+		for i, base := range h.strings[TagBaseNames] {
+			if i < len(indexes) && int(indexes[i]) < len(dirs) {
+				names = append(names, path.Join(dirs[indexes[i]], base))
+			}
+		}
+	}
+	modes := h.ints[TagFileModes]
+	flags := h.ints[TagFileFlags]
+	result := make([]File, 0, len(names))
+	for i, name := range names {
+		f := File{Path: name}
+		if i < len(modes) {
+			f.Mode = unixMode(modes[i])
+		}
+		if i < len(flags) {
+			f.Config = flags[i]&fileFlagConfig != 0
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// unixMode converts the st_mode of a file to an os.FileMode.
+func unixMode(mode int64) os.FileMode {
+	result := os.FileMode(mode & 0o777)
+	switch mode & 0o170000 {
+	case 0o040000:
+		result |= os.ModeDir
+	case 0o120000:
+		result |= os.ModeSymlink
+	}
+	if mode&0o4000 != 0 {
+		result |= os.ModeSetuid
+	}
+	if mode&0o2000 != 0 {
+		result |= os.ModeSetgid
+	}
+	if mode&0o1000 != 0 {
+		result |= os.ModeSticky
+	}
+	return result
+}
+
+// ReadRPM reads the signature and main headers of an rpm package.
+func ReadRPM(r io.ReadSeeker) (sig RPMHeader, hdr RPMHeader, err error) {
+	lead := make([]byte, 96)
+	if _, err := io.ReadFull(r, lead); err != nil {
+		return sig, hdr, fmt.Errorf("%w: %v", ErrInvalidRPM, err)
+	}
+	if !bytes.Equal(lead[:4], rpmLeadMagic) {
+		return sig, hdr, ErrInvalidRPM
+	}
+	sig, err = readHeader(r, 96)
+	if err != nil {
+		return sig, hdr, err
+	}
+	// the signature header is padded to a multiple of 8 bytes
+	start := sig.End + (8-sig.End%8)%8
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return sig, hdr, err
+	}
+	hdr, err = readHeader(r, start)
+	return sig, hdr, err
+}
+
+func readHeader(r io.Reader, start int64) (RPMHeader, error) {
+	h := RPMHeader{
+		strings: map[int][]string{},
+		ints:    map[int][]int64{},
+		Start:   start,
+	}
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return h, fmt.Errorf("%w: %v", ErrInvalidRPM, err)
+	}
+	if !bytes.Equal(intro[:4], RPMHeaderMagic) {
+		return h, ErrInvalidRPM
+	}
+	count := int64(binary.BigEndian.Uint32(intro[8:12]))
+	size := int64(binary.BigEndian.Uint32(intro[12:16]))
+	if count > 1<<16 || size > 1<<28 {
+		return h, ErrInvalidRPM
+	}
+	index := make([]byte, count*16)
+	if _, err := io.ReadFull(r, index); err != nil {
+		return h, fmt.Errorf("%w: %v", ErrInvalidRPM, err)
+	}
+	store := make([]byte, size)
+	if _, err := io.ReadFull(r, store); err != nil {
+		return h, fmt.Errorf("%w: %v", ErrInvalidRPM, err)
+	}
+	h.End = start + 16 + count*16 + size
+
+	for i := int64(0); i < count; i++ {
+		entry := index[i*16 : i*16+16]
+		tag := int(binary.BigEndian.Uint32(entry[0:4]))
+		typ := binary.BigEndian.Uint32(entry[4:8])
+		offset := int64(binary.BigEndian.Uint32(entry[8:12]))
+		n := int64(binary.BigEndian.Uint32(entry[12:16]))
+		if offset > size {
+			return h, ErrInvalidRPM
+		}
+		data := store[offset:]
+		switch typ {
+		case typeInt16:
+			if int64(len(data)) < n*2 {
+				return h, ErrInvalidRPM
+			}
+			for j := int64(0); j < n; j++ {
+				h.ints[tag] = append(h.ints[tag], int64(binary.BigEndian.Uint16(data[j*2:])))
+			}
+		case typeInt32:
+			if int64(len(data)) < n*4 {
+				return h, ErrInvalidRPM
+			}
+			for j := int64(0); j < n; j++ {
+				h.ints[tag] = append(h.ints[tag], int64(binary.BigEndian.Uint32(data[j*4:])))
+			}
+		case typeString, typeStringArray, typeI18NString:
+			if typ == typeString {
+				n = 1
+			}
+			for j := int64(0); j < n; j++ {
+				end := bytes.IndexByte(data, 0)
+				if end < 0 {
+					return h, ErrInvalidRPM
+				}
+				h.strings[tag] = append(h.strings[tag], string(data[:end]))
+				data = data[end+1:]
+			}
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,70 @@
+package linuxpkg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRPM(t *testing.T) {
+	f, err := os.Open(createPackage(t, "rpm"))
+	require.NoError(t, err)
+	defer f.Close()
+	sig, hdr, err := ReadRPM(f)
+	require.NoError(t, err)
+	require.NotZero(t, sig.GetInt(SigTagPayloadSize))
+	require.Equal(t, int64(96), sig.Start)
+	require.Greater(t, hdr.End, hdr.Start)
+	require.Equal(t, "foo", hdr.GetString(TagName))
+	require.Equal(t, "1.0.0", hdr.GetString(TagVersion))
+	require.Equal(t, "MIT", hdr.GetString(TagLicense))
+	require.Equal(t, "Foo <foo@bar.com>", hdr.GetString(TagPackager))
+	require.Equal(t, []RPMDependency{{Name: "bash"}}, hdr.Dependencies(TagRequireName, TagRequireFlags, TagRequireVersion))
+
+	files := hdr.Files()
+	require.Equal(t, os.FileMode(0o755), file(t, files, "/usr/bin/foo").Mode)
+	require.False(t, file(t, files, "/usr/bin/foo").Config)
+	require.Equal(t, os.FileMode(0o666), file(t, files, "/etc/foo.conf").Mode)
+	require.True(t, file(t, files, "/etc/foo.conf").Config)
+	require.True(t, file(t, files, "/var/lib/foo").Mode.IsDir())
+}
+
+func TestReadRPMInvalid(t *testing.T) {
+	_, _, err := ReadRPM(bytes.NewReader([]byte("nope")))
+	require.ErrorIs(t, err, ErrInvalidRPM)
+
+	lead := make([]byte, 96)
+	copy(lead, rpmLeadMagic)
+	_, _, err = ReadRPM(bytes.NewReader(append(lead, "nope nope nope nope"...)))
+	require.ErrorIs(t, err, ErrInvalidRPM)
+}
+
+func TestDependencies(t *testing.T) {
+	hdr := RPMHeader{
+		strings: map[int][]string{
+			TagRequireName:    {"rpmlib(CompressedFileNames)", "foo", "bar", "baz"},
+			TagRequireVersion: {"3.0.4-1", "1.0", "2:1.0-1", ""},
+		},
+		ints: map[int][]int64{
+			TagRequireFlags: {senseRPMLib | senseLess | senseEqual, senseGreater | senseEqual, senseEqual, 0},
+		},
+	}
+	require.Equal(t, []RPMDependency{
+		{Name: "foo", Flags: "GE", Epoch: "0", Version: "1.0"},
+		{Name: "bar", Flags: "EQ", Epoch: "2", Version: "1.0", Release: "1"},
+		{Name: "baz"},
+	}, hdr.Dependencies(TagRequireName, TagRequireFlags, TagRequireVersion))
+}
+
+func TestSplitEVR(t *testing.T) {
+	for evr, expected := range map[string][3]string{
+		"1.0":       {"0", "1.0", ""},
+		"1.0-1":     {"0", "1.0", "1"},
+		"2:1.0-1.a": {"2", "1.0", "1.a"},
+	} {
+		epoch, version, release := splitEVR(evr)
+		require.Equal(t, expected, [3]string{epoch, version, release}, evr)
+	}
+}
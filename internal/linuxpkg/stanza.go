@@ -0,0 +1,85 @@
+package linuxpkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stanza is a paragraph of a debian control file, keeping the order of its
+// fields.
+type Stanza struct {
+	keys   []string
+	values map[string]string
+}
+
+// NewStanza creates an empty stanza.
+func NewStanza() *Stanza {
+	return &Stanza{values: map[string]string{}}
+}
+
+// Get returns the value of a field, which are case insensitive.
+func (s *Stanza) Get(key string) string {
+	return s.values[strings.ToLower(key)]
+}
+
+// Set sets the value of a field, adding it to the end if it is new.
+func (s *Stanza) Set(key, value string) {
+	lower := strings.ToLower(key)
+	if _, ok := s.values[lower]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.values[lower] = value
+}
+
+func (s *Stanza) String() string {
+	var b strings.Builder
+	for _, key := range s.keys {
+		value := s.values[strings.ToLower(key)]
+		if strings.HasPrefix(value, "\n") {
+			// multiline fields, like the Release checksums, start on the
+			// next line
+			fmt.Fprintf(&b, "%s:%s\n", key, value)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+	return b.String()
+}
+
+// ParseStanzas parses the paragraphs of a debian control file, such as a
+// package control file or a Packages index.
+func ParseStanzas(r io.Reader) ([]*Stanza, error) {
+	var result []*Stanza
+	var current *Stanza
+	var lastKey string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			current = NewStanza()
+			result = append(result, current)
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// continuation of a multiline field, like the description
+			if lastKey == "" {
+				return nil, fmt.Errorf("invalid control line: %q", line)
+			}
+			current.values[lastKey] += "\n" + line
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid control line: %q", line)
+		}
+		current.Set(parts[0], strings.TrimSpace(parts[1]))
+		lastKey = strings.ToLower(parts[0])
+	}
+	return result, scanner.Err()
+}
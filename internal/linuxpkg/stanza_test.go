@@ -0,0 +1,39 @@
+package linuxpkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStanzas(t *testing.T) {
+	stanzas, err := ParseStanzas(strings.NewReader(`Package: foo
+Description: foo
+ does things
+ .
+ really well
+
+Package: bar
+Version: 1.0
+`))
+	require.NoError(t, err)
+	require.Len(t, stanzas, 2)
+	require.Equal(t, "foo\n does things\n .\n really well", stanzas[0].Get("description"))
+	require.Equal(t, "Package: foo\nDescription: foo\n does things\n .\n really well\n", stanzas[0].String())
+	require.Equal(t, "1.0", stanzas[1].Get("Version"))
+
+	_, err = ParseStanzas(strings.NewReader("nope\n"))
+	require.EqualError(t, err, `invalid control line: "nope"`)
+
+	_, err = ParseStanzas(strings.NewReader(" nope\n"))
+	require.EqualError(t, err, `invalid control line: " nope"`)
+}
+
+func TestStanzaSet(t *testing.T) {
+	s := NewStanza()
+	s.Set("Package", "foo")
+	s.Set("SHA256", "\n abc 1 Packages")
+	s.Set("package", "bar")
+	require.Equal(t, "Package: bar\nSHA256:\n abc 1 Packages\n", s.String())
+}
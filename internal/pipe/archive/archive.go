@@ -9,8 +9,10 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/fileglob"
@@ -19,6 +21,8 @@ import (
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/archive"
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
+	"github.com/goreleaser/goreleaser/pkg/archive/makeself"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
@@ -161,27 +165,37 @@ func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Arti
 		return err
 	}
 
-	a := NewEnhancedArchive(archive.New(archiveFile), wrap)
-	defer a.Close()
-
 	files, err := findFiles(template, arch.Files)
 	if err != nil {
 		return fmt.Errorf("failed to find files to archive: %w", err)
 	}
-	for _, f := range files {
-		if err = a.Add(f); err != nil {
-			return fmt.Errorf("failed to add: '%s' -> '%s': %w", f.Source, f.Destination, err)
+	if hasTemplates(files) {
+		dir, err := os.MkdirTemp("", "goreleaser-archive-")
+		if err != nil {
+			return err
 		}
-	}
-	bins := []string{}
-	for _, binary := range binaries {
-		if err := a.Add(config.File{
-			Source:      binary.Path,
-			Destination: binary.Name,
-		}); err != nil {
-			return fmt.Errorf("failed to add: '%s' -> '%s': %w", binary.Path, binary.Name, err)
+		defer os.RemoveAll(dir)
+		if files, err = templateFiles(template, dir, files); err != nil {
+			return err
 		}
-		bins = append(bins, binary.Name)
+	}
+
+	opts, err := archiveOptions(ctx, template, arch, format)
+	if err != nil {
+		return err
+	}
+	arc, err := archive.NewWithOptions(archiveFile, opts)
+	if err != nil {
+		return err
+	}
+	a := NewEnhancedArchive(arc, wrap)
+	bins, err := addAll(a, files, binaries)
+	if err != nil {
+		_ = a.Close()
+		return err
+	}
+	if err := a.Close(); err != nil {
+		return fmt.Errorf("failed to close archive %s: %w", archivePath, err)
 	}
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Type:   artifact.UploadableArchive,
@@ -203,6 +217,96 @@ func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Arti
 	return nil
 }
 
+// addAll adds the given files and binaries to the archive, returning the
+// names of the binaries.
+func addAll(a archive.Archive, files []config.File, binaries []*artifact.Artifact) ([]string, error) {
+	for _, f := range files {
+		if err := a.Add(f); err != nil {
+			return nil, fmt.Errorf("failed to add: '%s' -> '%s': %w", f.Source, f.Destination, err)
+		}
+	}
+	bins := []string{}
+	for _, binary := range binaries {
+		if err := a.Add(config.File{
+			Source:      binary.Path,
+			Destination: binary.Name,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to add: '%s' -> '%s': %w", binary.Path, binary.Name, err)
+		}
+		bins = append(bins, binary.Name)
+	}
+	return bins, nil
+}
+
+// archiveOptions builds the archive options. Archives are reproducible if
+// either asked to or if SOURCE_DATE_EPOCH is set, in which case it is used as
+// the files modification time instead of the commit date.
+func archiveOptions(ctx *context.Context, template *tmpl.Template, arch config.Archive, format string) (archive.Options, error) {
+	opts := archive.Options{
+		Level:        arch.CompressionLevel,
+		Reproducible: arch.Reproducible,
+		MTime:        ctx.Git.CommitDate,
+	}
+	if format == "run" || format == "sh" {
+		makeself, err := makeselfOptions(ctx, template, arch.Makeself)
+		if err != nil {
+			return opts, err
+		}
+		opts.Makeself = makeself
+	}
+	epoch := ctx.Env["SOURCE_DATE_EPOCH"]
+	if epoch == "" {
+		return opts, nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return opts, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %q", epoch)
+	}
+	opts.Reproducible = true
+	opts.MTime = time.Unix(sec, 0).UTC()
+	return opts, nil
+}
+
+func makeselfOptions(ctx *context.Context, template *tmpl.Template, cfg config.Makeself) (makeself.Options, error) {
+	opts := makeself.Options{
+		Label: ctx.Config.ProjectName + " " + ctx.Version,
+	}
+	prefix, err := template.Apply(cfg.Prefix)
+	if err != nil {
+		return opts, err
+	}
+	opts.Prefix = prefix
+
+	if cfg.License != "" {
+		path, err := template.Apply(cfg.License)
+		if err != nil {
+			return opts, err
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read license: %w", err)
+		}
+		opts.License = string(bts)
+	}
+
+	if cfg.PostInstall != "" {
+		path, err := template.Apply(cfg.PostInstall)
+		if err != nil {
+			return opts, err
+		}
+		bts, err := os.ReadFile(path)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read post install script: %w", err)
+		}
+		script, err := template.Apply(string(bts))
+		if err != nil {
+			return opts, fmt.Errorf("failed to apply template to %s: %w", path, err)
+		}
+		opts.PostInstall = script
+	}
+	return opts, nil
+}
+
 func wrapFolder(a config.Archive) string {
 	switch a.WrapInDirectory {
 	case "true":
@@ -264,6 +368,7 @@ func findFiles(template *tmpl.Template, files []config.File) ([]config.File, err
 				Source:      file,
 				Destination: destinationFor(f, file),
 				Info:        f.Info,
+				Template:    f.Template,
 			})
 		}
 	}
@@ -275,6 +380,35 @@ func findFiles(template *tmpl.Template, files []config.File) ([]config.File, err
 	return unique(result), nil
 }
 
+func hasTemplates(files []config.File) bool {
+	for _, f := range files {
+		if f.Template {
+			return true
+		}
+	}
+	return false
+}
+
+// templateFiles renders the contents of the files that have templating
+// enabled into dir, and points their sources to the rendered files.
+func templateFiles(template *tmpl.Template, dir string, files []config.File) ([]config.File, error) {
+	result := make([]config.File, 0, len(files))
+	for _, f := range files {
+		if f.Template {
+			dst, err := entry.Path(dir, f.Destination)
+			if err != nil {
+				return nil, fmt.Errorf("failed to template %s: %w", f.Source, err)
+			}
+			if err := template.ApplyFile(f.Source, dst); err != nil {
+				return nil, err
+			}
+			f.Source = dst
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
 // remove duplicates
 func unique(in []config.File) []config.File {
 	var result []config.File
@@ -35,7 +35,7 @@ func createFakeBinary(t *testing.T, dist, arch, bin string) {
 
 func TestRunPipe(t *testing.T) {
 	folder := testlib.Mktmp(t)
-	for _, format := range []string{"tar.gz", "zip"} {
+	for _, format := range []string{"tar.gz", "zip", "tar.zst", "tar.bz2"} {
 		t.Run("Archive format "+format, func(t *testing.T) {
 			dist := filepath.Join(folder, format+"_dist")
 			require.NoError(t, os.Mkdir(dist, 0o755))
@@ -288,6 +288,357 @@ func TestRunPipeNoBinaries(t *testing.T) {
 	require.NoError(t, Pipe{}.Run(ctx))
 }
 
+func TestRunPipeInvalidCompressionLevel(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:           []string{"default"},
+			NameTemplate:     defaultNameTemplate,
+			Format:           "tar.zst",
+			CompressionLevel: 99,
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "zstd: invalid compression level: 99")
+}
+
+func TestRunPipeReproducible(t *testing.T) {
+	for name, tt := range map[string]struct {
+		reproducible bool
+		epoch        string
+		mtime        time.Time
+	}{
+		"commit date": {
+			reproducible: true,
+			mtime:        time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC),
+		},
+		"source date epoch": {
+			epoch: "1600000000",
+			mtime: time.Unix(1600000000, 0).UTC(),
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			folder := testlib.Mktmp(t)
+			dist := filepath.Join(folder, "dist")
+			require.NoError(t, os.Mkdir(dist, 0o755))
+			createFakeBinary(t, dist, "linuxamd64", "mybin")
+			require.NoError(t, os.WriteFile(filepath.Join(folder, "README.md"), []byte("readme"), 0o600))
+			ctx := context.New(config.Project{
+				Dist:        dist,
+				ProjectName: "foobar",
+				Archives: []config.Archive{{
+					Builds:       []string{"default"},
+					NameTemplate: defaultNameTemplate,
+					Format:       "tar.gz",
+					Reproducible: tt.reproducible,
+					Files:        []config.File{{Source: "README.*"}},
+				}},
+			})
+			ctx.Version = "0.0.1"
+			ctx.Git.CurrentTag = "v0.0.1"
+			ctx.Git.CommitDate = time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+			if tt.epoch != "" {
+				ctx.Env["SOURCE_DATE_EPOCH"] = tt.epoch
+			}
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Goos:   "linux",
+				Goarch: "amd64",
+				Name:   "mybin",
+				Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					artifact.ExtraBinary: "mybin",
+					artifact.ExtraID:     "default",
+				},
+			})
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			f, err := os.Open(filepath.Join(dist, "foobar_0.0.1_linux_amd64.tar.gz"))
+			require.NoError(t, err)
+			defer f.Close()
+			gr, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			defer gr.Close()
+			r := tar.NewReader(gr)
+			var names []string
+			for {
+				h, err := r.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				names = append(names, h.Name)
+				require.Equal(t, tt.mtime, h.ModTime.UTC(), h.Name)
+				require.Equal(t, "root", h.Uname, h.Name)
+				require.Equal(t, "root", h.Gname, h.Name)
+				require.Equal(t, int64(0o644), h.Mode, h.Name)
+			}
+			require.Equal(t, []string{"README.md", "mybin"}, names)
+		})
+	}
+}
+
+func TestRunPipeMakeself(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "LICENSE"), []byte("some license"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "post.sh"), []byte("echo {{ .ProjectName }} {{ .Version }}"), 0o600))
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:       []string{"default"},
+			NameTemplate: defaultNameTemplate,
+			Format:       "run",
+			Makeself: config.Makeself{
+				Prefix:      "/opt/{{ .ProjectName }}",
+				License:     "LICENSE",
+				PostInstall: "post.sh",
+			},
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "foobar_0.0.1_linux_amd64.run", archives[0].Name)
+	require.Equal(t, "run", archives[0].ExtraOr(artifact.ExtraFormat, ""))
+
+	bts, err := os.ReadFile(archives[0].Path)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "LABEL='foobar 0.0.1'")
+	require.Contains(t, string(bts), "PREFIX='/opt/foobar'")
+	require.Contains(t, string(bts), "\nsome license\n")
+	require.Contains(t, string(bts), "\necho foobar 0.0.1\n")
+}
+
+func TestRunPipeMakeselfMissingLicense(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:       []string{"default"},
+			NameTemplate: defaultNameTemplate,
+			Format:       "sh",
+			Makeself: config.Makeself{
+				License: "LICENSE",
+			},
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read license")
+}
+
+func TestRunPipeInvalidSourceDateEpoch(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:       []string{"default"},
+			NameTemplate: defaultNameTemplate,
+			Format:       "tar.gz",
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Env["SOURCE_DATE_EPOCH"] = "yesterday"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), `invalid SOURCE_DATE_EPOCH: "yesterday"`)
+}
+
+func TestRunPipeTemplatedFiles(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "README.md"), []byte("# {{ .ProjectName }} {{ .Version }} for {{ .Os }}/{{ .Arch }}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "LICENSE"), []byte("{{ .Version }}"), 0o600))
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:       []string{"default"},
+			NameTemplate: defaultNameTemplate,
+			Format:       "tar.gz",
+			Files: []config.File{
+				{Source: "README.*", Destination: "docs", Template: true},
+				{Source: "LICENSE"},
+			},
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	f, err := os.Open(filepath.Join(dist, "foobar_0.0.1_linux_amd64.tar.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer gr.Close()
+	r := tar.NewReader(gr)
+	contents := map[string]string{}
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		bts, err := io.ReadAll(r)
+		require.NoError(t, err)
+		contents[h.Name] = string(bts)
+	}
+	require.Equal(t, "# foobar 0.0.1 for linux/amd64\n", contents["docs/README.md"])
+	require.Equal(t, "{{ .Version }}", contents["LICENSE"])
+	require.FileExists(t, filepath.Join(folder, "README.md"))
+}
+
+func TestRunPipeInvalidTemplatedFile(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "README.md"), []byte("{{ .Nope }}"), 0o600))
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:       []string{"default"},
+			NameTemplate: defaultNameTemplate,
+			Format:       "tar.gz",
+			Files:        []config.File{{Source: "README.md", Template: true}},
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to apply template to README.md")
+}
+
+func TestRunPipeTemplatedFileOutsideDir(t *testing.T) {
+	folder := testlib.Mktmp(t)
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	createFakeBinary(t, dist, "linuxamd64", "mybin")
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "README.md"), []byte("{{ .Version }}"), 0o600))
+	ctx := context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "foobar",
+		Archives: []config.Archive{{
+			Builds:       []string{"default"},
+			NameTemplate: defaultNameTemplate,
+			Format:       "tar.gz",
+			Files: []config.File{
+				{Source: "README.md", Destination: "../../../../escaped", Template: true},
+			},
+		}},
+	})
+	ctx.Version = "0.0.1"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "linuxamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraBinary: "mybin",
+			artifact.ExtraID:     "default",
+		},
+	})
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "illegal path outside of the extraction directory")
+}
+
 func zipFiles(t *testing.T, path string) []string {
 	t.Helper()
 	f, err := os.Open(path)
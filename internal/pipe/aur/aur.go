@@ -0,0 +1,361 @@
+// Package aur implements the Pipe, providing AUR PKGBUILD and .SRCINFO
+// generation and pushing them to an AUR git repository.
+package aur
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	aurConfigExtra = "AURConfig"
+
+	defaultCommitMsg = "Update to {{ .Tag }}"
+)
+
+var (
+	// ErrNoArchivesFound happens when no linux archives are found.
+	ErrNoArchivesFound = errors.New("no linux archives found")
+
+	// ErrMultipleArchivesSameArch happens when the config yields multiple
+	// archives for the same architecture.
+	ErrMultipleArchivesSameArch = errors.New("one aur package can handle only one archive per architecture. Consider using ids in the aurs section")
+
+	errNoDescription = errors.New("aur: description is required")
+	errNoLicense     = errors.New("aur: license is required")
+)
+
+// architectures maps the go architectures to the Arch Linux ones.
+var architectures = map[string]string{
+	"386":   "i686",
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"arm6":  "armv6h",
+	"arm7":  "armv7h",
+}
+
+// Pipe for AUR packages.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "arch user repositories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.AURs) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.AURs {
+		aur := &ctx.Config.AURs[i]
+
+		if aur.Name == "" {
+			aur.Name = ctx.Config.ProjectName + "-bin"
+		}
+		if aur.CommitAuthor.Name == "" {
+			aur.CommitAuthor.Name = "goreleaserbot"
+		}
+		if aur.CommitAuthor.Email == "" {
+			aur.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+		if aur.CommitMessageTemplate == "" {
+			aur.CommitMessageTemplate = defaultCommitMsg
+		}
+		if aur.Rel == "" {
+			aur.Rel = "1"
+		}
+		if len(aur.Provides) == 0 {
+			aur.Provides = []string{ctx.Config.ProjectName}
+		}
+		if len(aur.Conflicts) == 0 {
+			aur.Conflicts = []string{ctx.Config.ProjectName}
+		}
+		for _, field := range []struct {
+			property string
+			old, new *string
+		}{
+			{"aurs.git_url", &aur.GitURL, &aur.Git.URL},
+			{"aurs.git_ssh_command", &aur.GitSSHCommand, &aur.Git.SSHCommand},
+			{"aurs.private_key", &aur.PrivateKey, &aur.Git.PrivateKey},
+		} {
+			if *field.old == "" {
+				continue
+			}
+			deprecate.Notice(ctx, field.property)
+			if *field.new == "" {
+				*field.new = *field.old
+			}
+			*field.old = ""
+		}
+	}
+	return nil
+}
+
+// Run creates the PKGBUILD and .SRCINFO files locally.
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish pushes the PKGBUILD and .SRCINFO files to the AUR.
+func (Pipe) Publish(ctx *context.Context) error {
+	skips := pipe.SkipMemento{}
+	for _, pkgbuild := range ctx.Artifacts.Filter(artifact.ByType(artifact.PkgBuild)).List() {
+		err := doPublish(ctx, pkgbuild)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, aur := range ctx.Config.AURs {
+		if err := doRun(ctx, aur, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, aur config.AUR, cl client.Client) error {
+	if aur.Git.URL == "" {
+		return pipe.Skip("aur.git.url is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByGoos("linux"),
+		artifact.Or(
+			artifact.ByGoarch("386"),
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+			artifact.And(
+				artifact.ByGoarch("arm"),
+				artifact.Or(
+					artifact.ByGoarm("6"),
+					artifact.ByGoarm("7"),
+				),
+			),
+		),
+		artifact.ByType(artifact.UploadableArchive),
+	}
+	if len(aur.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(aur.IDs...))
+	}
+
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	aur, err := templateFields(ctx, aur)
+	if err != nil {
+		return err
+	}
+
+	data, err := dataFor(ctx, aur, cl, archives)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range []struct {
+		name string
+		ext  string
+		kind artifact.Type
+		tpl  *template.Template
+	}{
+		{"PKGBUILD", ".pkgbuild", artifact.PkgBuild, pkgBuildTemplate},
+		{".SRCINFO", ".srcinfo", artifact.SrcInfo, srcInfoTemplate},
+	} {
+		var out bytes.Buffer
+		if err := info.tpl.Execute(&out, data); err != nil {
+			return err
+		}
+
+		filename := filepath.Join(ctx.Config.Dist, "aur", aur.Name+info.ext)
+		log.WithField("file", filename).Info("writing")
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", info.name, err)
+		}
+		if err := os.WriteFile(filename, out.Bytes(), 0o644); err != nil { //nolint: gosec
+			return fmt.Errorf("failed to write %s: %w", info.name, err)
+		}
+
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: info.name,
+			Path: filename,
+			Type: info.kind,
+			Extra: map[string]interface{}{
+				artifact.ExtraID: aur.Name,
+				aurConfigExtra:   aur,
+			},
+		})
+	}
+	return nil
+}
+
+// templateFields applies the templates of the config.
+func templateFields(ctx *context.Context, aur config.AUR) (config.AUR, error) {
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&aur.Name,
+		&aur.Description,
+		&aur.Homepage,
+		&aur.Package,
+		&aur.SkipUpload,
+	} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return aur, err
+		}
+	}
+
+	switch {
+	case aur.Description == "":
+		return aur, errNoDescription
+	case aur.License == "":
+		return aur, errNoLicense
+	}
+	return aur, nil
+}
+
+func dataFor(ctx *context.Context, aur config.AUR, cl client.Client, archives []*artifact.Artifact) (templateData, error) {
+	data := templateData{
+		Name:         aur.Name,
+		Desc:         aur.Description,
+		Homepage:     aur.Homepage,
+		Version:      pkgVersion(ctx.Version),
+		Rel:          aur.Rel,
+		License:      aur.License,
+		Maintainers:  aur.Maintainers,
+		Contributors: aur.Contributors,
+		Provides:     aur.Provides,
+		Conflicts:    aur.Conflicts,
+		Depends:      aur.Depends,
+		OptDepends:   aur.OptDepends,
+		Package:      aur.Package,
+	}
+
+	if aur.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return data, err
+		}
+		aur.URLTemplate = url
+	}
+
+	for _, art := range archives {
+		arch := architectures[art.Goarch+art.Goarm]
+		for _, seen := range data.Arches {
+			if seen == arch {
+				return data, ErrMultipleArchivesSameArch
+			}
+		}
+		data.Arches = append(data.Arches, arch)
+
+		url, err := tmpl.New(ctx).WithArtifact(art, map[string]string{}).Apply(aur.URLTemplate)
+		if err != nil {
+			return data, err
+		}
+
+		sum, err := art.Checksum("sha256")
+		if err != nil {
+			return data, err
+		}
+
+		data.Sources = append(data.Sources, releasePackage{
+			DownloadURL: url,
+			SHA256:      sum,
+			Arch:        arch,
+		})
+
+		if data.Package == "" {
+			data.Package = defaultPackage(art)
+		}
+	}
+
+	sort.Strings(data.Arches)
+	sort.Slice(data.Sources, func(i, j int) bool {
+		return data.Sources[i].Arch < data.Sources[j].Arch
+	})
+	return data, nil
+}
+
+// defaultPackage installs the binaries of the archive into /usr/bin.
+func defaultPackage(art *artifact.Artifact) string {
+	wrap := art.ExtraOr(artifact.ExtraWrappedIn, "").(string)
+	var lines []string
+	for _, bin := range art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string) {
+		src := path.Join(wrap, bin)
+		lines = append(lines, fmt.Sprintf(`install -Dm755 "./%s" "${pkgdir}/usr/bin/%s"`, src, path.Base(bin)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pkgVersion returns the version in a form accepted by pkgver, which can't
+// contain dashes.
+func pkgVersion(version string) string {
+	return strings.ReplaceAll(version, "-", "_")
+}
+
+func doPublish(ctx *context.Context, pkgbuild *artifact.Artifact) error {
+	aur := pkgbuild.Extra[aurConfigExtra].(config.AUR)
+
+	if strings.TrimSpace(aur.SkipUpload) == "true" {
+		return pipe.Skip("aur.skip_upload is set")
+	}
+	if strings.TrimSpace(aur.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping aur publish")
+	}
+
+	srcinfos := ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.SrcInfo),
+		artifact.ByIDs(aur.Name),
+	)).List()
+	if len(srcinfos) != 1 {
+		return fmt.Errorf("aur: expected one .SRCINFO for %s, found %d", aur.Name, len(srcinfos))
+	}
+
+	msg, err := tmpl.New(ctx).Apply(aur.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	files := make([]client.RepoFile, 0, 2)
+	for _, art := range []*artifact.Artifact{pkgbuild, srcinfos[0]} {
+		bts, err := os.ReadFile(art.Path)
+		if err != nil {
+			return err
+		}
+		files = append(files, client.RepoFile{Path: art.Name, Content: bts})
+	}
+
+	log.WithField("repo", aur.Git.URL).
+		WithField("name", aur.Name).
+		Info("pushing")
+
+	cli := client.NewGit(aur.Git)
+	if err := cli.CreateFiles(ctx, aur.CommitAuthor, client.Repo{Branch: "master"}, files, msg); err != nil {
+		return fmt.Errorf("failed to push %s: %w", aur.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package aur
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		AURs: []config.AUR{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		AURs:        []config.AUR{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.AUR{
+		Name: "foo-bin",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+		CommitMessageTemplate: defaultCommitMsg,
+		Rel:                   "1",
+		Provides:              []string{"foo"},
+		Conflicts:             []string{"foo"},
+	}, ctx.Config.AURs[0])
+}
+
+func TestDefaultDeprecatedGit(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		AURs: []config.AUR{{
+			GitURL:        "ssh://aur@aur.archlinux.org/foo-bin.git",
+			GitSSHCommand: "ssh -i {{ .KeyPath }}",
+			PrivateKey:    "{{ .Env.AUR_KEY }}",
+		}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.True(t, ctx.Deprecated)
+	aur := ctx.Config.AURs[0]
+	require.Equal(t, config.GitRepoRef{
+		URL:        "ssh://aur@aur.archlinux.org/foo-bin.git",
+		SSHCommand: "ssh -i {{ .KeyPath }}",
+		PrivateKey: "{{ .Env.AUR_KEY }}",
+	}, aur.Git)
+	require.Empty(t, aur.GitURL)
+	require.Empty(t, aur.GitSSHCommand)
+	require.Empty(t, aur.PrivateKey)
+}
+
+func newContext(tb testing.TB, aur config.AUR) *context.Context {
+	tb.Helper()
+	folder := tb.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		AURs:        []config.AUR{aur},
+	})
+	testlib.FakeRelease(tb, ctx, "v1.0.1-beta.1")
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	testlib.FakeArchives(tb, ctx, []testlib.FakeArchive{
+		{Goos: "linux", Goarch: "amd64"},
+		{Goos: "linux", Goarch: "arm64"},
+		{Goos: "linux", Goarch: "arm", Goarm: "6"},
+		{Goos: "linux", Goarch: "arm", Goarm: "7"},
+		{Goos: "linux", Goarch: "386"},
+		{Goos: "linux", Goarch: "mips"},
+		{Goos: "darwin", Goarch: "amd64"},
+	}, func(testlib.FakeArchive) artifact.Extras {
+		return artifact.Extras{
+			artifact.ExtraBinaries:  []string{"foo"},
+			artifact.ExtraWrappedIn: "",
+		}
+	})
+	return ctx
+}
+
+func validConfig(gitURL string) config.AUR {
+	return config.AUR{
+		Description:  "A {{ .ProjectName }} tool",
+		Homepage:     "https://example.com/foo",
+		License:      "MIT",
+		Maintainers:  []string{"Foo Bar <foo at bar dot com>"},
+		Contributors: []string{"Someone Else <someone at else dot com>"},
+		Depends:      []string{"git"},
+		OptDepends:   []string{"bash: for the completions"},
+		Git: config.GitRepoRef{
+			URL: gitURL,
+		},
+	}
+}
+
+func readArtifact(tb testing.TB, ctx *context.Context, kind artifact.Type) []byte {
+	tb.Helper()
+	arts := ctx.Artifacts.Filter(artifact.ByType(kind)).List()
+	require.Len(tb, arts, 1)
+	bts, err := os.ReadFile(arts[0].Path)
+	require.NoError(tb, err)
+	return bts
+}
+
+func TestRunPipe(t *testing.T) {
+	ctx := newContext(t, validConfig("ssh://aur@aur.archlinux.org/foo-bin.git"))
+	require.NoError(t, runAll(ctx, client.NewMock()))
+
+	t.Run("PKGBUILD", func(t *testing.T) {
+		golden.RequireEqual(t, readArtifact(t, ctx, artifact.PkgBuild))
+	})
+	t.Run("SRCINFO", func(t *testing.T) {
+		golden.RequireEqual(t, readArtifact(t, ctx, artifact.SrcInfo))
+	})
+}
+
+func TestRunPipeCustomPackage(t *testing.T) {
+	cfg := validConfig("ssh://aur@aur.archlinux.org/foo-bin.git")
+	cfg.Package = `
+install -Dm755 "./foo" "${pkgdir}/usr/bin/foo"
+install -Dm644 "./LICENSE" "${pkgdir}/usr/share/licenses/{{ .ProjectName }}/LICENSE"
+`
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.Contains(
+		t,
+		string(readArtifact(t, ctx, artifact.PkgBuild)),
+		"package() {\n"+
+			"  install -Dm755 \"./foo\" \"${pkgdir}/usr/bin/foo\"\n"+
+			"  install -Dm644 \"./LICENSE\" \"${pkgdir}/usr/share/licenses/foo/LICENSE\"\n"+
+			"}\n",
+	)
+}
+
+func TestRunPipeWrappedInDirectory(t *testing.T) {
+	ctx := newContext(t, validConfig("ssh://aur@aur.archlinux.org/foo-bin.git"))
+	for _, art := range ctx.Artifacts.List() {
+		art.Extra[artifact.ExtraWrappedIn] = "foo_1.0.1"
+	}
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.Contains(
+		t,
+		string(readArtifact(t, ctx, artifact.PkgBuild)),
+		`install -Dm755 "./foo_1.0.1/foo" "${pkgdir}/usr/bin/foo"`,
+	)
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		modify   func(cfg *config.AUR)
+		expected string
+	}{
+		"no description": {
+			modify:   func(cfg *config.AUR) { cfg.Description = "" },
+			expected: errNoDescription.Error(),
+		},
+		"no license": {
+			modify:   func(cfg *config.AUR) { cfg.License = "" },
+			expected: errNoLicense.Error(),
+		},
+		"no archives": {
+			modify:   func(cfg *config.AUR) { cfg.IDs = []string{"nope"} },
+			expected: ErrNoArchivesFound.Error(),
+		},
+		"invalid template": {
+			modify:   func(cfg *config.AUR) { cfg.Description = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+		"invalid url template": {
+			modify:   func(cfg *config.AUR) { cfg.URLTemplate = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig("ssh://aur@aur.archlinux.org/foo-bin.git")
+			tt.modify(&cfg)
+			ctx := newContext(t, cfg)
+			err := runAll(ctx, client.NewMock())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestRunPipeNoGitURL(t *testing.T) {
+	ctx := newContext(t, validConfig(""))
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeMultipleArchivesSameArch(t *testing.T) {
+	ctx := newContext(t, validConfig("ssh://aur@aur.archlinux.org/foo-bin.git"))
+	testlib.FakeArchives(t, ctx, []testlib.FakeArchive{
+		{ID: "bar", Goos: "linux", Goarch: "amd64"},
+	}, nil)
+	require.Equal(t, ErrMultipleArchivesSameArch, runAll(ctx, client.NewMock()))
+}
+
+// bareRepo creates a bare git repository and returns its file:// URL.
+func bareRepo(tb testing.TB) string {
+	tb.Helper()
+	testlib.CheckPath(tb, "git")
+	dir := filepath.Join(tb.TempDir(), "foo-bin.git")
+	out, err := exec.Command("git", "init", "--bare", dir).CombinedOutput()
+	require.NoError(tb, err, string(out))
+	return "file://" + filepath.ToSlash(dir)
+}
+
+func gitLog(tb testing.TB, url string, args ...string) string {
+	tb.Helper()
+	dir := strings.TrimPrefix(url, "file://")
+	out, err := exec.Command("git", append([]string{"--git-dir", dir}, args...)...).CombinedOutput()
+	require.NoError(tb, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func TestPublish(t *testing.T) {
+	url := bareRepo(t)
+	cfg := validConfig(url)
+	cfg.CommitAuthor = config.CommitAuthor{Name: "Foo Bot", Email: "bot@foo.com"}
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	require.Equal(t, "Update to v1.0.1-beta.1", gitLog(t, url, "log", "-1", "--format=%s", "master"))
+	require.Equal(t, "Foo Bot <bot@foo.com>", gitLog(t, url, "log", "-1", "--format=%an <%ae>", "master"))
+	require.Equal(t, ".SRCINFO\nPKGBUILD", gitLog(t, url, "ls-tree", "--name-only", "master"))
+	require.Equal(
+		t,
+		string(readArtifact(t, ctx, artifact.PkgBuild)),
+		gitLog(t, url, "show", "master:PKGBUILD")+"\n",
+	)
+	entries, err := os.ReadDir(filepath.Join(ctx.Config.Dist, "aur"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.False(t, entry.IsDir(), "the repository should not be cloned into dist: %s", entry.Name())
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		require.NoError(t, Pipe{}.Publish(ctx))
+		require.Equal(t, "1", gitLog(t, url, "rev-list", "--count", "master"))
+	})
+}
+
+func TestPublishRetry(t *testing.T) {
+	url := bareRepo(t)
+	bare := strings.TrimPrefix(url, "file://")
+	gitLog(t, url, "symbolic-ref", "HEAD", "refs/heads/master")
+	tree := gitLog(t, url, "mktree")
+	gitLog(t, url, "update-ref", "refs/heads/master", gitLog(t, url, "-c", "user.name=other", "-c", "user.email=other@foo.com", "commit-tree", tree, "-m", "init"))
+
+	// somebody else pushes right after the first clone, so the first push is
+	// rejected as non-fast-forward.
+	hooks := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "pushed")
+	require.NoError(t, os.WriteFile(filepath.Join(hooks, "post-checkout"), []byte(`#!/bin/sh
+[ -f "`+marker+`" ] && exit 0
+touch "`+marker+`"
+export GIT_DIR="`+bare+`"
+commit=$(git -c user.name=other -c user.email=other@foo.com commit-tree master^{tree} -p master -m concurrent)
+git update-ref refs/heads/master "$commit"
+`), 0o755))
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "core.hooksPath")
+	t.Setenv("GIT_CONFIG_VALUE_0", hooks)
+
+	cfg := validConfig(url)
+	cfg.CommitAuthor = config.CommitAuthor{Name: "Foo Bot", Email: "bot@foo.com"}
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.FileExists(t, marker)
+	require.Equal(t, "Update to v1.0.1-beta.1\nconcurrent\ninit", gitLog(t, url, "log", "--format=%s", "master"))
+}
+
+func TestPublishSkipUpload(t *testing.T) {
+	for _, skip := range []string{"true", "auto"} {
+		t.Run(skip, func(t *testing.T) {
+			cfg := validConfig(bareRepo(t))
+			cfg.SkipUpload = skip
+			ctx := newContext(t, cfg)
+			ctx.Semver.Prerelease = "beta.1"
+			require.NoError(t, runAll(ctx, client.NewMock()))
+			testlib.AssertSkipped(t, Pipe{}.Publish(ctx))
+		})
+	}
+}
+
+func TestPublishInvalidRepo(t *testing.T) {
+	cfg := validConfig("file://" + filepath.Join(t.TempDir(), "nope.git"))
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	err := Pipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to push foo-bin: could not clone")
+}
+
+func TestPublishSigned(t *testing.T) {
+	testlib.CheckPath(t, "ssh-keygen")
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-t", "ed25519", "-N", "", "-C", "bot@foo.com", "-f", key).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	url := bareRepo(t)
+	cfg := validConfig(url)
+	cfg.CommitAuthor = config.CommitAuthor{
+		Name:  "Foo Bot",
+		Email: "bot@foo.com",
+		Signing: config.CommitSigning{
+			Enabled: true,
+			Format:  "ssh",
+			Key:     key,
+		},
+	}
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	require.Contains(t, gitLog(t, url, "cat-file", "commit", "master"), "-----BEGIN SSH SIGNATURE-----")
+}
+
+func TestPublishInvalidSigning(t *testing.T) {
+	cfg := validConfig(bareRepo(t))
+	cfg.CommitAuthor.Signing = config.CommitSigning{Enabled: true, Format: "nope"}
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.EqualError(t, Pipe{}.Publish(ctx), "failed to push foo-bin: invalid commit signing format: nope")
+}
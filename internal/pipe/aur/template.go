@@ -0,0 +1,115 @@
+package aur
+
+import (
+	"strings"
+	"text/template"
+)
+
+type templateData struct {
+	Name         string
+	Desc         string
+	Homepage     string
+	Version      string
+	Rel          string
+	License      string
+	Maintainers  []string
+	Contributors []string
+	Provides     []string
+	Conflicts    []string
+	Depends      []string
+	OptDepends   []string
+	Arches       []string
+	Package      string
+	Sources      []releasePackage
+}
+
+type releasePackage struct {
+	DownloadURL string
+	SHA256      string
+	Arch        string
+}
+
+var funcs = template.FuncMap{
+	"list": func(s ...string) []string {
+		return s
+	},
+	"quoted": func(list []string) string {
+		quoted := make([]string, 0, len(list))
+		for _, s := range list {
+			quoted = append(quoted, "'"+strings.ReplaceAll(s, "'", `'\''`)+"'")
+		}
+		return strings.Join(quoted, " ")
+	},
+	"indent": func(s string) string {
+		lines := strings.Split(strings.Trim(s, "\n"), "\n")
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				lines[i] = ""
+				continue
+			}
+			lines[i] = "  " + strings.TrimRight(line, " \t")
+		}
+		return strings.Join(lines, "\n")
+	},
+}
+
+var pkgBuildTemplate = template.Must(template.New("PKGBUILD").Funcs(funcs).Parse(`# This file was generated by GoReleaser. DO NOT EDIT.
+{{- range .Maintainers }}
+# Maintainer: {{ . }}
+{{- end }}
+{{- range .Contributors }}
+# Contributor: {{ . }}
+{{- end }}
+
+pkgname='{{ .Name }}'
+pkgver={{ .Version }}
+pkgrel={{ .Rel }}
+pkgdesc={{ quoted (list .Desc) }}
+url='{{ .Homepage }}'
+arch=({{ quoted .Arches }})
+license=({{ quoted (list .License) }})
+provides=({{ quoted .Provides }})
+conflicts=({{ quoted .Conflicts }})
+{{- with .Depends }}
+depends=({{ quoted . }})
+{{- end }}
+{{- with .OptDepends }}
+optdepends=({{ quoted . }})
+{{- end }}
+{{ range .Sources }}
+source_{{ .Arch }}=('{{ .DownloadURL }}')
+sha256sums_{{ .Arch }}=('{{ .SHA256 }}')
+{{ end }}
+package() {
+{{ indent .Package }}
+}
+`))
+
+var srcInfoTemplate = template.Must(template.New(".SRCINFO").Funcs(funcs).Parse(`pkgbase = {{ .Name }}
+	pkgdesc = {{ .Desc }}
+	pkgver = {{ .Version }}
+	pkgrel = {{ .Rel }}
+	url = {{ .Homepage }}
+{{- range .Arches }}
+	arch = {{ . }}
+{{- end }}
+	license = {{ .License }}
+{{- range .Depends }}
+	depends = {{ . }}
+{{- end }}
+{{- range .OptDepends }}
+	optdepends = {{ . }}
+{{- end }}
+{{- range .Provides }}
+	provides = {{ . }}
+{{- end }}
+{{- range .Conflicts }}
+	conflicts = {{ . }}
+{{- end }}
+{{- range .Sources }}
+	source_{{ .Arch }} = {{ .DownloadURL }}
+	sha256sums_{{ .Arch }} = {{ .SHA256 }}
+{{- end }}
+
+pkgname = {{ .Name }}
+`))
@@ -84,6 +84,8 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 		artifact.ByType(artifact.Certificate),
 		artifact.ByType(artifact.LinuxPackage),
 		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.UploadableDebugSymbols),
+		artifact.ByType(artifact.LinuxRepository),
 	)
 	if len(conf.IDs) > 0 {
 		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
@@ -95,8 +97,17 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 	}
 	defer up.Close()
 
+	var artifacts []*artifact.Artifact
+	for _, a := range ctx.Artifacts.Filter(filter).List() {
+		files, err := a.Files()
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, files...)
+	}
+
 	g := semerrgroup.New(ctx.Parallelism)
-	for _, artifact := range ctx.Artifacts.Filter(filter).List() {
+	for _, artifact := range artifacts {
 		artifact := artifact
 		g.Go(func() error {
 			// TODO: replace this with ?prefix=folder on the bucket url
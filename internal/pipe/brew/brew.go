@@ -140,11 +140,11 @@ func doPublish(ctx *context.Context, formula *artifact.Artifact, cl client.Clien
 		return err
 	}
 
-	return cl.CreateFile(ctx, brew.CommitAuthor, repo, content, gpath, msg)
+	return client.PublishFile(ctx, cl, brew.Tap, brew.CommitAuthor, content, gpath, msg)
 }
 
 func doRun(ctx *context.Context, brew config.Homebrew, cl client.Client) error {
-	if brew.Tap.Name == "" {
+	if brew.Tap.Name == "" && brew.Tap.Git.URL == "" {
 		return pipe.Skip("brew tap name is not set")
 	}
 
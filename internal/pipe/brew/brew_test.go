@@ -387,6 +387,67 @@ func TestRunPipeNameTemplate(t *testing.T) {
 	require.Equal(t, client.Content, string(distBts))
 }
 
+func TestRunPipePullRequest(t *testing.T) {
+	folder := t.TempDir()
+	ctx := &context.Context{
+		Git: context.GitInfo{
+			CurrentTag: "v1.0.1",
+		},
+		Version:   "1.0.1",
+		Artifacts: artifact.New(),
+		Config: config.Project{
+			Dist:        folder,
+			ProjectName: "foo",
+			Brews: []config.Homebrew{
+				{
+					Name:                  "foo",
+					Folder:                "Formula",
+					CommitMessageTemplate: "Brew formula update for {{ .ProjectName }} version {{ .Tag }}",
+					Tap: config.RepoRef{
+						Owner:  "Homebrew",
+						Name:   "homebrew-core",
+						Branch: "master",
+						PullRequest: config.PullRequest{
+							Enabled: true,
+							Fork: config.PullRequestFork{
+								Owner: "foo",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	path := filepath.Join(folder, "bin.tar.gz")
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bin.tar.gz",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraFormat: "tar.gz",
+		},
+	})
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	cli := client.NewMock()
+
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.True(t, cli.CreatedFile)
+	require.Equal(t, "Formula/foo.rb", cli.Path)
+	head := client.Repo{Owner: "foo", Name: "homebrew-core", Branch: "foo-1.0.1"}
+	require.Equal(t, head, cli.FileRepo)
+	require.True(t, cli.OpenedPullRequest)
+	require.Equal(t, client.Repo{Owner: "Homebrew", Name: "homebrew-core", Branch: "master"}, cli.PullRequestBase)
+	require.Equal(t, head, cli.PullRequestHead)
+	require.Equal(t, "Brew formula update for foo version v1.0.1", cli.PullRequestTitle)
+}
+
 func TestRunPipeMultipleBrewsWithSkip(t *testing.T) {
 	folder := t.TempDir()
 	ctx := &context.Context{
@@ -75,6 +75,12 @@ func buildWithDefaults(ctx *context.Context, build config.Build) (config.Build,
 	if build.ID == "" {
 		build.ID = ctx.Config.ProjectName
 	}
+	if build.DebugSymbols.ID == "" {
+		build.DebugSymbols.ID = build.ID + "-debug"
+	}
+	if build.DebugSymbols.NameTemplate == "" {
+		build.DebugSymbols.NameTemplate = "{{ .ArtifactName }}.debug"
+	}
 	for k, v := range build.Env {
 		build.Env[k] = os.ExpandEnv(v)
 	}
@@ -270,6 +270,9 @@ func TestDefaultEmptyBuild(t *testing.T) {
 	require.Equal(t, []string{"hardfloat"}, build.Gomips)
 	require.Len(t, build.Ldflags, 1)
 	require.Equal(t, "-s -w -X main.version={{.Version}} -X main.commit={{.Commit}} -X main.date={{.Date}} -X main.builtBy=goreleaser", build.Ldflags[0])
+	require.False(t, build.DebugSymbols.Enabled)
+	require.Equal(t, "foo-debug", build.DebugSymbols.ID)
+	require.Equal(t, "{{ .ArtifactName }}.debug", build.DebugSymbols.NameTemplate)
 }
 
 func TestDefaultBuildID(t *testing.T) {
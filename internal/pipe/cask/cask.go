@@ -0,0 +1,356 @@
+// Package cask implements the Pipe, providing homebrew cask generation and
+// uploading it to a configured repo.
+package cask
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const caskConfigExtra = "CaskConfig"
+
+var (
+	// ErrNoArtifactsFound happens when no macOS artifacts are found.
+	ErrNoArtifactsFound = errors.New("no macos archives, dmg or pkg files found")
+
+	// ErrMultipleArtifactsSameArch happens when more than one archive, dmg or
+	// pkg targets the same mac, counting universal ones for both
+	// architectures.
+	ErrMultipleArtifactsSameArch = errors.New("found multiple macos artifacts for the same architecture, universal ones included. Consider using ids in the homebrew_casks section")
+)
+
+// Pipe for homebrew casks deployment.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "homebrew tap casks" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.HomebrewCasks) == 0 }
+
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.HomebrewCasks {
+		cask := &ctx.Config.HomebrewCasks[i]
+
+		if cask.CommitAuthor.Name == "" {
+			cask.CommitAuthor.Name = "goreleaserbot"
+		}
+		if cask.CommitAuthor.Email == "" {
+			cask.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+		if cask.CommitMessageTemplate == "" {
+			cask.CommitMessageTemplate = "Brew cask update for {{ .ProjectName }} version {{ .Tag }}"
+		}
+		if cask.Name == "" {
+			cask.Name = ctx.Config.ProjectName
+		}
+		if cask.Folder == "" {
+			cask.Folder = "Casks"
+		}
+	}
+	return nil
+}
+
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish homebrew casks.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, cask := range ctx.Config.HomebrewCasks {
+		if err := doRun(ctx, cask, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, cask := range ctx.Artifacts.Filter(artifact.ByType(artifact.BrewCask)).List() {
+		err := doPublish(ctx, cask, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, cask *artifact.Artifact, cl client.Client) error {
+	cfg := cask.Extra[caskConfigExtra].(config.HomebrewCask)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, cfg.Tap.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(cfg.SkipUpload) == "true" {
+		return pipe.Skip("homebrew_casks.skip_upload is set")
+	}
+
+	if strings.TrimSpace(cfg.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping homebrew cask publish")
+	}
+
+	gpath := path.Join(cfg.Folder, cask.Name)
+	log.WithField("cask", gpath).
+		WithField("repo", client.RepoFromRef(cfg.Tap).String()).
+		Info("pushing")
+
+	msg, err := tmpl.New(ctx).Apply(cfg.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(cask.Path)
+	if err != nil {
+		return err
+	}
+
+	return client.PublishFile(ctx, cl, cfg.Tap, cfg.CommitAuthor, content, gpath, msg)
+}
+
+func doRun(ctx *context.Context, cask config.HomebrewCask, cl client.Client) error {
+	if cask.Tap.Name == "" && cask.Tap.Git.URL == "" {
+		return pipe.Skip("homebrew cask tap name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByGoos("darwin"),
+		artifact.Or(
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("all"),
+		),
+		artifact.Or(
+			artifact.And(
+				artifact.ByFormats("zip", "tar.gz"),
+				artifact.ByType(artifact.UploadableArchive),
+			),
+			artifact.And(
+				artifact.ByFormats("dmg", "pkg"),
+				artifact.ByType(artifact.UploadableFile),
+			),
+		),
+		artifact.OnlyReplacingUnibins,
+	}
+	if len(cask.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(cask.IDs...))
+	}
+
+	artifacts := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(artifacts) == 0 {
+		return ErrNoArtifactsFound
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{&cask.Name, &cask.Tap.Owner, &cask.Tap.Name, &cask.SkipUpload} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return err
+		}
+	}
+
+	content, err := buildCask(ctx, cask, cl, artifacts)
+	if err != nil {
+		return err
+	}
+
+	filename := tokenFor(cask.Name) + ".rb"
+	path := filepath.Join(ctx.Config.Dist, filename)
+	log.WithField("cask", path).Info("writing")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write homebrew cask: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: path,
+		Type: artifact.BrewCask,
+		Extra: map[string]interface{}{
+			caskConfigExtra: cask,
+		},
+	})
+
+	return nil
+}
+
+func buildCask(ctx *context.Context, cask config.HomebrewCask, cl client.Client, artifacts []*artifact.Artifact) (string, error) {
+	data, err := dataFor(ctx, cask, cl, artifacts)
+	if err != nil {
+		return "", err
+	}
+	return doBuildCask(ctx, data)
+}
+
+func doBuildCask(ctx *context.Context, data templateData) (string, error) {
+	t, err := template.New(data.Token).Parse(caskTemplate)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+
+	content, err := tmpl.New(ctx).Apply(out.String())
+	if err != nil {
+		return "", err
+	}
+	out.Reset()
+
+	// Sanitize the template output and get rid of trailing whitespace.
+	s := bufio.NewScanner(strings.NewReader(content))
+	for s.Scan() {
+		_, _ = out.WriteString(strings.TrimRight(s.Text(), " "))
+		_ = out.WriteByte('\n')
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+func dataFor(ctx *context.Context, cfg config.HomebrewCask, cl client.Client, artifacts []*artifact.Artifact) (templateData, error) {
+	result := templateData{
+		Token:          tokenFor(cfg.Name),
+		Name:           cfg.Name,
+		Desc:           cfg.Description,
+		Homepage:       cfg.Homepage,
+		Version:        ctx.Version,
+		Caveats:        split(cfg.Caveats),
+		App:            cfg.App,
+		Binaries:       cfg.Binaries,
+		DependsOnMacOS: cfg.DependsOn.MacOS,
+		Uninstall:      uninstall(cfg.Uninstall),
+		ZapTrash:       cfg.Zap.Trash,
+	}
+
+	counts := map[string]int{}
+	for _, art := range artifacts {
+		sum, err := art.Checksum("sha256")
+		if err != nil {
+			return result, err
+		}
+
+		if cfg.URLTemplate == "" {
+			url, err := cl.ReleaseURLTemplate(ctx)
+			if err != nil {
+				return result, err
+			}
+			cfg.URLTemplate = url
+		}
+
+		url, err := tmpl.New(ctx).WithArtifact(art, map[string]string{}).Apply(cfg.URLTemplate)
+		if err != nil {
+			return result, err
+		}
+
+		pkg := releasePackage{
+			DownloadURL: url,
+			SHA256:      sum,
+			Arch:        art.Goarch,
+		}
+		if art.Format() == "pkg" {
+			pkg.Pkg = art.Name
+		}
+
+		if art.Goarch == "all" {
+			// universal artifacts are for both architectures
+			counts["amd64"]++
+			counts["arm64"]++
+		} else {
+			counts[art.Goarch]++
+		}
+		result.Packages = append(result.Packages, pkg)
+
+		if len(result.Binaries) == 0 && cfg.App == "" && art.Type == artifact.UploadableArchive {
+			result.Binaries = art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string)
+		}
+	}
+
+	for _, v := range counts {
+		if v > 1 {
+			return result, ErrMultipleArtifactsSameArch
+		}
+	}
+	if result.App == "" && len(result.Binaries) == 0 && result.Packages[0].Pkg == "" {
+		return result, fmt.Errorf("homebrew cask %s has no app, binaries or pkg to install", cfg.Name)
+	}
+
+	// intel first, then arm
+	sort.Slice(result.Packages, func(i, j int) bool {
+		return result.Packages[i].Arch < result.Packages[j].Arch
+	})
+	return result, nil
+}
+
+// uninstall renders the arguments of the uninstall stanza.
+func uninstall(cfg config.HomebrewCaskUninstall) string {
+	var args []string
+	if len(cfg.PkgUtil) > 0 {
+		args = append(args, "pkgutil: "+rubyArray(cfg.PkgUtil))
+	}
+	if len(cfg.Delete) > 0 {
+		args = append(args, "delete:  "+rubyArray(cfg.Delete))
+	}
+	return strings.Join(args, ",\n            ")
+}
+
+func rubyArray(items []string) string {
+	var b strings.Builder
+	b.WriteString("[\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "              %q,\n", item)
+	}
+	b.WriteString("            ]")
+	return b.String()
+}
+
+func split(s string) []string {
+	strings := strings.Split(strings.TrimSpace(s), "\n")
+	if len(strings) == 1 && strings[0] == "" {
+		return []string{}
+	}
+	return strings
+}
+
+var invalidTokenChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// tokenFor transforms the cask name into a valid cask token, e.g.
+// "My App" is turned into "my-app".
+func tokenFor(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "@", "-at-")
+	return strings.Trim(invalidTokenChars.ReplaceAllString(name, "-"), "-")
+}
@@ -0,0 +1,293 @@
+package cask
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestTokenFor(t *testing.T) {
+	for name, expected := range map[string]string{
+		"foo":         "foo",
+		"Foo":         "foo",
+		"My App":      "my-app",
+		"foo_bar":     "foo-bar",
+		"foo.bar":     "foo-bar",
+		"foo@2":       "foo-at-2",
+		"--Foo  Bar-": "foo-bar",
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, expected, tokenFor(name))
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	testlib.Mktmp(t)
+
+	ctx := &context.Context{
+		TokenType: context.TokenTypeGitHub,
+		Config: config.Project{
+			ProjectName:   "myproject",
+			HomebrewCasks: []config.HomebrewCask{{}},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.HomebrewCask{
+		Name:                  "myproject",
+		Folder:                "Casks",
+		CommitMessageTemplate: "Brew cask update for {{ .ProjectName }} version {{ .Tag }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.HomebrewCasks[0])
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		HomebrewCasks: []config.HomebrewCask{{}},
+	})))
+}
+
+func TestFullCask(t *testing.T) {
+	data := templateData{
+		Token:          "my-app",
+		Name:           "My App",
+		Desc:           "Some desc",
+		Homepage:       "https://example.com",
+		Version:        "1.2.3",
+		Caveats:        []string{"Here are some caveats", "on two lines"},
+		App:            "My App.app",
+		Binaries:       []string{"#{appdir}/My App.app/Contents/MacOS/myapp"},
+		DependsOnMacOS: ">= :big_sur",
+		Uninstall: uninstall(config.HomebrewCaskUninstall{
+			Delete: []string{"/usr/local/share/myapp"},
+		}),
+		ZapTrash: []string{"~/Library/Preferences/com.example.myapp.plist", "~/.myapp"},
+		Packages: []releasePackage{
+			{
+				DownloadURL: "https://example.com/myapp_1.2.3_darwin_amd64.zip",
+				SHA256:      "aaa",
+				Arch:        "amd64",
+			},
+			{
+				DownloadURL: "https://example.com/myapp_1.2.3_darwin_arm64.zip",
+				SHA256:      "bbb",
+				Arch:        "arm64",
+			},
+		},
+	}
+	cask, err := doBuildCask(context.New(config.Project{}), data)
+	require.NoError(t, err)
+	golden.RequireEqualRb(t, []byte(cask))
+}
+
+func TestPkgCask(t *testing.T) {
+	data := templateData{
+		Token:    "myapp",
+		Name:     "myapp",
+		Version:  "1.2.3",
+		Homepage: "https://example.com",
+		Uninstall: uninstall(config.HomebrewCaskUninstall{
+			PkgUtil: []string{"com.example.myapp"},
+			Delete:  []string{"/usr/local/bin/myapp"},
+		}),
+		Packages: []releasePackage{
+			{
+				DownloadURL: "https://example.com/myapp_1.2.3_darwin_all.pkg",
+				SHA256:      "ccc",
+				Arch:        "all",
+				Pkg:         "myapp_1.2.3_darwin_all.pkg",
+			},
+		},
+	}
+	cask, err := doBuildCask(context.New(config.Project{}), data)
+	require.NoError(t, err)
+	golden.RequireEqualRb(t, []byte(cask))
+}
+
+func createFile(tb testing.TB, path string) {
+	tb.Helper()
+	require.NoError(tb, os.WriteFile(path, []byte("fake "+filepath.Base(path)), 0o644))
+}
+
+func newContext(tb testing.TB, cask config.HomebrewCask) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		Dist:          tb.TempDir(),
+		ProjectName:   "foo",
+		HomebrewCasks: []config.HomebrewCask{cask},
+	})
+	testlib.FakeRelease(tb, ctx, "v1.0.1")
+	require.NoError(tb, Pipe{}.Default(ctx))
+	testlib.FakeArchives(tb, ctx, []testlib.FakeArchive{
+		{Goos: "darwin", Goarch: "amd64"},
+		{Goos: "linux", Goarch: "amd64"},
+		{Goos: "darwin", Goarch: "arm64"},
+		{Goos: "linux", Goarch: "arm64"},
+	}, func(testlib.FakeArchive) artifact.Extras {
+		return artifact.Extras{
+			artifact.ExtraBinaries: []string{"foo"},
+		}
+	})
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{
+		Tap: config.RepoRef{
+			Owner: "foo",
+			Name:  "homebrew-{{ .ProjectName }}",
+		},
+		Description: "A foo app",
+		Homepage:    "https://example.com",
+		Zap: config.HomebrewCaskZap{
+			Trash: []string{"~/.foo"},
+		},
+	})
+	cli := client.NewMock()
+
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.True(t, cli.CreatedFile)
+	require.Equal(t, "Casks/foo.rb", cli.Path)
+	require.Equal(t, client.Repo{Owner: "foo", Name: "homebrew-foo"}, cli.FileRepo)
+	golden.RequireEqualRb(t, []byte(cli.Content))
+
+	distBts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "foo.rb"))
+	require.NoError(t, err)
+	require.Equal(t, cli.Content, string(distBts))
+}
+
+func TestRunPipeUniversalPkg(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{
+		Tap: config.RepoRef{
+			Owner: "foo",
+			Name:  "homebrew-foo",
+		},
+		IDs: []string{"pkg"},
+		Uninstall: config.HomebrewCaskUninstall{
+			PkgUtil: []string{"com.example.foo"},
+		},
+	})
+	path := filepath.Join(ctx.Config.Dist, "foo_darwin_all.pkg")
+	createFile(t, path)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo_darwin_all.pkg",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "all",
+		Type:   artifact.UploadableFile,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "pkg",
+			artifact.ExtraFormat: "pkg",
+		},
+	})
+	cli := client.NewMock()
+
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	golden.RequireEqualRb(t, []byte(cli.Content))
+}
+
+func TestRunPipeNoArtifacts(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{
+		Tap: config.RepoRef{
+			Owner: "foo",
+			Name:  "homebrew-foo",
+		},
+		IDs: []string{"nope"},
+	})
+	require.Equal(t, ErrNoArtifactsFound, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeMultipleArtifactsSameArch(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{
+		Tap: config.RepoRef{
+			Owner: "foo",
+			Name:  "homebrew-foo",
+		},
+	})
+	path := filepath.Join(ctx.Config.Dist, "foo_darwin_all.zip")
+	createFile(t, path)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo_darwin_all.zip",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "all",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraFormat: "zip",
+		},
+	})
+	require.Equal(t, ErrMultipleArtifactsSameArch, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeNothingToInstall(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{
+		Tap: config.RepoRef{
+			Owner: "foo",
+			Name:  "homebrew-foo",
+		},
+	})
+	for _, a := range ctx.Artifacts.List() {
+		a.Extra[artifact.ExtraBinaries] = []string{}
+	}
+	require.EqualError(t, runAll(ctx, client.NewMock()), "homebrew cask foo has no app, binaries or pkg to install")
+}
+
+func TestRunPipeNoTapName(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{})
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeInvalidTemplate(t *testing.T) {
+	ctx := newContext(t, config.HomebrewCask{
+		Name: "{{ .Nope }",
+		Tap: config.RepoRef{
+			Owner: "foo",
+			Name:  "homebrew-foo",
+		},
+	})
+	require.Error(t, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeSkipUpload(t *testing.T) {
+	for name, tt := range map[string]struct {
+		skipUpload string
+		prerelease string
+	}{
+		"true": {skipUpload: "true"},
+		"auto": {skipUpload: "auto", prerelease: "beta1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := newContext(t, config.HomebrewCask{
+				Tap: config.RepoRef{
+					Owner: "foo",
+					Name:  "homebrew-foo",
+				},
+				SkipUpload: tt.skipUpload,
+			})
+			ctx.Semver.Prerelease = tt.prerelease
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			testlib.AssertSkipped(t, publishAll(ctx, cli))
+			require.False(t, cli.CreatedFile)
+		})
+	}
+}
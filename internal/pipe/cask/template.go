@@ -0,0 +1,107 @@
+package cask
+
+type templateData struct {
+	Token          string
+	Name           string
+	Desc           string
+	Homepage       string
+	Version        string
+	Caveats        []string
+	App            string
+	Binaries       []string
+	DependsOnMacOS string
+	Uninstall      string
+	ZapTrash       []string
+	Packages       []releasePackage
+}
+
+type releasePackage struct {
+	DownloadURL string
+	SHA256      string
+	Arch        string
+	Pkg         string
+}
+
+// CPU returns the name of the cask on_* block for the package architecture.
+func (p releasePackage) CPU() string {
+	if p.Arch == "arm64" {
+		return "arm"
+	}
+	return "intel"
+}
+
+const caskTemplate = `# typed: false
+# frozen_string_literal: true
+
+# This file was generated by GoReleaser. DO NOT EDIT.
+cask "{{ .Token }}" do
+  version "{{ .Version }}"
+  {{- if eq (len .Packages) 1 }}
+  {{- with index .Packages 0 }}
+  url "{{ .DownloadURL }}"
+  sha256 "{{ .SHA256 }}"
+  {{- end }}
+  {{- else }}
+  {{- range .Packages }}
+
+  on_{{ .CPU }} do
+    url "{{ .DownloadURL }}"
+    sha256 "{{ .SHA256 }}"
+    {{- with .Pkg }}
+
+    pkg "{{ . }}"
+    {{- end }}
+  end
+  {{- end }}
+  {{- end }}
+
+  name "{{ .Name }}"
+  {{- with .Desc }}
+  desc "{{ . }}"
+  {{- end }}
+  {{- with .Homepage }}
+  homepage "{{ . }}"
+  {{- end }}
+
+  {{- with .DependsOnMacOS }}
+
+  depends_on macos: "{{ . }}"
+  {{- end }}
+  {{- printf "\n" }}
+
+  {{- if eq (len .Packages) 1 }}
+  {{- with (index .Packages 0).Pkg }}
+  pkg "{{ . }}"
+  {{- end }}
+  {{- end }}
+  {{- with .App }}
+  app "{{ . }}"
+  {{- end }}
+  {{- range .Binaries }}
+  binary "{{ . }}"
+  {{- end }}
+
+  {{- with .Uninstall }}
+
+  uninstall {{ . }}
+  {{- end }}
+
+  {{- with .ZapTrash }}
+
+  zap trash: [
+    {{- range . }}
+    "{{ . }}",
+    {{- end }}
+  ]
+  {{- end }}
+
+  {{- with .Caveats }}
+
+  caveats <<~EOS
+    {{- range . }}
+    {{ . -}}
+    {{- end }}
+  EOS
+  {{- end }}
+end
+`
@@ -0,0 +1,390 @@
+// Package chocolatey implements the Pipe, providing chocolatey packages
+// generation and publishing them to a NuGet v2 compatible repository.
+package chocolatey
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	h "net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	chocoConfigExtra  = "ChocolateyConfig"
+	defaultSourceRepo = "https://push.chocolatey.org/"
+)
+
+var (
+	// ErrNoWindowsArtifacts happens when no windows zip archives are found.
+	ErrNoWindowsArtifacts = errors.New("no windows zip archives found")
+
+	// ErrMultipleArchivesSameArch happens when more than one zip archive is
+	// found for the same architecture, as the install script can only download
+	// one of them.
+	ErrMultipleArchivesSameArch = errors.New("found multiple windows zip archives for the same architecture. Consider using ids in the chocolateys section")
+
+	errNoAuthors     = errors.New("chocolatey: authors is required")
+	errNoDescription = errors.New("chocolatey: description is required")
+	errNoAPIKey      = errors.New("chocolatey: api_key is required to publish")
+)
+
+// Pipe for chocolatey packages.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "chocolatey packages" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Chocolateys) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Chocolateys {
+		choco := &ctx.Config.Chocolateys[i]
+
+		if choco.Name == "" {
+			choco.Name = ctx.Config.ProjectName
+		}
+		if choco.Title == "" {
+			choco.Title = ctx.Config.ProjectName
+		}
+		if choco.SourceRepo == "" {
+			choco.SourceRepo = defaultSourceRepo
+		}
+		if choco.ReleaseNotes == "" {
+			choco.ReleaseNotes = "{{ .ReleaseNotes }}"
+		}
+	}
+	return nil
+}
+
+// Run builds the chocolatey packages.
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish pushes the chocolatey packages.
+func (Pipe) Publish(ctx *context.Context) error {
+	return publishAll(ctx, h.DefaultClient)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, choco := range ctx.Config.Chocolateys {
+		if err := doRun(ctx, choco, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, hc *h.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, pkg := range ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableChocolatey)).List() {
+		err := doPublish(ctx, pkg, hc)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, choco config.Chocolatey, cl client.Client) error {
+	filters := []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.Or(
+			artifact.ByGoarch("386"),
+			artifact.ByGoarch("amd64"),
+		),
+		artifact.ByFormats("zip"),
+		artifact.ByType(artifact.UploadableArchive),
+	}
+	if len(choco.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(choco.IDs...))
+	}
+
+	artifacts := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(artifacts) == 0 {
+		return ErrNoWindowsArtifacts
+	}
+
+	choco, err := templateFields(ctx, choco)
+	if err != nil {
+		return err
+	}
+
+	script, err := installScriptFor(ctx, choco, cl, artifacts)
+	if err != nil {
+		return err
+	}
+
+	nuspec, err := nuspecFor(ctx, choco).Bytes()
+	if err != nil {
+		return err
+	}
+
+	name := choco.Name + "." + ctx.Version + ".nupkg"
+	path := filepath.Join(ctx.Config.Dist, name)
+	log.WithField("package", path).Info("writing")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write chocolatey package: %w", err)
+	}
+	defer f.Close()
+	if err := writeNupkg(ctx, f, choco.Name, nuspec, script); err != nil {
+		return fmt.Errorf("failed to write chocolatey package: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write chocolatey package: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: name,
+		Path: path,
+		Type: artifact.PublishableChocolatey,
+		Extra: map[string]interface{}{
+			artifact.ExtraFormat: "nupkg",
+			chocoConfigExtra:     choco,
+		},
+	})
+	return nil
+}
+
+func doPublish(ctx *context.Context, pkg *artifact.Artifact, hc *h.Client) error {
+	choco := pkg.Extra[chocoConfigExtra].(config.Chocolatey)
+	if choco.SkipPublish {
+		return pipe.Skip("chocolatey.skip_publish is set")
+	}
+
+	key, err := tmpl.New(ctx).Apply(choco.APIKey)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errNoAPIKey
+	}
+
+	target := strings.TrimSuffix(choco.SourceRepo, "/") + "/api/v2/package"
+	log.WithField("package", pkg.Name).
+		WithField("source", choco.SourceRepo).
+		Info("pushing")
+
+	return push(ctx, hc, target, key, pkg)
+}
+
+// push uploads the package using the NuGet v2 push API.
+func push(ctx *context.Context, hc *h.Client, target, key string, pkg *artifact.Artifact) error {
+	f, err := os.Open(pkg.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("package", pkg.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	req, err := h.NewRequestWithContext(ctx, h.MethodPut, target, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("X-NuGet-ApiKey", key)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push chocolatey package: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case h.StatusOK, h.StatusCreated, h.StatusAccepted:
+		return nil
+	default:
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to push chocolatey package: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+}
+
+// templateFields applies the templates of the config.
+func templateFields(ctx *context.Context, choco config.Chocolatey) (config.Chocolatey, error) {
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&choco.Name,
+		&choco.PackageSourceURL,
+		&choco.Owners,
+		&choco.Title,
+		&choco.Authors,
+		&choco.ProjectURL,
+		&choco.IconURL,
+		&choco.Copyright,
+		&choco.LicenseURL,
+		&choco.ProjectSourceURL,
+		&choco.DocsURL,
+		&choco.BugTrackerURL,
+		&choco.Summary,
+		&choco.Description,
+		&choco.ReleaseNotes,
+		&choco.SourceRepo,
+	} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return choco, err
+		}
+	}
+
+	switch {
+	case choco.Authors == "":
+		return choco, errNoAuthors
+	case choco.Description == "":
+		return choco, errNoDescription
+	}
+	return choco, nil
+}
+
+func nuspecFor(ctx *context.Context, choco config.Chocolatey) *Nuspec {
+	nuspec := &Nuspec{
+		Xmlns: schemaNamespace,
+		Metadata: Metadata{
+			ID:                       choco.Name,
+			Version:                  ctx.Version,
+			PackageSourceURL:         choco.PackageSourceURL,
+			Owners:                   choco.Owners,
+			Title:                    choco.Title,
+			Authors:                  choco.Authors,
+			ProjectURL:               choco.ProjectURL,
+			IconURL:                  choco.IconURL,
+			Copyright:                choco.Copyright,
+			LicenseURL:               choco.LicenseURL,
+			RequireLicenseAcceptance: choco.RequireLicenseAcceptance,
+			ProjectSourceURL:         choco.ProjectSourceURL,
+			DocsURL:                  choco.DocsURL,
+			BugTrackerURL:            choco.BugTrackerURL,
+			Tags:                     choco.Tags,
+			Summary:                  choco.Summary,
+			Description:              choco.Description,
+			ReleaseNotes:             choco.ReleaseNotes,
+		},
+	}
+	if len(choco.Dependencies) > 0 {
+		nuspec.Metadata.Dependencies = &Dependencies{}
+		for _, dep := range choco.Dependencies {
+			nuspec.Metadata.Dependencies.Dependency = append(
+				nuspec.Metadata.Dependencies.Dependency,
+				Dependency{ID: dep.ID, Version: dep.Version},
+			)
+		}
+	}
+	return nuspec
+}
+
+func installScriptFor(ctx *context.Context, choco config.Chocolatey, cl client.Client, artifacts []*artifact.Artifact) ([]byte, error) {
+	if choco.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		choco.URLTemplate = url
+	}
+
+	data := templateData{}
+	seen := map[string]bool{}
+	for _, art := range artifacts {
+		if seen[art.Goarch] {
+			return nil, ErrMultipleArchivesSameArch
+		}
+		seen[art.Goarch] = true
+
+		url, err := tmpl.New(ctx).WithArtifact(art, map[string]string{}).Apply(choco.URLTemplate)
+		if err != nil {
+			return nil, err
+		}
+
+		sum, err := art.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+
+		data.Packages = append(data.Packages, releasePackage{
+			DownloadURL: url,
+			Checksum:    sum,
+			Arch:        art.Goarch,
+		})
+	}
+
+	var out bytes.Buffer
+	if err := scriptTemplate.Execute(&out, data); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeNupkg writes the nupkg, which is a zip file with the nuspec, the
+// install script, and the Open Packaging Conventions metadata.
+func writeNupkg(ctx *context.Context, w io.Writer, id string, nuspec, script []byte) error {
+	z := zip.NewWriter(w)
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{"_rels/.rels", []byte(fmt.Sprintf(relsTemplate, id))},
+		{"[Content_Types].xml", []byte(contentTypes)},
+		{id + ".nuspec", nuspec},
+		{"tools/chocolateyinstall.ps1", script},
+	} {
+		fw, err := z.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: ctx.Date,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(file.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+const relsTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Type="http://schemas.microsoft.com/packaging/2010/07/manifest" Target="/%s.nuspec" Id="R1" />
+</Relationships>
+`
+
+const contentTypes = `<?xml version="1.0" encoding="utf-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+  <Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml" />
+  <Default Extension="nuspec" ContentType="application/octet" />
+  <Default Extension="ps1" ContentType="application/octet" />
+</Types>
+`
@@ -0,0 +1,239 @@
+package chocolatey
+
+import (
+	"archive/zip"
+	"io"
+	h "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Chocolateys: []config.Chocolatey{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Chocolateys: []config.Chocolatey{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Chocolatey{
+		Name:         "foo",
+		Title:        "foo",
+		SourceRepo:   "https://push.chocolatey.org/",
+		ReleaseNotes: "{{ .ReleaseNotes }}",
+	}, ctx.Config.Chocolateys[0])
+}
+
+func newContext(tb testing.TB, choco config.Chocolatey) *context.Context {
+	tb.Helper()
+	folder := tb.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Chocolateys: []config.Chocolatey{choco},
+	})
+	testlib.FakeRelease(tb, ctx, "v1.0.1")
+	ctx.ReleaseNotes = "## Changelog\n\n* abc123 feat: foo\n"
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	var archives []testlib.FakeArchive
+	for _, goarch := range []string{"386", "amd64", "arm64"} {
+		for _, goos := range []string{"windows", "linux"} {
+			archives = append(archives, testlib.FakeArchive{Goos: goos, Goarch: goarch, Format: "zip"})
+		}
+	}
+	testlib.FakeArchives(tb, ctx, archives, nil)
+	return ctx
+}
+
+func validConfig() config.Chocolatey {
+	return config.Chocolatey{
+		Authors:                  "Foo Inc",
+		Owners:                   "foo",
+		ProjectURL:               "https://example.com/foo",
+		LicenseURL:               "https://example.com/foo/LICENSE",
+		Tags:                     "cli foo",
+		Summary:                  "A {{ .ProjectName }} tool",
+		Description:              "A longer description of {{ .ProjectName }}.",
+		APIKey:                   "{{ .Env.CHOCOLATEY_API_KEY }}",
+		RequireLicenseAcceptance: true,
+		Dependencies: []config.ChocolateyDependency{
+			{ID: "nfpm", Version: "2.20.0"},
+		},
+	}
+}
+
+func readNupkg(tb testing.TB, path string) map[string][]byte {
+	tb.Helper()
+	z, err := zip.OpenReader(path)
+	require.NoError(tb, err)
+	defer z.Close()
+
+	files := map[string][]byte{}
+	for _, f := range z.File {
+		r, err := f.Open()
+		require.NoError(tb, err)
+		bts, err := io.ReadAll(r)
+		require.NoError(tb, err)
+		require.NoError(tb, r.Close())
+		files[f.Name] = bts
+	}
+	return files
+}
+
+func TestRunPipe(t *testing.T) {
+	ctx := newContext(t, validConfig())
+	require.NoError(t, runAll(ctx, client.NewMock()))
+
+	pkgs := ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableChocolatey)).List()
+	require.Len(t, pkgs, 1)
+	require.Equal(t, "foo.1.0.1.nupkg", pkgs[0].Name)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, "foo.1.0.1.nupkg"), pkgs[0].Path)
+
+	files := readNupkg(t, pkgs[0].Path)
+	require.Len(t, files, 4)
+	require.Contains(t, files, "_rels/.rels")
+	require.Contains(t, files, "[Content_Types].xml")
+
+	t.Run("nuspec", func(t *testing.T) {
+		golden.RequireEqual(t, files["foo.nuspec"])
+	})
+	t.Run("install script", func(t *testing.T) {
+		golden.RequireEqual(t, files["tools/chocolateyinstall.ps1"])
+	})
+}
+
+func TestRunPipeNoDependencies(t *testing.T) {
+	cfg := validConfig()
+	cfg.Dependencies = nil
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	pkgs := ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableChocolatey)).List()
+	require.Len(t, pkgs, 1)
+	require.NotContains(t, string(readNupkg(t, pkgs[0].Path)["foo.nuspec"]), "dependencies")
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		modify   func(cfg *config.Chocolatey)
+		expected string
+	}{
+		"no authors": {
+			modify:   func(cfg *config.Chocolatey) { cfg.Authors = "" },
+			expected: errNoAuthors.Error(),
+		},
+		"no description": {
+			modify:   func(cfg *config.Chocolatey) { cfg.Description = "" },
+			expected: errNoDescription.Error(),
+		},
+		"no artifacts": {
+			modify:   func(cfg *config.Chocolatey) { cfg.IDs = []string{"nope"} },
+			expected: ErrNoWindowsArtifacts.Error(),
+		},
+		"invalid template": {
+			modify:   func(cfg *config.Chocolatey) { cfg.Authors = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+		"invalid url template": {
+			modify:   func(cfg *config.Chocolatey) { cfg.URLTemplate = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+			ctx := newContext(t, cfg)
+			err := runAll(ctx, client.NewMock())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestRunPipeMultipleArtifactsSameArch(t *testing.T) {
+	ctx := newContext(t, validConfig())
+	testlib.FakeArchives(t, ctx, []testlib.FakeArchive{
+		{ID: "bar", Goos: "windows", Goarch: "amd64", Format: "zip"},
+	}, nil)
+	require.Equal(t, ErrMultipleArchivesSameArch, runAll(ctx, client.NewMock()))
+}
+
+func TestPublish(t *testing.T) {
+	var gotKey, gotName string
+	var gotBody []byte
+	srv := httptest.NewServer(h.HandlerFunc(func(w h.ResponseWriter, r *h.Request) {
+		require.Equal(t, h.MethodPut, r.Method)
+		require.Equal(t, "/api/v2/package", r.URL.Path)
+		gotKey = r.Header.Get("X-NuGet-ApiKey")
+		f, header, err := r.FormFile("package")
+		require.NoError(t, err)
+		defer f.Close()
+		gotName = header.Filename
+		gotBody, err = io.ReadAll(f)
+		require.NoError(t, err)
+		w.WriteHeader(h.StatusCreated)
+	}))
+	defer srv.Close()
+
+	cfg := validConfig()
+	cfg.SourceRepo = srv.URL + "/"
+	ctx := newContext(t, cfg)
+	ctx.Env = map[string]string{"CHOCOLATEY_API_KEY": "secret"}
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.NoError(t, publishAll(ctx, srv.Client()))
+
+	require.Equal(t, "secret", gotKey)
+	require.Equal(t, "foo.1.0.1.nupkg", gotName)
+	expected, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "foo.1.0.1.nupkg"))
+	require.NoError(t, err)
+	require.Equal(t, expected, gotBody)
+}
+
+func TestPublishFailed(t *testing.T) {
+	srv := httptest.NewServer(h.HandlerFunc(func(w h.ResponseWriter, r *h.Request) {
+		w.WriteHeader(h.StatusConflict)
+		_, _ = w.Write([]byte("package already exists"))
+	}))
+	defer srv.Close()
+
+	cfg := validConfig()
+	cfg.SourceRepo = srv.URL
+	ctx := newContext(t, cfg)
+	ctx.Env = map[string]string{"CHOCOLATEY_API_KEY": "secret"}
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.EqualError(t, publishAll(ctx, srv.Client()), "failed to push chocolatey package: 409 Conflict: package already exists")
+}
+
+func TestPublishNoAPIKey(t *testing.T) {
+	ctx := newContext(t, validConfig())
+	ctx.Env = map[string]string{"CHOCOLATEY_API_KEY": ""}
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	require.Equal(t, errNoAPIKey, publishAll(ctx, h.DefaultClient))
+}
+
+func TestPublishSkip(t *testing.T) {
+	cfg := validConfig()
+	cfg.SkipPublish = true
+	ctx := newContext(t, cfg)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	testlib.AssertSkipped(t, publishAll(ctx, h.DefaultClient))
+}
@@ -0,0 +1,65 @@
+package chocolatey
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+const schemaNamespace = "http://schemas.microsoft.com/packaging/2015/06/nuspec.xsd"
+
+// Nuspec represents a Nuget/Chocolatey Nuspec.
+// More info: https://learn.microsoft.com/en-us/nuget/reference/nuspec
+// https://docs.chocolatey.org/en-us/create/create-packages
+type Nuspec struct {
+	XMLName  xml.Name `xml:"package"`
+	Xmlns    string   `xml:"xmlns,attr,omitempty"`
+	Metadata Metadata `xml:"metadata"`
+}
+
+// Metadata contains information about a single package.
+type Metadata struct {
+	ID                       string        `xml:"id"`
+	Version                  string        `xml:"version"`
+	PackageSourceURL         string        `xml:"packageSourceUrl,omitempty"`
+	Owners                   string        `xml:"owners,omitempty"`
+	Title                    string        `xml:"title,omitempty"`
+	Authors                  string        `xml:"authors"`
+	ProjectURL               string        `xml:"projectUrl,omitempty"`
+	IconURL                  string        `xml:"iconUrl,omitempty"`
+	Copyright                string        `xml:"copyright,omitempty"`
+	LicenseURL               string        `xml:"licenseUrl,omitempty"`
+	RequireLicenseAcceptance bool          `xml:"requireLicenseAcceptance"`
+	ProjectSourceURL         string        `xml:"projectSourceUrl,omitempty"`
+	DocsURL                  string        `xml:"docsUrl,omitempty"`
+	BugTrackerURL            string        `xml:"bugTrackerUrl,omitempty"`
+	Tags                     string        `xml:"tags,omitempty"`
+	Summary                  string        `xml:"summary,omitempty"`
+	Description              string        `xml:"description"`
+	ReleaseNotes             string        `xml:"releaseNotes,omitempty"`
+	Dependencies             *Dependencies `xml:"dependencies,omitempty"`
+}
+
+// Dependency represents a dependency element.
+type Dependency struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr,omitempty"`
+}
+
+// Dependencies represents a collection zero or more dependency elements.
+type Dependencies struct {
+	Dependency []Dependency `xml:"dependency"`
+}
+
+// Bytes returns the Nuspec as an indented XML document.
+func (n *Nuspec) Bytes() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
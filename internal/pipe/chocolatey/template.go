@@ -0,0 +1,49 @@
+package chocolatey
+
+import "text/template"
+
+type templateData struct {
+	Packages []releasePackage
+}
+
+type releasePackage struct {
+	DownloadURL string
+	Checksum    string
+	Arch        string
+}
+
+// Suffix returns the suffix chocolatey uses for the package arguments of
+// the package architecture.
+func (p releasePackage) Suffix() string {
+	if p.Arch == "amd64" {
+		return "64"
+	}
+	return ""
+}
+
+// URLKey returns the chocolatey package argument holding the package URL.
+func (p releasePackage) URLKey() string {
+	if p.Arch == "amd64" {
+		return "url64bit"
+	}
+	return "url"
+}
+
+var scriptTemplate = template.Must(template.New("chocolateyinstall.ps1").Parse(`# This file was generated by GoReleaser. DO NOT EDIT.
+$ErrorActionPreference = 'Stop';
+
+$packageName = $env:chocolateyPackageName
+$installDir = "$(Split-Path -parent $MyInvocation.MyCommand.Definition)"
+
+$packageArgs = @{
+  packageName = $packageName
+  unzipLocation = $installDir
+{{- range .Packages }}
+  {{ .URLKey }} = '{{ .DownloadURL }}'
+  checksumType{{ .Suffix }} = 'sha256'
+  checksum{{ .Suffix }} = '{{ .Checksum }}'
+{{- end }}
+}
+
+Install-ChocolateyZipPackage @packageArgs
+`))
@@ -0,0 +1,87 @@
+// Package debugsymbols provides a pipe that splits the debug symbols out of
+// the built binaries into separate artifacts.
+package debugsymbols
+
+import (
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/debugsym"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe for debug symbols.
+type Pipe struct{}
+
+func (Pipe) String() string { return "splitting debug symbols" }
+
+func (Pipe) Skip(ctx *context.Context) bool {
+	for _, build := range ctx.Config.Builds {
+		if build.DebugSymbols.Enabled && !build.Skip {
+			return false
+		}
+	}
+	return true
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, build := range ctx.Config.Builds {
+		if !build.DebugSymbols.Enabled || build.Skip {
+			continue
+		}
+		build := build
+		for _, binary := range ctx.Artifacts.Filter(artifact.And(
+			artifact.ByType(artifact.Binary),
+			artifact.ByIDs(build.ID),
+		)).List() {
+			binary := binary
+			if !debugsym.Supported(binary.Goos) {
+				log.WithField("binary", binary.Path).
+					Warnf("splitting debug symbols is not supported for %s binaries", binary.Goos)
+				continue
+			}
+			g.Go(func() error {
+				return split(ctx, build.DebugSymbols, binary)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+func split(ctx *context.Context, conf config.DebugSymbols, binary *artifact.Artifact) error {
+	name, err := tmpl.New(ctx).WithArtifact(binary, map[string]string{}).Apply(conf.NameTemplate)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(filepath.Dir(binary.Path), name)
+
+	log.WithField("binary", binary.Path).WithField("debug", path).Info("splitting debug symbols")
+	if err := debugsym.Split(binary.Path, path); err != nil {
+		return err
+	}
+
+	typ := artifact.DebugSymbols
+	if conf.Upload {
+		typ = artifact.UploadableDebugSymbols
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   typ,
+		Name:   name,
+		Path:   path,
+		Goos:   binary.Goos,
+		Goarch: binary.Goarch,
+		Goarm:  binary.Goarm,
+		Gomips: binary.Gomips,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     conf.ID,
+			artifact.ExtraBinary: binary.ExtraOr(artifact.ExtraBinary, ""),
+		},
+	})
+	return nil
+}
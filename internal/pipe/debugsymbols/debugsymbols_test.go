@@ -0,0 +1,222 @@
+package debugsymbols
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(context.New(config.Project{
+			Builds: []config.Build{{ID: "foo"}},
+		})))
+	})
+
+	t.Run("skip build", func(t *testing.T) {
+		require.True(t, Pipe{}.Skip(context.New(config.Project{
+			Builds: []config.Build{{
+				ID:           "foo",
+				Skip:         true,
+				DebugSymbols: config.DebugSymbols{Enabled: true},
+			}},
+		})))
+	})
+
+	t.Run("dont skip", func(t *testing.T) {
+		require.False(t, Pipe{}.Skip(context.New(config.Project{
+			Builds: []config.Build{
+				{ID: "foo"},
+				{ID: "bar", DebugSymbols: config.DebugSymbols{Enabled: true}},
+			},
+		})))
+	})
+}
+
+func TestRun(t *testing.T) {
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Builds: []config.Build{
+			{
+				ID: "foo",
+				DebugSymbols: config.DebugSymbols{
+					Enabled:      true,
+					ID:           "foo-debug",
+					NameTemplate: "{{ .Binary }}_{{ .Os }}_{{ .Arch }}.debug",
+				},
+			},
+			{
+				ID: "bar",
+			},
+		},
+	})
+
+	linux := buildBinary(t, dist, "foo")
+	bar := buildBinary(t, dist, "bar")
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   linux,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraBinary: "foo",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   filepath.Join(dist, "foo_darwin"),
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraBinary: "foo",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bar",
+		Path:   bar,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "bar",
+			artifact.ExtraBinary: "bar",
+		},
+	})
+
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	debugs := ctx.Artifacts.Filter(artifact.ByType(artifact.DebugSymbols)).List()
+	require.Len(t, debugs, 1)
+	debug := debugs[0]
+	require.Equal(t, "foo_linux_amd64.debug", debug.Name)
+	require.Equal(t, filepath.Join(filepath.Dir(linux), "foo_linux_amd64.debug"), debug.Path)
+	require.Equal(t, "foo-debug", debug.ID())
+	require.Equal(t, "linux", debug.Goos)
+	require.Equal(t, "amd64", debug.Goarch)
+	require.FileExists(t, debug.Path)
+
+	requireSymbols(t, linux, false)
+	requireSymbols(t, debug.Path, true)
+	requireSymbols(t, bar, true)
+}
+
+func TestRunUpload(t *testing.T) {
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Builds: []config.Build{{
+			ID: "foo",
+			DebugSymbols: config.DebugSymbols{
+				Enabled:      true,
+				ID:           "foo-debug",
+				NameTemplate: "foo.debug",
+				Upload:       true,
+			},
+		}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   buildBinary(t, dist, "foo"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraBinary: "foo",
+		},
+	})
+
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.DebugSymbols)).List())
+	debugs := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableDebugSymbols)).List()
+	require.Len(t, debugs, 1)
+	require.Equal(t, "foo.debug", debugs[0].Name)
+}
+
+func TestRunBadTemplate(t *testing.T) {
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Builds: []config.Build{{
+			ID: "foo",
+			DebugSymbols: config.DebugSymbols{
+				Enabled:      true,
+				NameTemplate: "{{ .Nope }",
+			},
+		}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   filepath.Join(dist, "foo"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "foo",
+		},
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestRunNotELF(t *testing.T) {
+	dist := t.TempDir()
+	path := filepath.Join(dist, "foo")
+	require.NoError(t, os.WriteFile(path, []byte("not a binary"), 0o755))
+	ctx := context.New(config.Project{
+		Dist: dist,
+		Builds: []config.Build{{
+			ID: "foo",
+			DebugSymbols: config.DebugSymbols{
+				Enabled:      true,
+				NameTemplate: "foo.debug",
+			},
+		}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Path:   path,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "foo",
+		},
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func requireSymbols(tb testing.TB, path string, has bool) {
+	tb.Helper()
+	f, err := elf.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	_, err = f.Symbols()
+	if has {
+		require.NoError(tb, err)
+		return
+	}
+	require.ErrorIs(tb, err, elf.ErrNoSymbols)
+}
+
+func buildBinary(tb testing.TB, dist, name string) string {
+	tb.Helper()
+	bin := filepath.Join(dist, name+"_linux_amd64", name)
+	testlib.BuildBinary(tb, "linux", "amd64", bin, nil)
+	return bin
+}
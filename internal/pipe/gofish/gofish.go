@@ -77,7 +77,7 @@ func runAll(ctx *context.Context, cli client.Client) error {
 }
 
 func doRun(ctx *context.Context, goFish config.GoFish, cl client.Client) error {
-	if goFish.Rig.Name == "" {
+	if goFish.Rig.Name == "" && goFish.Rig.Git.URL == "" {
 		return pipe.Skip("Rigs rig name is not set")
 	}
 
@@ -302,7 +302,7 @@ func doPublish(ctx *context.Context, food *artifact.Artifact, cl client.Client)
 		return err
 	}
 
-	return cl.CreateFile(ctx, rig.CommitAuthor, repo, content, gpath, msg)
+	return client.PublishFile(ctx, cl, rig.Rig, rig.CommitAuthor, content, gpath, msg)
 }
 
 func buildFoodPath(folder, filename string) string {
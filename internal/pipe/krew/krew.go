@@ -313,7 +313,7 @@ func doPublish(ctx *context.Context, manifest *artifact.Artifact, cl client.Clie
 		return err
 	}
 
-	return cl.CreateFile(ctx, cfg.CommitAuthor, repo, content, gpath, msg)
+	return client.PublishFile(ctx, cl, cfg.Index, cfg.CommitAuthor, content, gpath, msg)
 }
 
 func buildManifestPath(folder, filename string) string {
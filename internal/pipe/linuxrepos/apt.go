@@ -0,0 +1,227 @@
+package linuxrepos
+
+import (
+	"bytes"
+	"crypto/md5"  // #nosec
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/linuxpkg"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"golang.org/x/crypto/openpgp"
+)
+
+// apt creates an apt repository from the given deb packages, at dir/deb.
+func apt(repo config.LinuxRepo, dir, index string, date time.Time, key *openpgp.Entity, packages []*artifact.Artifact) error {
+	root := filepath.Join(dir, "deb")
+	suite := repo.Apt.Suite
+	component := repo.Apt.Component
+
+	byArch := map[string][]*linuxpkg.Stanza{}
+	filenames := map[string]bool{}
+	for _, pkg := range packages {
+		deb, err := linuxpkg.ReadDeb(pkg.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", pkg.Name, err)
+		}
+		control := deb.Control
+		name := control.Get("Package")
+		filename := path.Join("pool", component, poolPrefix(name), name, pkg.Name)
+		if err := copyPackage(pkg.Path, filepath.Join(root, filepath.FromSlash(filename))); err != nil {
+			return err
+		}
+		if err := setChecksums(control, pkg.Path, filename); err != nil {
+			return err
+		}
+		filenames[filename] = true
+		arch := control.Get("Architecture")
+		byArch[arch] = append(byArch[arch], control)
+	}
+
+	if index != "" {
+		existing, err := existingDebs(filepath.Join(index, "deb"), suite, component)
+		if err != nil {
+			return err
+		}
+		for _, control := range existing {
+			if filenames[control.Get("Filename")] {
+				continue
+			}
+			log.WithField("package", control.Get("Filename")).Debug("keeping package from existing index")
+			arch := control.Get("Architecture")
+			byArch[arch] = append(byArch[arch], control)
+		}
+	}
+
+	archs := make([]string, 0, len(byArch))
+	for arch := range byArch {
+		if arch != "all" {
+			archs = append(archs, arch)
+		}
+	}
+	if len(archs) == 0 {
+		archs = append(archs, "all")
+	}
+	sort.Strings(archs)
+
+	suiteDir := filepath.Join(root, "dists", suite)
+	var indexes []string
+	for _, arch := range archs {
+		controls := byArch[arch]
+		if arch != "all" {
+			controls = append(controls, byArch["all"]...)
+		}
+		sort.Slice(controls, func(i, j int) bool {
+			return controls[i].Get("Filename") < controls[j].Get("Filename")
+		})
+		var buf bytes.Buffer
+		for i, control := range controls {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(control.String())
+		}
+		name := path.Join(component, "binary-"+arch, "Packages")
+		if err := writeFile(filepath.Join(suiteDir, filepath.FromSlash(name)), buf.Bytes()); err != nil {
+			return err
+		}
+		gz, err := gzipped(buf.Bytes(), date)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(suiteDir, filepath.FromSlash(name+".gz")), gz); err != nil {
+			return err
+		}
+		indexes = append(indexes, name, name+".gz")
+	}
+
+	release, err := releaseFile(repo, suiteDir, date, archs, indexes)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(suiteDir, "Release"), release); err != nil {
+		return err
+	}
+	if key == nil {
+		return nil
+	}
+	inRelease, err := clearSign(key, release)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(suiteDir, "InRelease"), inRelease); err != nil {
+		return err
+	}
+	signature, err := detachSign(key, release)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(suiteDir, "Release.gpg"), signature)
+}
+
+// poolPrefix is the pool folder of a package, which is the first letter of
+// its name, or the first 4 for libraries.
+func poolPrefix(name string) string {
+	if strings.HasPrefix(name, "lib") && len(name) > 3 {
+		return name[:4]
+	}
+	return name[:1]
+}
+
+// setChecksums adds the fields apt needs to find and verify the package to its
+// control file.
+func setChecksums(control *linuxpkg.Stanza, file, filename string) error {
+	md5h, sha1h, sha256h := md5.New(), sha1.New(), sha256.New() // #nosec
+	size, err := hashFile(file, md5h, sha1h, sha256h)
+	if err != nil {
+		return err
+	}
+	control.Set("Filename", filename)
+	control.Set("Size", fmt.Sprint(size))
+	control.Set("MD5sum", hex.EncodeToString(md5h.Sum(nil)))
+	control.Set("SHA1", hex.EncodeToString(sha1h.Sum(nil)))
+	control.Set("SHA256", hex.EncodeToString(sha256h.Sum(nil)))
+	return nil
+}
+
+// existingDebs reads the Packages indexes of an existing apt repository.
+func existingDebs(root, suite, component string) ([]*linuxpkg.Stanza, error) {
+	matches, err := filepath.Glob(filepath.Join(root, "dists", suite, component, "binary-*", "Packages"))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var result []*linuxpkg.Stanza
+	for _, match := range matches {
+		f, err := os.Open(match)
+		if err != nil {
+			return nil, err
+		}
+		stanzas, err := linuxpkg.ParseStanzas(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", match, err)
+		}
+		for _, s := range stanzas {
+			// packages for all architectures are listed in every index
+			if seen[s.Get("Filename")] {
+				continue
+			}
+			seen[s.Get("Filename")] = true
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// releaseFile creates the suite Release file, with the checksums of all the
+// given indexes.
+func releaseFile(repo config.LinuxRepo, suiteDir string, date time.Time, archs, indexes []string) ([]byte, error) {
+	release := linuxpkg.NewStanza()
+	if repo.Apt.Origin != "" {
+		release.Set("Origin", repo.Apt.Origin)
+	}
+	if repo.Apt.Label != "" {
+		release.Set("Label", repo.Apt.Label)
+	}
+	release.Set("Suite", repo.Apt.Suite)
+	release.Set("Codename", repo.Apt.Suite)
+	release.Set("Date", date.UTC().Format(time.RFC1123))
+	release.Set("Architectures", strings.Join(archs, " "))
+	release.Set("Components", repo.Apt.Component)
+	if repo.Apt.Description != "" {
+		release.Set("Description", repo.Apt.Description)
+	}
+
+	for _, sum := range []struct {
+		field string
+		hash  func() hash.Hash
+	}{
+		{"MD5Sum", md5.New}, // #nosec
+		{"SHA1", sha1.New},  // #nosec
+		{"SHA256", sha256.New},
+	} {
+		var value strings.Builder
+		for _, name := range indexes {
+			h := sum.hash()
+			size, err := hashFile(filepath.Join(suiteDir, filepath.FromSlash(name)), h)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&value, "\n %s %d %s", hex.EncodeToString(h.Sum(nil)), size, name)
+		}
+		release.Set(sum.field, value.String())
+	}
+	return []byte(release.String()), nil
+}
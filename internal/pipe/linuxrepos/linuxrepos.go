@@ -0,0 +1,233 @@
+// Package linuxrepos implements the Pipe interface creating apt and yum
+// repositories from the linux packages.
+package linuxrepos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/gio"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+// Pipe for linux repositories.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "linux repositories" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.LinuxRepos) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	ids := ids.New("linux_repos")
+	for i := range ctx.Config.LinuxRepos {
+		repo := &ctx.Config.LinuxRepos[i]
+		if repo.ID == "" {
+			repo.ID = "default"
+		}
+		if repo.NameTemplate == "" {
+			repo.NameTemplate = "{{ .ProjectName }}"
+		}
+		if repo.Apt.Suite == "" {
+			repo.Apt.Suite = "stable"
+		}
+		if repo.Apt.Component == "" {
+			repo.Apt.Component = "main"
+		}
+		if repo.Yum.Folder == "" {
+			repo.Yum.Folder = "Packages"
+		}
+		ids.Inc(repo.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	for _, repo := range ctx.Config.LinuxRepos {
+		if err := doRun(ctx, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, repo config.LinuxRepo) error {
+	filter := artifact.ByType(artifact.LinuxPackage)
+	if len(repo.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(repo.IDs...))
+	}
+	debs := ctx.Artifacts.Filter(artifact.And(filter, artifact.ByFormats("deb"))).List()
+	rpms := ctx.Artifacts.Filter(artifact.And(filter, artifact.ByFormats("rpm"))).List()
+	if len(debs) == 0 && len(rpms) == 0 {
+		return fmt.Errorf("no deb or rpm packages found for linux repository %s", repo.ID)
+	}
+
+	t := tmpl.New(ctx)
+	name, err := t.Apply(repo.NameTemplate)
+	if err != nil {
+		return err
+	}
+	index, err := t.Apply(repo.Index)
+	if err != nil {
+		return err
+	}
+	key, err := signingKey(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(ctx.Config.Dist, "linux_repos", repo.ID)
+	log := log.WithField("repository", dir)
+	if len(debs) > 0 {
+		log.WithField("packages", len(debs)).Info("creating apt repository")
+		if err := apt(repo, dir, index, ctx.Date, key, debs); err != nil {
+			return fmt.Errorf("failed to create apt repository: %w", err)
+		}
+	}
+	if len(rpms) > 0 {
+		log.WithField("packages", len(rpms)).Info("creating yum repository")
+		if err := yum(repo, dir, index, ctx.Date, key, rpms); err != nil {
+			return fmt.Errorf("failed to create yum repository: %w", err)
+		}
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.LinuxRepository,
+		Name: name,
+		Path: dir,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: repo.ID,
+		},
+	})
+	return nil
+}
+
+// signingKey loads the key used to sign the repository metadata, if any.
+//
+// The passphrase is taken from the environment variable
+// LINUX_REPO_<ID>_PASSPHRASE.
+func signingKey(ctx *context.Context, repo config.LinuxRepo) (*openpgp.Entity, error) {
+	if repo.Signature.KeyFile == "" || ctx.SkipSign {
+		return nil, nil
+	}
+	path, err := tmpl.New(ctx).Apply(repo.Signature.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(bts))
+	if err != nil {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(bts))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	key := entities[0]
+	if key.PrivateKey == nil {
+		return nil, fmt.Errorf("failed to read signing key: %s is not a private key", path)
+	}
+	if key.PrivateKey.Encrypted {
+		passphrase := []byte(ctx.Env[fmt.Sprintf("LINUX_REPO_%s_PASSPHRASE", strings.ToUpper(repo.ID))])
+		if err := key.PrivateKey.Decrypt(passphrase); err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+		}
+		for _, sub := range key.Subkeys {
+			if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+				if err := sub.PrivateKey.Decrypt(passphrase); err != nil {
+					return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+				}
+			}
+		}
+	}
+	return key, nil
+}
+
+// clearSign creates an inline signed copy of data, such as an InRelease file.
+func clearSign(key *openpgp.Entity, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := clearsign.Encode(&buf, key.PrivateKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// detachSign creates an armored detached signature of data.
+func detachSign(key *openpgp.Entity, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, key, bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func copyPackage(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return gio.Copy(src, dst)
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// gzipped compresses content, using the given modification time so the
+// output doesn't change between runs.
+func gzipped(content []byte, mtime time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	gw.ModTime = mtime
+	if _, err := gw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hashFile writes the contents of the file to all the given hashes, returning
+// its size.
+func hashFile(path string, hashes ...hash.Hash) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+	return io.Copy(io.MultiWriter(writers...), f)
+}
@@ -0,0 +1,412 @@
+package linuxrepos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2"
+	_ "github.com/goreleaser/nfpm/v2/deb" // register the deb packager
+	"github.com/goreleaser/nfpm/v2/files"
+	_ "github.com/goreleaser/nfpm/v2/rpm" // register the rpm packager
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+var date = time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		LinuxRepos: []config.LinuxRepo{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		LinuxRepos: []config.LinuxRepo{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.LinuxRepo{
+		ID:           "default",
+		NameTemplate: "{{ .ProjectName }}",
+		Apt:          config.LinuxRepoApt{Suite: "stable", Component: "main"},
+		Yum:          config.LinuxRepoYum{Folder: "Packages"},
+	}, ctx.Config.LinuxRepos[0])
+}
+
+func TestDefaultDuplicateIDs(t *testing.T) {
+	ctx := context.New(config.Project{
+		LinuxRepos: []config.LinuxRepo{{}, {}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 linux_repos with the ID 'default', please fix your config")
+}
+
+func TestRunPipe(t *testing.T) {
+	folder := t.TempDir()
+	ctx := newContext(t, folder, config.LinuxRepo{
+		Apt: config.LinuxRepoApt{
+			Origin:      "Foo",
+			Label:       "Foo",
+			Description: "Foo packages",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	repos := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxRepository)).List()
+	require.Len(t, repos, 1)
+	require.Equal(t, "foo", repos[0].Name)
+	require.Equal(t, filepath.Join(folder, "linux_repos", "default"), repos[0].Path)
+	require.Equal(t, "default", repos[0].ExtraOr(artifact.ExtraID, ""))
+
+	root := repos[0].Path
+	require.FileExists(t, filepath.Join(root, "deb", "pool", "main", "f", "foo", "foo_1.0.0_amd64.deb"))
+	require.FileExists(t, filepath.Join(root, "deb", "pool", "main", "f", "foo", "foo_1.0.0_arm64.deb"))
+	require.FileExists(t, filepath.Join(root, "rpm", "Packages", "foo-1.0.0.x86_64.rpm"))
+
+	packages := readFile(t, filepath.Join(root, "deb", "dists", "stable", "main", "binary-amd64", "Packages"))
+	require.Contains(t, packages, "Package: foo\n")
+	require.Contains(t, packages, "Architecture: amd64\n")
+	require.Contains(t, packages, "Filename: pool/main/f/foo/foo_1.0.0_amd64.deb\n")
+	require.Contains(t, packages, "SHA256: ")
+	require.NotContains(t, packages, "arm64")
+	require.Equal(t, packages, readGzip(t, filepath.Join(root, "deb", "dists", "stable", "main", "binary-amd64", "Packages.gz")))
+
+	release := readFile(t, filepath.Join(root, "deb", "dists", "stable", "Release"))
+	require.True(t, strings.HasPrefix(release, `Origin: Foo
+Label: Foo
+Suite: stable
+Codename: stable
+Date: Wed, 01 Dec 2021 10:00:00 UTC
+Architectures: amd64 arm64
+Components: main
+Description: Foo packages
+MD5Sum:
+`), release)
+	require.Contains(t, release, " main/binary-arm64/Packages.gz\n")
+	require.NoFileExists(t, filepath.Join(root, "deb", "dists", "stable", "InRelease"))
+	require.NoFileExists(t, filepath.Join(root, "deb", "dists", "stable", "Release.gpg"))
+
+	md := readRepomd(t, root)
+	require.Len(t, md.Data, 2)
+	require.Equal(t, "primary", md.Data[0].Type)
+	require.Equal(t, "filelists", md.Data[1].Type)
+	primary := readGzip(t, filepath.Join(root, "rpm", filepath.FromSlash(md.Data[0].Location.Href)))
+	require.Contains(t, primary, `packages="1"`)
+	require.Contains(t, primary, "<name>foo</name>")
+	require.Contains(t, primary, "<arch>x86_64</arch>")
+	require.Contains(t, primary, `<version epoch="0" ver="1.0.0" rel="1"></version>`)
+	require.Contains(t, primary, `<location href="Packages/foo-1.0.0.x86_64.rpm"></location>`)
+	require.Contains(t, primary, `<rpm:entry name="bash"></rpm:entry>`)
+	require.Contains(t, primary, "<file>/usr/bin/foo</file>")
+	require.NotContains(t, primary, "rpmlib(")
+	filelists := readGzip(t, filepath.Join(root, "rpm", filepath.FromSlash(md.Data[1].Location.Href)))
+	require.Contains(t, filelists, `name="foo" arch="x86_64"`)
+	require.Contains(t, filelists, "<file>/usr/bin/foo</file>")
+	require.Contains(t, filelists, "<file>/usr/share/foo/README</file>")
+	require.NoFileExists(t, filepath.Join(root, "rpm", "repodata", "repomd.xml.asc"))
+}
+
+func TestRunPipeSigned(t *testing.T) {
+	folder := t.TempDir()
+	ctx := newContext(t, folder, config.LinuxRepo{
+		ID: "signed",
+		Signature: config.LinuxRepoSignature{
+			KeyFile: "./testdata/privkey.gpg",
+		},
+	})
+	ctx.Env = map[string]string{
+		"LINUX_REPO_SIGNED_PASSPHRASE": "hunter2",
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	root := filepath.Join(folder, "linux_repos", "signed")
+	keyring := readKeyring(t)
+
+	suite := filepath.Join(root, "deb", "dists", "stable")
+	release, err := os.ReadFile(filepath.Join(suite, "Release"))
+	require.NoError(t, err)
+	checkSignature(t, keyring, release, filepath.Join(suite, "Release.gpg"))
+
+	inRelease, err := os.ReadFile(filepath.Join(suite, "InRelease"))
+	require.NoError(t, err)
+	block, _ := clearsign.Decode(inRelease)
+	require.NotNil(t, block)
+	require.Equal(t, release, block.Plaintext)
+	_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body)
+	require.NoError(t, err)
+
+	repomd, err := os.ReadFile(filepath.Join(root, "rpm", "repodata", "repomd.xml"))
+	require.NoError(t, err)
+	checkSignature(t, keyring, repomd, filepath.Join(root, "rpm", "repodata", "repomd.xml.asc"))
+}
+
+func TestRunPipeSkipSign(t *testing.T) {
+	folder := t.TempDir()
+	ctx := newContext(t, folder, config.LinuxRepo{
+		Signature: config.LinuxRepoSignature{
+			KeyFile: "./testdata/privkey.gpg",
+		},
+	})
+	ctx.SkipSign = true
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoFileExists(t, filepath.Join(folder, "linux_repos", "default", "deb", "dists", "stable", "InRelease"))
+}
+
+func TestRunPipeMergeIndex(t *testing.T) {
+	index := t.TempDir()
+	previous := newContext(t, index, config.LinuxRepo{})
+	previous.Version = "0.9.0"
+	previous.Artifacts = artifact.New()
+	addPackages(t, previous, index, "0.9.0")
+	require.NoError(t, Pipe{}.Default(previous))
+	require.NoError(t, Pipe{}.Run(previous))
+
+	folder := t.TempDir()
+	ctx := newContext(t, folder, config.LinuxRepo{
+		Index: filepath.Join(index, "linux_repos", "{{ .ProjectName }}"),
+	})
+	require.NoError(t, os.Rename(filepath.Join(index, "linux_repos", "default"), filepath.Join(index, "linux_repos", "foo")))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	root := filepath.Join(folder, "linux_repos", "default")
+	packages := readFile(t, filepath.Join(root, "deb", "dists", "stable", "main", "binary-amd64", "Packages"))
+	require.Contains(t, packages, "Filename: pool/main/f/foo/foo_0.9.0_amd64.deb\n")
+	require.Contains(t, packages, "Filename: pool/main/f/foo/foo_1.0.0_amd64.deb\n")
+	require.Less(t, strings.Index(packages, "foo_0.9.0"), strings.Index(packages, "foo_1.0.0"))
+
+	md := readRepomd(t, root)
+	primary := readGzip(t, filepath.Join(root, "rpm", filepath.FromSlash(md.Data[0].Location.Href)))
+	require.Contains(t, primary, `packages="2"`)
+	require.Contains(t, primary, `<location href="Packages/foo-0.9.0.x86_64.rpm"></location>`)
+	require.Contains(t, primary, `<location href="Packages/foo-1.0.0.x86_64.rpm"></location>`)
+	filelists := readGzip(t, filepath.Join(root, "rpm", filepath.FromSlash(md.Data[1].Location.Href)))
+	require.Contains(t, filelists, `packages="2"`)
+	require.Contains(t, filelists, `ver="0.9.0"`)
+
+	// running again against itself must not duplicate packages
+	ctx = newContext(t, t.TempDir(), config.LinuxRepo{Index: root})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	packages = readFile(t, filepath.Join(ctx.Config.Dist, "linux_repos", "default", "deb", "dists", "stable", "main", "binary-amd64", "Packages"))
+	require.Equal(t, 1, strings.Count(packages, "foo_1.0.0_amd64.deb\n"))
+	require.Equal(t, 1, strings.Count(packages, "foo_0.9.0_amd64.deb\n"))
+}
+
+func TestRunPipeFilterIDs(t *testing.T) {
+	ctx := newContext(t, t.TempDir(), config.LinuxRepo{
+		IDs: []string{"nope"},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.EqualError(t, Pipe{}.Run(ctx), "no deb or rpm packages found for linux repository default")
+}
+
+func TestRunPipeOnlyRPMs(t *testing.T) {
+	folder := t.TempDir()
+	ctx := newContext(t, folder, config.LinuxRepo{})
+	ctx.Artifacts = ctx.Artifacts.Filter(artifact.ByFormats("rpm"))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoDirExists(t, filepath.Join(folder, "linux_repos", "default", "deb"))
+	require.FileExists(t, filepath.Join(folder, "linux_repos", "default", "rpm", "repodata", "repomd.xml"))
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		repo     config.LinuxRepo
+		env      map[string]string
+		expected string
+	}{
+		"name template": {
+			repo:     config.LinuxRepo{NameTemplate: "{{ .Nope }"},
+			expected: `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"index template": {
+			repo:     config.LinuxRepo{Index: "{{ .Nope }"},
+			expected: `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"missing key": {
+			repo:     config.LinuxRepo{Signature: config.LinuxRepoSignature{KeyFile: "testdata/nope.gpg"}},
+			expected: "failed to read signing key: open testdata/nope.gpg: no such file or directory",
+		},
+		"wrong passphrase": {
+			repo:     config.LinuxRepo{Signature: config.LinuxRepoSignature{KeyFile: "testdata/privkey.gpg"}},
+			env:      map[string]string{"LINUX_REPO_DEFAULT_PASSPHRASE": "nope"},
+			expected: "failed to decrypt signing key: openpgp: invalid data: private key checksum failure",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := newContext(t, t.TempDir(), tt.repo)
+			ctx.Env = tt.env
+			require.NoError(t, Pipe{}.Default(ctx))
+			err := Pipe{}.Run(ctx)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestRunPipeInvalidPackage(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		LinuxRepos:  []config.LinuxRepo{{}},
+	})
+	bad := filepath.Join(folder, "foo.rpm")
+	require.NoError(t, os.WriteFile(bad, []byte("not an rpm"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.rpm",
+		Path: bad,
+		Type: artifact.LinuxPackage,
+		Extra: map[string]interface{}{
+			artifact.ExtraFormat: "rpm",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read foo.rpm: not a valid rpm package")
+}
+
+func TestPoolPrefix(t *testing.T) {
+	require.Equal(t, "f", poolPrefix("foo"))
+	require.Equal(t, "libf", poolPrefix("libfoo"))
+	require.Equal(t, "l", poolPrefix("lib"))
+}
+
+func newContext(tb testing.TB, folder string, repo config.LinuxRepo) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        folder,
+		LinuxRepos:  []config.LinuxRepo{repo},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Date = date
+	addPackages(tb, ctx, folder, "1.0.0")
+	return ctx
+}
+
+// addPackages creates deb packages for amd64 and arm64, and an rpm package
+// for amd64.
+func addPackages(tb testing.TB, ctx *context.Context, folder, version string) {
+	tb.Helper()
+	readme := filepath.Join(folder, "README")
+	require.NoError(tb, os.WriteFile(readme, []byte("foo"), 0o644))
+	for _, pkg := range []struct {
+		format string
+		arch   string
+	}{
+		{"deb", "amd64"},
+		{"deb", "arm64"},
+		{"rpm", "amd64"},
+	} {
+		packager, err := nfpm.Get(pkg.format)
+		require.NoError(tb, err)
+		info := nfpm.WithDefaults(&nfpm.Info{
+			Name:        "foo",
+			Arch:        pkg.arch,
+			Platform:    "linux",
+			Version:     version,
+			Maintainer:  "Foo <foo@bar.com>",
+			Description: "foo does things",
+			Homepage:    "https://foo.bar",
+			License:     "MIT",
+			Overridables: nfpm.Overridables{
+				Depends: []string{"bash"},
+				Contents: files.Contents{
+					{Source: readme, Destination: "/usr/bin/foo", FileInfo: &files.ContentFileInfo{MTime: date, Mode: 0o755}},
+					{Source: readme, Destination: "/usr/share/foo/README", FileInfo: &files.ContentFileInfo{MTime: date}},
+				},
+			},
+		})
+		require.NoError(tb, nfpm.Validate(info))
+		name := packager.ConventionalFileName(info)
+		path := filepath.Join(folder, name)
+		f, err := os.Create(path)
+		require.NoError(tb, err)
+		require.NoError(tb, packager.Package(info, f))
+		require.NoError(tb, f.Close())
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   name,
+			Path:   path,
+			Goos:   "linux",
+			Goarch: pkg.arch,
+			Type:   artifact.LinuxPackage,
+			Extra: map[string]interface{}{
+				artifact.ExtraID:     "foo",
+				artifact.ExtraFormat: pkg.format,
+			},
+		})
+	}
+}
+
+func readFile(tb testing.TB, path string) string {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	return string(bts)
+}
+
+func readGzip(tb testing.TB, path string) string {
+	tb.Helper()
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(tb, err)
+	bts, err := io.ReadAll(gr)
+	require.NoError(tb, err)
+	return string(bts)
+}
+
+func readRepomd(tb testing.TB, root string) repomd {
+	tb.Helper()
+	var md repomd
+	require.NoError(tb, xml.Unmarshal([]byte(readFile(tb, filepath.Join(root, "rpm", "repodata", "repomd.xml"))), &md))
+	return md
+}
+
+func readKeyring(tb testing.TB) openpgp.EntityList {
+	tb.Helper()
+	f, err := os.Open("testdata/privkey.gpg")
+	require.NoError(tb, err)
+	defer f.Close()
+	keyring, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		_, err = f.Seek(0, io.SeekStart)
+		require.NoError(tb, err)
+		keyring, err = openpgp.ReadKeyRing(f)
+	}
+	require.NoError(tb, err)
+	return keyring
+}
+
+func checkSignature(tb testing.TB, keyring openpgp.EntityList, content []byte, signature string) {
+	tb.Helper()
+	sig, err := os.Open(signature)
+	require.NoError(tb, err)
+	defer sig.Close()
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(content), sig)
+	require.NoError(tb, err)
+}
@@ -0,0 +1,453 @@
+package linuxrepos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/linuxpkg"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"golang.org/x/crypto/openpgp"
+)
+
+const (
+	xmlnsCommon    = "http://linux.duke.edu/metadata/common"
+	xmlnsRPM       = "http://linux.duke.edu/metadata/rpm"
+	xmlnsFilelists = "http://linux.duke.edu/metadata/filelists"
+	xmlnsRepo      = "http://linux.duke.edu/metadata/repo"
+)
+
+type yumVersion struct {
+	Epoch string `xml:"epoch,attr"`
+	Ver   string `xml:"ver,attr"`
+	Rel   string `xml:"rel,attr"`
+}
+
+type yumChecksum struct {
+	Type  string `xml:"type,attr"`
+	PkgID string `xml:"pkgid,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type yumLocation struct {
+	Href string `xml:"href,attr"`
+}
+
+type yumEntry struct {
+	Name  string `xml:"name,attr"`
+	Flags string `xml:"flags,attr,omitempty"`
+	Epoch string `xml:"epoch,attr,omitempty"`
+	Ver   string `xml:"ver,attr,omitempty"`
+	Rel   string `xml:"rel,attr,omitempty"`
+}
+
+type yumEntries struct {
+	Entries []yumEntry `xml:"rpm:entry"`
+}
+
+type yumFile struct {
+	Type string `xml:"type,attr,omitempty"`
+	Path string `xml:",chardata"`
+}
+
+// yumPackage is a package entry of the primary.xml file.
+type yumPackage struct {
+	XMLName     xml.Name    `xml:"package"`
+	Type        string      `xml:"type,attr"`
+	Name        string      `xml:"name"`
+	Arch        string      `xml:"arch"`
+	Version     yumVersion  `xml:"version"`
+	Checksum    yumChecksum `xml:"checksum"`
+	Summary     string      `xml:"summary"`
+	Description string      `xml:"description"`
+	Packager    string      `xml:"packager"`
+	URL         string      `xml:"url"`
+	Time        struct {
+		File  int64 `xml:"file,attr"`
+		Build int64 `xml:"build,attr"`
+	} `xml:"time"`
+	Size struct {
+		Package   int64 `xml:"package,attr"`
+		Installed int64 `xml:"installed,attr"`
+		Archive   int64 `xml:"archive,attr"`
+	} `xml:"size"`
+	Location yumLocation `xml:"location"`
+	Format   struct {
+		License     string `xml:"rpm:license"`
+		Vendor      string `xml:"rpm:vendor"`
+		Group       string `xml:"rpm:group"`
+		BuildHost   string `xml:"rpm:buildhost"`
+		SourceRPM   string `xml:"rpm:sourcerpm"`
+		HeaderRange struct {
+			Start int64 `xml:"start,attr"`
+			End   int64 `xml:"end,attr"`
+		} `xml:"rpm:header-range"`
+		Provides  *yumEntries `xml:"rpm:provides,omitempty"`
+		Requires  *yumEntries `xml:"rpm:requires,omitempty"`
+		Conflicts *yumEntries `xml:"rpm:conflicts,omitempty"`
+		Obsoletes *yumEntries `xml:"rpm:obsoletes,omitempty"`
+		Files     []yumFile   `xml:"file"`
+	} `xml:"format"`
+}
+
+// yumFilelist is a package entry of the filelists.xml file.
+type yumFilelist struct {
+	XMLName xml.Name   `xml:"package"`
+	PkgID   string     `xml:"pkgid,attr"`
+	Name    string     `xml:"name,attr"`
+	Arch    string     `xml:"arch,attr"`
+	Version yumVersion `xml:"version"`
+	Files   []yumFile  `xml:"file"`
+}
+
+type repomdData struct {
+	Type         string      `xml:"type,attr"`
+	Checksum     yumChecksum `xml:"checksum"`
+	OpenChecksum yumChecksum `xml:"open-checksum"`
+	Location     yumLocation `xml:"location"`
+	Timestamp    int64       `xml:"timestamp"`
+	Size         int64       `xml:"size"`
+	OpenSize     int64       `xml:"open-size"`
+}
+
+type repomd struct {
+	XMLName  xml.Name     `xml:"repomd"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	XmlnsRPM string       `xml:"xmlns:rpm,attr"`
+	Revision int64        `xml:"revision"`
+	Data     []repomdData `xml:"data"`
+}
+
+// rawPackage is a package entry of an existing primary.xml or filelists.xml,
+// which is kept as is.
+type rawPackage struct {
+	Attrs    []xml.Attr  `xml:",any,attr"`
+	Checksum string      `xml:"checksum"`
+	Location yumLocation `xml:"location"`
+	Inner    string      `xml:",innerxml"`
+}
+
+func (p rawPackage) attr(name string) string {
+	for _, attr := range p.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (p rawPackage) String() string {
+	var b strings.Builder
+	b.WriteString("<package")
+	for _, attr := range p.Attrs {
+		b.WriteString(" " + attr.Name.Local + `="`)
+		_ = xml.EscapeText(&b, []byte(attr.Value))
+		b.WriteString(`"`)
+	}
+	b.WriteString(">" + p.Inner + "</package>")
+	return b.String()
+}
+
+// yum creates a yum repository from the given rpm packages, at dir/rpm.
+func yum(repo config.LinuxRepo, dir, index string, date time.Time, key *openpgp.Entity, packages []*artifact.Artifact) error {
+	root := filepath.Join(dir, "rpm")
+
+	var primary, filelists []interface{}
+	hrefs := map[string]bool{}
+	for _, pkg := range packages {
+		href := path.Join(repo.Yum.Folder, pkg.Name)
+		if err := copyPackage(pkg.Path, filepath.Join(root, filepath.FromSlash(href))); err != nil {
+			return err
+		}
+		p, fl, err := readYumPackage(pkg.Path, href)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", pkg.Name, err)
+		}
+		hrefs[href] = true
+		primary = append(primary, p)
+		filelists = append(filelists, fl)
+	}
+
+	if index != "" {
+		existingPrimary, existingFilelists, err := existingRPMs(filepath.Join(index, "rpm"), hrefs)
+		if err != nil {
+			return err
+		}
+		primary = append(primary, existingPrimary...)
+		filelists = append(filelists, existingFilelists...)
+	}
+
+	md := repomd{
+		Xmlns:    xmlnsRepo,
+		XmlnsRPM: xmlnsRPM,
+		Revision: date.Unix(),
+	}
+	for _, data := range []struct {
+		name     string
+		root     string
+		xmlns    string
+		packages []interface{}
+	}{
+		{"primary", "metadata", fmt.Sprintf(`xmlns=%q xmlns:rpm=%q`, xmlnsCommon, xmlnsRPM), primary},
+		{"filelists", "filelists", fmt.Sprintf(`xmlns=%q`, xmlnsFilelists), filelists},
+	} {
+		content, err := yumDocument(data.root, data.xmlns, data.packages)
+		if err != nil {
+			return err
+		}
+		gz, err := gzipped(content, date)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(gz)
+		openSum := sha256.Sum256(content)
+		href := path.Join("repodata", hex.EncodeToString(sum[:])+"-"+data.name+".xml.gz")
+		if err := writeFile(filepath.Join(root, filepath.FromSlash(href)), gz); err != nil {
+			return err
+		}
+		md.Data = append(md.Data, repomdData{
+			Type:         data.name,
+			Checksum:     yumChecksum{Type: "sha256", Value: hex.EncodeToString(sum[:])},
+			OpenChecksum: yumChecksum{Type: "sha256", Value: hex.EncodeToString(openSum[:])},
+			Location:     yumLocation{Href: href},
+			Timestamp:    date.Unix(),
+			Size:         int64(len(gz)),
+			OpenSize:     int64(len(content)),
+		})
+	}
+
+	bts, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return err
+	}
+	bts = append([]byte(xml.Header), append(bts, '\n')...)
+	if err := writeFile(filepath.Join(root, "repodata", "repomd.xml"), bts); err != nil {
+		return err
+	}
+	if key == nil {
+		return nil
+	}
+	signature, err := detachSign(key, bts)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(root, "repodata", "repomd.xml.asc"), signature)
+}
+
+// yumDocument renders a primary.xml or filelists.xml document, with the
+// packages sorted by location.
+func yumDocument(root, xmlns string, packages []interface{}) ([]byte, error) {
+	entries := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		if raw, ok := pkg.(rawPackage); ok {
+			entries = append(entries, raw.String())
+			continue
+		}
+		bts, err := xml.Marshal(pkg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, string(bts))
+	}
+	sort.Strings(entries)
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<%s %s packages=\"%d\">\n", root, xmlns, len(entries))
+	for _, entry := range entries {
+		b.WriteString(entry + "\n")
+	}
+	fmt.Fprintf(&b, "</%s>\n", root)
+	return b.Bytes(), nil
+}
+
+// readYumPackage creates the primary and filelists entries of an rpm
+// package.
+func readYumPackage(file, href string) (yumPackage, yumFilelist, error) {
+	var p yumPackage
+	var fl yumFilelist
+	f, err := os.Open(file)
+	if err != nil {
+		return p, fl, err
+	}
+	defer f.Close()
+	sig, hdr, err := linuxpkg.ReadRPM(f)
+	if err != nil {
+		return p, fl, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return p, fl, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return p, fl, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return p, fl, err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	version := yumVersion{
+		Epoch: fmt.Sprint(hdr.GetInt(linuxpkg.TagEpoch)),
+		Ver:   hdr.GetString(linuxpkg.TagVersion),
+		Rel:   hdr.GetString(linuxpkg.TagRelease),
+	}
+	p.Type = "rpm"
+	p.Name = hdr.GetString(linuxpkg.TagName)
+	p.Arch = hdr.GetString(linuxpkg.TagArch)
+	p.Version = version
+	p.Checksum = yumChecksum{Type: "sha256", PkgID: "YES", Value: sum}
+	p.Summary = hdr.GetString(linuxpkg.TagSummary)
+	p.Description = hdr.GetString(linuxpkg.TagDescription)
+	p.Packager = hdr.GetString(linuxpkg.TagPackager)
+	p.URL = hdr.GetString(linuxpkg.TagURL)
+	p.Time.File = stat.ModTime().Unix()
+	p.Time.Build = hdr.GetInt(linuxpkg.TagBuildTime)
+	p.Size.Package = size
+	p.Size.Installed = hdr.GetInt(linuxpkg.TagSize)
+	p.Size.Archive = sig.GetInt(linuxpkg.SigTagPayloadSize)
+	p.Location.Href = href
+	p.Format.License = hdr.GetString(linuxpkg.TagLicense)
+	p.Format.Vendor = hdr.GetString(linuxpkg.TagVendor)
+	p.Format.Group = hdr.GetString(linuxpkg.TagGroup)
+	p.Format.BuildHost = hdr.GetString(linuxpkg.TagBuildHost)
+	p.Format.SourceRPM = hdr.GetString(linuxpkg.TagSourceRPM)
+	p.Format.HeaderRange.Start = hdr.Start
+	p.Format.HeaderRange.End = hdr.End
+	p.Format.Provides = yumDependencies(hdr.Dependencies(linuxpkg.TagProvideName, linuxpkg.TagProvideFlags, linuxpkg.TagProvideVersion))
+	p.Format.Requires = yumDependencies(hdr.Dependencies(linuxpkg.TagRequireName, linuxpkg.TagRequireFlags, linuxpkg.TagRequireVersion))
+	p.Format.Conflicts = yumDependencies(hdr.Dependencies(linuxpkg.TagConflictName, linuxpkg.TagConflictFlags, linuxpkg.TagConflictVersion))
+	p.Format.Obsoletes = yumDependencies(hdr.Dependencies(linuxpkg.TagObsoleteName, linuxpkg.TagObsoleteFlags, linuxpkg.TagObsoleteVersion))
+
+	fl.PkgID = sum
+	fl.Name = p.Name
+	fl.Arch = p.Arch
+	fl.Version = version
+	for _, file := range hdr.Files() {
+		yf := yumFile{Path: file.Path}
+		if file.Mode.IsDir() {
+			yf.Type = "dir"
+		}
+		fl.Files = append(fl.Files, yf)
+		if isPrimaryFile(file.Path) {
+			p.Format.Files = append(p.Format.Files, yf)
+		}
+	}
+	return p, fl, nil
+}
+
+// isPrimaryFile tells whether a file is listed in primary.xml besides
+// filelists.xml, the same way createrepo does.
+func isPrimaryFile(name string) bool {
+	return strings.HasPrefix(name, "/etc/") ||
+		strings.Contains(name, "bin/") ||
+		name == "/usr/lib/sendmail"
+}
+
+func yumDependencies(deps []linuxpkg.RPMDependency) *yumEntries {
+	if len(deps) == 0 {
+		return nil
+	}
+	entries := &yumEntries{}
+	for _, dep := range deps {
+		entries.Entries = append(entries.Entries, yumEntry{
+			Name:  dep.Name,
+			Flags: dep.Flags,
+			Epoch: dep.Epoch,
+			Ver:   dep.Version,
+			Rel:   dep.Release,
+		})
+	}
+	return entries
+}
+
+// existingRPMs reads the primary and filelists entries of an existing yum
+// repository, except for the packages at the given locations.
+func existingRPMs(root string, hrefs map[string]bool) ([]interface{}, []interface{}, error) {
+	bts, err := os.ReadFile(filepath.Join(root, "repodata", "repomd.xml"))
+	if errors.Is(err, os.ErrNotExist) {
+		// the index has no yum repository yet
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var md repomd
+	if err := xml.Unmarshal(bts, &md); err != nil {
+		return nil, nil, fmt.Errorf("failed to read repomd.xml: %w", err)
+	}
+
+	var primary, filelists []interface{}
+	kept := map[string]bool{}
+	for _, data := range md.Data {
+		if data.Type != "primary" {
+			continue
+		}
+		packages, err := readRawPackages(filepath.Join(root, filepath.FromSlash(data.Location.Href)))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, pkg := range packages {
+			if hrefs[pkg.Location.Href] {
+				continue
+			}
+			log.WithField("package", pkg.Location.Href).Debug("keeping package from existing index")
+			kept[strings.TrimSpace(pkg.Checksum)] = true
+			primary = append(primary, pkg)
+		}
+	}
+	for _, data := range md.Data {
+		if data.Type != "filelists" {
+			continue
+		}
+		packages, err := readRawPackages(filepath.Join(root, filepath.FromSlash(data.Location.Href)))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, pkg := range packages {
+			if kept[pkg.attr("pkgid")] {
+				filelists = append(filelists, pkg)
+			}
+		}
+	}
+	return primary, filelists, nil
+}
+
+func readRawPackages(file string) ([]rawPackage, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+	var doc struct {
+		Packages []rawPackage `xml:"package"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return doc.Packages, nil
+}
@@ -0,0 +1,95 @@
+package nfpm
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/chglog"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+var commitRe = regexp.MustCompile(`^[0-9a-f]{7,40}:?$`)
+
+// writeChangelog creates the chglog file nfpm uses to embed the changelog in
+// the deb and rpm packages, returning its path.
+//
+// It contains the entries of the maintained changelog file, if any, and an
+// entry for the current version created from the release notes.
+func writeChangelog(ctx *context.Context, fpm config.NFPM) (string, error) {
+	var entries chglog.ChangeLogEntries
+	if fpm.Changelog.File != "" {
+		file, err := tmpl.New(ctx).Apply(fpm.Changelog.File)
+		if err != nil {
+			return "", err
+		}
+		entries, err = chglog.Parse(file)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !hasEntry(entries, ctx.Version) {
+		entries = append(entries, &chglog.ChangeLog{
+			ChangeLogOverridables: chglog.ChangeLogOverridables{
+				Deb: &chglog.ChangelogDeb{
+					Urgency:       "low",
+					Distributions: []string{"stable"},
+				},
+			},
+			Semver:   ctx.Version,
+			Date:     ctx.Date.UTC(),
+			Packager: fpm.Maintainer,
+			Changes:  changelogChanges(ctx),
+		})
+	}
+	sort.Sort(sort.Reverse(entries))
+
+	path := filepath.Join(ctx.Config.Dist, fpm.ID+".changelog.yml")
+	if err := entries.Save(path); err != nil {
+		return "", fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return path, nil
+}
+
+func hasEntry(entries chglog.ChangeLogEntries, version string) bool {
+	for _, entry := range entries {
+		if strings.TrimPrefix(entry.Semver, "v") == version {
+			return true
+		}
+	}
+	return false
+}
+
+// changelogChanges extracts the list items of the release notes, splitting
+// the commit from the message when there is one.
+func changelogChanges(ctx *context.Context) chglog.ChangeLogChanges {
+	var changes chglog.ChangeLogChanges
+	for _, line := range strings.Split(ctx.ReleaseNotes, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "* ") && !strings.HasPrefix(line, "- ") {
+			continue
+		}
+		change := &chglog.ChangeLogChange{
+			Note: strings.TrimSpace(line[2:]),
+		}
+		if parts := strings.SplitN(change.Note, " ", 2); len(parts) == 2 && commitRe.MatchString(parts[0]) {
+			change.Commit = strings.TrimSuffix(parts[0], ":")
+			change.Note = strings.TrimSpace(parts[1])
+		}
+		if change.Note == "" {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		changes = append(changes, &chglog.ChangeLogChange{
+			Note: "Release " + ctx.Version,
+		})
+	}
+	return changes
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/archlinux"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/ids"
@@ -54,6 +55,15 @@ func (Pipe) Default(ctx *context.Context) error {
 		if fpm.FileNameTemplate == "" {
 			fpm.FileNameTemplate = defaultNameTemplate
 		}
+		for j := range fpm.Systemd {
+			service := &fpm.Systemd[j]
+			if service.Name == "" {
+				service.Name = fpm.PackageName
+			}
+			if service.Restart == "" {
+				service.Restart = "on-failure"
+			}
+		}
 		if len(fpm.Builds) == 0 { // TODO: change this to empty by default and deal with it in the filtering code
 			for _, b := range ctx.Config.Builds {
 				fpm.Builds = append(fpm.Builds, b.ID)
@@ -89,13 +99,21 @@ func doRun(ctx *context.Context, fpm config.NFPM) error {
 	if len(linuxBinaries) == 0 {
 		return fmt.Errorf("no linux binaries found for builds %v", fpm.Builds)
 	}
+	var changelog string
+	if fpm.Changelog.Enabled {
+		path, err := writeChangelog(ctx, fpm)
+		if err != nil {
+			return err
+		}
+		changelog = path
+	}
 	g := semerrgroup.New(ctx.Parallelism)
 	for _, format := range fpm.Formats {
 		for _, artifacts := range linuxBinaries {
 			format := format
 			artifacts := artifacts
 			g.Go(func() error {
-				return create(ctx, fpm, format, artifacts)
+				return create(ctx, fpm, format, changelog, artifacts)
 			})
 		}
 	}
@@ -117,7 +135,7 @@ func mergeOverrides(fpm config.NFPM, format string) (*config.NFPMOverridables, e
 	return &overridden, nil
 }
 
-func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*artifact.Artifact) error {
+func create(ctx *context.Context, fpm config.NFPM, format, changelog string, binaries []*artifact.Artifact) error {
 	arch := binaries[0].Goarch + binaries[0].Goarm + binaries[0].Gomips
 
 	overridden, err := mergeOverrides(fpm, format)
@@ -162,6 +180,23 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		return err
 	}
 
+	var templateDir string
+	tempDir := func() (string, error) {
+		if templateDir != "" {
+			return templateDir, nil
+		}
+		dir, err := os.MkdirTemp("", "goreleaser-nfpm-")
+		if err != nil {
+			return "", err
+		}
+		templateDir = dir
+		return dir, nil
+	}
+	defer func() {
+		if templateDir != "" {
+			os.RemoveAll(templateDir)
+		}
+	}()
 	contents := files.Contents{}
 	for _, content := range overridden.Contents {
 		src, err := t.Apply(content.Source)
@@ -172,13 +207,29 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		if err != nil {
 			return err
 		}
-		contents = append(contents, &files.Content{
+		file := &files.Content{
 			Source:      src,
 			Destination: dst,
 			Type:        content.Type,
 			Packager:    content.Packager,
 			FileInfo:    content.FileInfo,
-		})
+		}
+		if !content.Template {
+			contents = append(contents, file)
+			continue
+		}
+		if err := checkTemplatable(file); err != nil {
+			return err
+		}
+		dir, err := tempDir()
+		if err != nil {
+			return err
+		}
+		rendered, err := templatedContents(t, file, dir)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, rendered...)
 	}
 
 	log := log.WithField("package", fpm.PackageName).WithField("format", format).WithField("arch", arch)
@@ -196,6 +247,26 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		}
 	}
 
+	var services []service
+	if len(fpm.Systemd) > 0 {
+		var defaultBinary string
+		if !fpm.Meta {
+			defaultBinary = binaries[0].Name
+		}
+		services, err = systemdServices(t, fpm, description, binDir, defaultBinary)
+		if err != nil {
+			return err
+		}
+		dir, err := tempDir()
+		if err != nil {
+			return err
+		}
+		contents, err = addSystemdUnits(services, dir, contents)
+		if err != nil {
+			return err
+		}
+	}
+
 	log.WithField("files", destinations(contents)).Debug("all archive files")
 
 	info := &nfpm.Info{
@@ -214,8 +285,10 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		Vendor:          fpm.Vendor,
 		Homepage:        homepage,
 		License:         fpm.License,
+		Changelog:       changelog,
 		Overridables: nfpm.Overridables{
 			Conflicts:    overridden.Conflicts,
+			Provides:     overridden.Provides,
 			Depends:      overridden.Dependencies,
 			Recommends:   overridden.Recommends,
 			Suggests:     overridden.Suggests,
@@ -281,6 +354,16 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 		},
 	}
 
+	if len(services) > 0 {
+		dir, err := tempDir()
+		if err != nil {
+			return err
+		}
+		if err := addSystemdScripts(services, format, dir, info); err != nil {
+			return err
+		}
+	}
+
 	if ctx.SkipSign {
 		info.APK.Signature = nfpm.APKSignature{}
 		info.RPM.Signature = nfpm.RPMSignature{}
@@ -303,8 +386,12 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 	if err != nil {
 		return err
 	}
-	if !strings.HasSuffix(name, "."+format) {
-		name = name + "." + format
+	ext := "." + format
+	if format == "archlinux" {
+		ext = archlinux.Extension
+	}
+	if !strings.HasSuffix(name, ext) {
+		name = name + ext
 	}
 
 	path := filepath.Join(ctx.Config.Dist, name)
@@ -337,6 +424,44 @@ func create(ctx *context.Context, fpm config.NFPM, format string, binaries []*ar
 	return nil
 }
 
+// checkTemplatable checks that the given content is backed by actual files.
+func checkTemplatable(content *files.Content) error {
+	switch content.Type {
+	case "ghost", "symlink", "dir":
+		return fmt.Errorf("%s: template is not supported for contents of type %s", content.Destination, content.Type)
+	}
+	return nil
+}
+
+// templatedContents renders the files matched by the given content, which
+// might be a glob, into dir, and returns them as contents of the package.
+func templatedContents(t *tmpl.Template, content *files.Content, dir string) (files.Contents, error) {
+	// the file info is left out of the expansion, so its defaults, the size
+	// in particular, are taken from the rendered files later on.
+	expanded, err := files.ExpandContentGlobs(files.Contents{{
+		Source:      content.Source,
+		Destination: content.Destination,
+		Type:        content.Type,
+		Packager:    content.Packager,
+	}}, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range expanded {
+		rendered := filepath.Join(dir, "contents", file.Packager, file.Destination)
+		if err := t.ApplyFile(file.Source, rendered); err != nil {
+			return nil, err
+		}
+		file.Source = rendered
+		file.FileInfo = nil
+		if content.FileInfo != nil {
+			info := *content.FileInfo
+			file.FileInfo = &info
+		}
+	}
+	return expanded, nil
+}
+
 func destinations(contents files.Contents) []string {
 	result := make([]string, 0, len(contents))
 	for _, f := range contents {
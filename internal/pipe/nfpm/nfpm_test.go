@@ -1,15 +1,25 @@
 package nfpm
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/goreleaser/chglog"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2"
 	"github.com/goreleaser/nfpm/v2/files"
 	"github.com/stretchr/testify/require"
 )
@@ -108,10 +118,11 @@ func TestRunPipe(t *testing.T) {
 					Suggests:         []string{"bzr"},
 					Replaces:         []string{"fish"},
 					Conflicts:        []string{"git"},
+					Provides:         []string{"foo-bin"},
 					EmptyFolders:     []string{"/var/log/foobar"},
 					Release:          "10",
 					Epoch:            "20",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "./testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -209,7 +220,7 @@ func TestRunPipeConventionalNameTemplate(t *testing.T) {
 			{
 				ID:          "someid",
 				Builds:      []string{"default"},
-				Formats:     []string{"deb", "rpm", "apk"},
+				Formats:     []string{"deb", "rpm", "apk", "archlinux"},
 				Section:     "somesection",
 				Priority:    "standard",
 				Description: "Some description ",
@@ -243,7 +254,7 @@ func TestRunPipeConventionalNameTemplate(t *testing.T) {
 	}
 	require.NoError(t, Pipe{}.Run(ctx))
 	packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
-	require.Len(t, packages, 6)
+	require.Len(t, packages, 8)
 	for _, pkg := range packages {
 		format := pkg.Format()
 		require.NotEmpty(t, format)
@@ -254,6 +265,8 @@ func TestRunPipeConventionalNameTemplate(t *testing.T) {
 			"foo_1.0.0_x86_64.apk",
 			"foo-1.0.0.i386.rpm",
 			"foo-1.0.0.x86_64.rpm",
+			"foo-1.0.0-1-i686.pkg.tar.zst",
+			"foo-1.0.0-1-x86_64.pkg.tar.zst",
 		}, pkg.Name, "package name is not expected")
 		require.Equal(t, "someid", pkg.ID())
 		require.ElementsMatch(t, []string{binPath}, sources(pkg.ExtraOr(extraFiles, files.Contents{}).(files.Contents)))
@@ -302,7 +315,7 @@ func TestInvalidTemplate(t *testing.T) {
 	t.Run("source", func(t *testing.T) {
 		ctx := makeCtx()
 		ctx.Config.NFPMs[0].NFPMOverridables = config.NFPMOverridables{
-			Contents: files.Contents{
+			Contents: config.NFPMContents{
 				{
 					Source:      "{{ .NOPE_SOURCE }}",
 					Destination: "/foo",
@@ -315,7 +328,7 @@ func TestInvalidTemplate(t *testing.T) {
 	t.Run("target", func(t *testing.T) {
 		ctx := makeCtx()
 		ctx.Config.NFPMs[0].NFPMOverridables = config.NFPMOverridables{
-			Contents: files.Contents{
+			Contents: config.NFPMContents{
 				{
 					Source:      "./testdata/testfile.txt",
 					Destination: "{{ .NOPE_TARGET }}",
@@ -371,7 +384,7 @@ func TestRunPipeInvalidContentsSourceTemplate(t *testing.T) {
 				{
 					NFPMOverridables: config.NFPMOverridables{
 						PackageName: "foo",
-						Contents: []*files.Content{
+						Contents: []*config.NFPMContent{
 							{
 								Source:      "{{.asdsd}",
 								Destination: "testfile",
@@ -435,7 +448,7 @@ func TestCreateFileDoesntExist(t *testing.T) {
 				Builds:  []string{"default"},
 				NFPMOverridables: config.NFPMOverridables{
 					PackageName: "foo",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/var/lib/test/testfile.txt",
@@ -582,7 +595,7 @@ func TestDebSpecificConfig(t *testing.T) {
 				Formats: []string{"deb"},
 				NFPMOverridables: config.NFPMOverridables{
 					PackageName: "foo",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -656,7 +669,7 @@ func TestRPMSpecificConfig(t *testing.T) {
 				Formats: []string{"rpm"},
 				NFPMOverridables: config.NFPMOverridables{
 					PackageName: "foo",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -803,7 +816,7 @@ func TestAPKSpecificConfig(t *testing.T) {
 				Formats:    []string{"apk"},
 				NFPMOverridables: config.NFPMOverridables{
 					PackageName: "foo",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -882,7 +895,7 @@ func TestAPKSpecificScriptsConfig(t *testing.T) {
 				Formats:    []string{"apk"},
 				NFPMOverridables: config.NFPMOverridables{
 					PackageName: "foo",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -995,7 +1008,7 @@ func TestMeta(t *testing.T) {
 					EmptyFolders:     []string{"/var/log/foobar"},
 					Release:          "10",
 					Epoch:            "20",
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -1073,7 +1086,7 @@ func TestSkipSign(t *testing.T) {
 				NFPMOverridables: config.NFPMOverridables{
 					PackageName:      "foo",
 					FileNameTemplate: defaultNameTemplate,
-					Contents: []*files.Content{
+					Contents: []*config.NFPMContent{
 						{
 							Source:      "testdata/testfile.txt",
 							Destination: "/usr/share/testfile.txt",
@@ -1223,3 +1236,517 @@ func sources(contents files.Contents) []string {
 	}
 	return result
 }
+
+func TestTemplatedContents(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(dist, "mybin"), 0o755))
+	binPath := filepath.Join(dist, "mybin", "mybin")
+	f, err := os.Create(binPath)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	confd := filepath.Join(folder, "conf.d")
+	require.NoError(t, os.Mkdir(confd, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(confd, "a.conf"), []byte("a={{ .Version }}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(confd, "b.conf"), []byte("b={{ .Arch }}\n"), 0o644))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Bindir:      "/usr/bin",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb"},
+				Description: "Some description",
+				License:     "MIT",
+				Maintainer:  "me@me",
+				NFPMOverridables: config.NFPMOverridables{
+					PackageName: "foo",
+					Contents: []*config.NFPMContent{
+						{
+							Source:      "./testdata/templated.conf",
+							Destination: "/etc/foo.conf",
+							Type:        "config",
+							Template:    true,
+						},
+						{
+							Source:      filepath.Join(confd, "*.conf"),
+							Destination: "/etc/foo.d",
+							Template:    true,
+						},
+						{
+							Source:      "./testdata/templated.conf",
+							Destination: "/etc/verbatim.conf",
+						},
+					},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	packages := ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+	require.Len(t, packages, 1)
+
+	contents := debContents(t, packages[0].Path)
+	require.Equal(t, "version=1.0.0\narch=amd64\n", contents["./etc/foo.conf"])
+	require.Equal(t, "version={{ .Version }}\narch={{ .Arch }}\n", contents["./etc/verbatim.conf"])
+	require.Equal(t, "a=1.0.0\n", contents["./etc/foo.d/a.conf"])
+	require.Equal(t, "b=amd64\n", contents["./etc/foo.d/b.conf"])
+}
+
+func TestTemplatedContentsNotAFile(t *testing.T) {
+	for _, tp := range []string{"symlink", "dir", "ghost"} {
+		tp := tp
+		t.Run(tp, func(t *testing.T) {
+			folder := t.TempDir()
+			dist := filepath.Join(folder, "dist")
+			require.NoError(t, os.Mkdir(dist, 0o755))
+			ctx := context.New(config.Project{
+				ProjectName: "mybin",
+				Dist:        dist,
+				NFPMs: []config.NFPM{
+					{
+						ID:          "someid",
+						Builds:      []string{"default"},
+						Formats:     []string{"deb"},
+						Maintainer:  "me@me",
+						Description: "Some description",
+						NFPMOverridables: config.NFPMOverridables{
+							PackageName: "foo",
+							Contents: []*config.NFPMContent{
+								{
+									Source:      "/usr/bin/foo",
+									Destination: "/usr/local/bin/foo",
+									Type:        tp,
+									Template:    true,
+								},
+							},
+						},
+					},
+				},
+			})
+			ctx.Version = "1.0.0"
+			ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "mybin",
+				Path:   filepath.Join(dist, "mybin"),
+				Goarch: "amd64",
+				Goos:   "linux",
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					artifact.ExtraID: "default",
+				},
+			})
+			require.EqualError(t, Pipe{}.Run(ctx), "/usr/local/bin/foo: template is not supported for contents of type "+tp)
+		})
+	}
+}
+
+func TestTemplatedContentsInvalid(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	tmpl := filepath.Join(folder, "invalid.conf")
+	require.NoError(t, os.WriteFile(tmpl, []byte("{{ .Nope }}"), 0o644))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb"},
+				Maintainer:  "me@me",
+				Description: "Some description",
+				NFPMOverridables: config.NFPMOverridables{
+					PackageName: "foo",
+					Contents: []*config.NFPMContent{
+						{
+							Source:      tmpl,
+							Destination: "/etc/foo.conf",
+							Template:    true,
+						},
+					},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "mybin"),
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to apply template to "+tmpl)
+}
+
+// debContents returns the contents of the regular files inside the data
+// archive of the given deb.
+func debContents(tb testing.TB, path string) map[string]string {
+	tb.Helper()
+	return debArchiveContents(tb, path, "data.tar.gz")
+}
+
+// debArchiveContents returns the contents of the regular files inside the
+// given archive of the deb.
+func debArchiveContents(tb testing.TB, path, archive string) map[string]string {
+	tb.Helper()
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	require.True(tb, bytes.HasPrefix(bts, []byte("!<arch>\n")))
+	bts = bts[8:]
+	for len(bts) >= 60 {
+		name := strings.TrimSpace(string(bts[0:16]))
+		size, err := strconv.Atoi(strings.TrimSpace(string(bts[48:58])))
+		require.NoError(tb, err)
+		data := bts[60 : 60+size]
+		bts = bts[60+size+size%2:]
+		if name != archive {
+			continue
+		}
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		require.NoError(tb, err)
+		result := map[string]string{}
+		tr := tar.NewReader(gr)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				return result
+			}
+			require.NoError(tb, err)
+			if h.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, err := io.ReadAll(tr)
+			require.NoError(tb, err)
+			result[h.Name] = string(content)
+		}
+	}
+	require.FailNow(tb, archive+" not found in "+path)
+	return nil
+}
+
+func TestChangelog(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	binPath := filepath.Join(dist, "mybin")
+	require.NoError(t, os.WriteFile(binPath, nil, 0o755))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Bindir:      "/usr/bin",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb", "rpm"},
+				Description: "Some description",
+				License:     "MIT",
+				Maintainer:  "me@me",
+				Changelog: config.NFPMChangelog{
+					Enabled: true,
+					File:    "./testdata/{{ .Env.CHANGELOG }}.yml",
+				},
+				NFPMOverridables: config.NFPMOverridables{
+					PackageName: "foo",
+				},
+			},
+		},
+	})
+	ctx.Env = map[string]string{"CHANGELOG": "changelog"}
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Date = time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	ctx.ReleaseNotes = "## Changelog\n\n* abcdef1 fix foo\n* 1234567: add bar   \n* a dependency update\n"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List(), 2)
+
+	entries, err := chglog.Parse(filepath.Join(dist, "someid.changelog.yml"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "1.0.0", entries[0].Semver)
+	require.Equal(t, "me@me", entries[0].Packager)
+	require.Equal(t, ctx.Date, entries[0].Date)
+	require.Equal(t, chglog.ChangeLogChanges{
+		{Commit: "abcdef1", Note: "fix foo"},
+		{Commit: "1234567", Note: "add bar"},
+		{Note: "a dependency update"},
+	}, entries[0].Changes)
+	require.Equal(t, "0.9.0", entries[1].Semver)
+
+	deb := ctx.Artifacts.Filter(artifact.ByFormats("deb")).List()[0]
+	gr, err := gzip.NewReader(strings.NewReader(debContents(t, deb.Path)["./usr/share/doc/foo/changelog.gz"]))
+	require.NoError(t, err)
+	changelog, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	require.Equal(t, `foo (1.0.0) stable; urgency=low
+  * fix foo
+  * add bar
+  * a dependency update
+
+ -- me@me  Wed, 01 Dec 2021 10:00:00 +0000
+
+foo (0.9.0) stable; urgency=low
+  * first release
+
+ -- me@me  Mon, 01 Nov 2021 10:00:00 +0000
+`, string(changelog))
+}
+
+func TestChangelogExistingEntry(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	ctx.Version = "0.9.0"
+	ctx.ReleaseNotes = "* abcdef1 fix foo\n"
+	path, err := writeChangelog(ctx, config.NFPM{
+		ID:        "default",
+		Changelog: config.NFPMChangelog{Enabled: true, File: "./testdata/changelog.yml"},
+	})
+	require.NoError(t, err)
+	entries, err := chglog.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "first release", entries[0].Changes[0].Note)
+}
+
+func TestChangelogNoReleaseNotes(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	ctx.Version = "1.0.0"
+	path, err := writeChangelog(ctx, config.NFPM{
+		ID:        "default",
+		Changelog: config.NFPMChangelog{Enabled: true},
+	})
+	require.NoError(t, err)
+	entries, err := chglog.Parse(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, chglog.ChangeLogChanges{{Note: "Release 1.0.0"}}, entries[0].Changes)
+}
+
+func TestChangelogInvalid(t *testing.T) {
+	ctx := context.New(config.Project{Dist: t.TempDir()})
+	_, err := writeChangelog(ctx, config.NFPM{
+		Changelog: config.NFPMChangelog{Enabled: true, File: "{{ .Nope }"},
+	})
+	require.Error(t, err)
+
+	invalid := filepath.Join(t.TempDir(), "changelog.yml")
+	require.NoError(t, os.WriteFile(invalid, []byte("nope: nope"), 0o644))
+	_, err = writeChangelog(ctx, config.NFPM{
+		Changelog: config.NFPMChangelog{Enabled: true, File: invalid},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error parsing "+invalid)
+}
+
+func TestSystemd(t *testing.T) {
+	folder := t.TempDir()
+	dist := filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0o755))
+	binPath := filepath.Join(dist, "mybin")
+	require.NoError(t, os.WriteFile(binPath, nil, 0o755))
+	postinstall := filepath.Join(folder, "postinstall.sh")
+	require.NoError(t, os.WriteFile(postinstall, []byte("#!/bin/sh\necho installed\n"), 0o755))
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		NFPMs: []config.NFPM{
+			{
+				ID:          "someid",
+				Bindir:      "/usr/bin",
+				Builds:      []string{"default"},
+				Formats:     []string{"deb", "rpm", "apk"},
+				Description: "Some description",
+				License:     "MIT",
+				Maintainer:  "me@me",
+				Systemd: []config.NFPMSystemd{
+					{
+						Args:            []string{"serve", "--port", "{{ .Env.PORT }}", "--motd", "hello world"},
+						User:            "mybin",
+						EnvironmentFile: "-/etc/default/{{ .PackageName }}",
+						Hardening: config.NFPMSystemdHardening{
+							NoNewPrivileges: true,
+							PrivateTmp:      true,
+							ProtectSystem:   "strict",
+							ReadWritePaths:  []string{"/var/lib/foo", "/var/log/foo"},
+						},
+					},
+					{
+						Name:        "foo-worker",
+						Description: "Foo worker",
+						Binary:      "/opt/foo/worker",
+					},
+				},
+				NFPMOverridables: config.NFPMOverridables{
+					PackageName: "foo",
+					Scripts: config.NFPMScripts{
+						PostInstall: postinstall,
+					},
+				},
+			},
+		},
+	})
+	ctx.Env = map[string]string{"PORT": "8080"}
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goarch: "amd64",
+		Goos:   "linux",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "foo", ctx.Config.NFPMs[0].Systemd[0].Name)
+	require.Equal(t, "on-failure", ctx.Config.NFPMs[0].Systemd[0].Restart)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List(), 3)
+
+	deb := ctx.Artifacts.Filter(artifact.ByFormats("deb")).List()[0]
+	contents := debContents(t, deb.Path)
+	require.Equal(t, `[Unit]
+Description=Some description
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart=/usr/bin/mybin serve --port 8080 --motd "hello world"
+User=mybin
+Group=mybin
+Restart=on-failure
+EnvironmentFile=-/etc/default/foo
+NoNewPrivileges=true
+PrivateTmp=true
+ProtectSystem=strict
+ReadWritePaths=/var/lib/foo
+ReadWritePaths=/var/log/foo
+
+[Install]
+WantedBy=multi-user.target
+`, contents["./lib/systemd/system/foo.service"])
+	require.Contains(t, contents["./lib/systemd/system/foo-worker.service"], "Description=Foo worker\n")
+	require.Contains(t, contents["./lib/systemd/system/foo-worker.service"], "ExecStart=/opt/foo/worker\n")
+	require.NotContains(t, contents["./lib/systemd/system/foo-worker.service"], "User=")
+
+	control := debArchiveContents(t, deb.Path, "control.tar.gz")
+	require.Contains(t, control["./preinst"], "useradd --system --gid mybin --no-create-home --home-dir /nonexistent --shell /usr/sbin/nologin mybin\n")
+	require.True(t, strings.HasPrefix(control["./postinst"], "#!/bin/sh\necho installed\n\nif command -v systemctl"), control["./postinst"])
+	require.Contains(t, control["./postinst"], "\t\tsystemctl enable foo.service foo-worker.service || :\n")
+	require.Contains(t, control["./prerm"], `if [ "$1" = "remove" ]; then`)
+	require.Contains(t, control["./postrm"], "systemctl daemon-reload")
+}
+
+func TestSystemdScripts(t *testing.T) {
+	services := []service{
+		{NFPMSystemd: config.NFPMSystemd{Name: "foo", User: "foo", Group: "foo"}},
+		{NFPMSystemd: config.NFPMSystemd{Name: "bar", User: "root"}},
+	}
+	for _, format := range []string{"deb", "rpm", "apk", "archlinux"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			info := &nfpm.Info{}
+			require.NoError(t, addSystemdScripts(services, format, dir, info))
+			require.NotEmpty(t, info.Scripts.PreInstall)
+			require.NotEmpty(t, info.Scripts.PostInstall)
+			require.NotEmpty(t, info.Scripts.PreRemove)
+			require.NotEmpty(t, info.Scripts.PostRemove)
+
+			preinstall, err := os.ReadFile(info.Scripts.PreInstall)
+			require.NoError(t, err)
+			require.NotContains(t, string(preinstall), "root")
+			if format == "apk" {
+				require.Contains(t, string(preinstall), "adduser -S -D -H -h /nonexistent -s /sbin/nologin -G foo foo\n")
+				require.NotEmpty(t, info.APK.Scripts.PostUpgrade)
+			} else {
+				require.Contains(t, string(preinstall), "groupadd --system foo\n")
+				require.Empty(t, info.APK.Scripts.PostUpgrade)
+			}
+
+			preremove, err := os.ReadFile(info.Scripts.PreRemove)
+			require.NoError(t, err)
+			require.Contains(t, string(preremove), "systemctl stop foo.service bar.service || :\n")
+			require.Contains(t, string(preremove), "systemctl disable foo.service bar.service || :\n")
+		})
+	}
+
+	t.Run("missing user script", func(t *testing.T) {
+		info := &nfpm.Info{}
+		info.Scripts.PreRemove = "/nope/preremove.sh"
+		require.ErrorIs(t, addSystemdScripts(services, "deb", t.TempDir(), info), os.ErrNotExist)
+	})
+}
+
+func TestSystemdRPMScripts(t *testing.T) {
+	info := &nfpm.Info{}
+	services := []service{{NFPMSystemd: config.NFPMSystemd{Name: "foo"}}}
+	require.NoError(t, addSystemdScripts(services, "rpm", t.TempDir(), info))
+	require.Empty(t, info.Scripts.PreInstall)
+	postinstall, err := os.ReadFile(info.Scripts.PostInstall)
+	require.NoError(t, err)
+	require.Equal(t, `#!/bin/sh
+if command -v systemctl >/dev/null 2>&1; then
+	systemctl daemon-reload || :
+	if [ "$1" -eq 1 ]; then
+		systemctl enable foo.service || :
+		if [ -d /run/systemd/system ]; then
+			systemctl start foo.service || :
+		fi
+	else
+		if [ -d /run/systemd/system ]; then
+			systemctl try-restart foo.service || :
+		fi
+	fi
+fi
+`, string(postinstall))
+}
+
+func TestSystemdNoBinary(t *testing.T) {
+	_, err := systemdServices(tmpl.New(context.New(config.Project{})), config.NFPM{
+		Systemd: []config.NFPMSystemd{{Name: "foo"}},
+	}, "", "/usr/bin", "")
+	require.EqualError(t, err, "systemd service foo: no binary to run")
+}
+
+func TestQuoteArg(t *testing.T) {
+	require.Equal(t, "foo", quoteArg("foo"))
+	require.Equal(t, `""`, quoteArg(""))
+	require.Equal(t, `"foo bar"`, quoteArg("foo bar"))
+	require.Equal(t, `"say \"hi\""`, quoteArg(`say "hi"`))
+}
@@ -0,0 +1,283 @@
+package nfpm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/nfpm/v2"
+	"github.com/goreleaser/nfpm/v2/files"
+)
+
+const systemdUnitDir = "/lib/systemd/system"
+
+const unitTemplate = `[Unit]
+Description={{ .Description }}
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{ .ExecStart }}
+{{- with .User }}
+User={{ . }}
+{{- end }}
+{{- with .Group }}
+Group={{ . }}
+{{- end }}
+Restart={{ .Restart }}
+{{- with .EnvironmentFile }}
+EnvironmentFile={{ . }}
+{{- end }}
+{{- if .Hardening.NoNewPrivileges }}
+NoNewPrivileges=true
+{{- end }}
+{{- if .Hardening.PrivateTmp }}
+PrivateTmp=true
+{{- end }}
+{{- if .Hardening.ProtectHome }}
+ProtectHome=true
+{{- end }}
+{{- with .Hardening.ProtectSystem }}
+ProtectSystem={{ . }}
+{{- end }}
+{{- range .Hardening.ReadWritePaths }}
+ReadWritePaths={{ . }}
+{{- end }}
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// service is a systemd service with all its templates applied.
+type service struct {
+	config.NFPMSystemd
+	ExecStart string
+}
+
+func (s service) unit() string {
+	return s.Name + ".service"
+}
+
+// systemdServices applies the templates of the systemd services of the
+// package.
+func systemdServices(t *tmpl.Template, fpm config.NFPM, description, binDir, defaultBinary string) ([]service, error) {
+	services := make([]service, 0, len(fpm.Systemd))
+	for _, cfg := range fpm.Systemd {
+		s := service{NFPMSystemd: cfg}
+		if s.Description == "" {
+			s.Description = description
+		}
+		var err error
+		for _, field := range []*string{&s.Description, &s.Binary, &s.EnvironmentFile} {
+			if *field, err = t.Apply(*field); err != nil {
+				return nil, err
+			}
+		}
+		if s.Binary == "" {
+			s.Binary = defaultBinary
+		}
+		if s.Binary == "" {
+			return nil, fmt.Errorf("systemd service %s: no binary to run", s.Name)
+		}
+		if !filepath.IsAbs(s.Binary) {
+			s.Binary = filepath.ToSlash(filepath.Join(binDir, s.Binary))
+		}
+		if s.User != "" && s.Group == "" {
+			s.Group = s.User
+		}
+		exec := []string{quoteArg(s.Binary)}
+		for _, arg := range s.Args {
+			arg, err := t.Apply(arg)
+			if err != nil {
+				return nil, err
+			}
+			exec = append(exec, quoteArg(arg))
+		}
+		s.ExecStart = strings.Join(exec, " ")
+		s.Description = strings.Join(strings.Fields(s.Description), " ")
+		services = append(services, s)
+	}
+	return services, nil
+}
+
+// quoteArg quotes a command line argument for systemd, if needed.
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(arg) + `"`
+}
+
+// addSystemdUnits renders the unit files of the services into dir, adding
+// them to the package contents.
+func addSystemdUnits(services []service, dir string, contents files.Contents) (files.Contents, error) {
+	tpl := template.Must(template.New("unit").Parse(unitTemplate))
+	for _, s := range services {
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, s); err != nil {
+			return nil, err
+		}
+		src := filepath.Join(dir, "systemd", s.unit())
+		if err := os.MkdirAll(filepath.Dir(src), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(src, buf.Bytes(), 0o644); err != nil {
+			return nil, err
+		}
+		contents = append(contents, &files.Content{
+			Source:      filepath.ToSlash(src),
+			Destination: systemdUnitDir + "/" + s.unit(),
+			FileInfo:    &files.ContentFileInfo{Mode: 0o644},
+		})
+	}
+	return contents, nil
+}
+
+// addSystemdScripts prepends or appends the handling of the systemd services
+// to the package scripts, writing the resulting scripts into dir.
+//
+// The services users are created before installing, the services are enabled
+// and started after installing, restarted after upgrading, and stopped and
+// disabled before removing.
+func addSystemdScripts(services []service, format, dir string, info *nfpm.Info) error {
+	units := make([]string, 0, len(services))
+	for _, s := range services {
+		units = append(units, s.unit())
+	}
+	list := strings.Join(units, " ")
+	start := fmt.Sprintf(`systemctl enable %[1]s || :
+if [ -d /run/systemd/system ]; then
+	systemctl start %[1]s || :
+fi`, list)
+	restart := fmt.Sprintf(`if [ -d /run/systemd/system ]; then
+	systemctl try-restart %s || :
+fi`, list)
+	stop := fmt.Sprintf(`if [ -d /run/systemd/system ]; then
+	systemctl stop %[1]s || :
+fi
+systemctl disable %[1]s || :`, list)
+	reload := "systemctl daemon-reload || :"
+
+	var postInstall, preRemove string
+	switch format {
+	case "deb":
+		postInstall = fmt.Sprintf(`if [ "$1" = "configure" ] && [ -z "$2" ]; then
+%s
+elif [ "$1" = "configure" ]; then
+%s
+fi`, indent(start), indent(restart))
+		preRemove = fmt.Sprintf(`if [ "$1" = "remove" ]; then
+%s
+fi`, indent(stop))
+	case "rpm":
+		postInstall = fmt.Sprintf(`if [ "$1" -eq 1 ]; then
+%s
+else
+%s
+fi`, indent(start), indent(restart))
+		preRemove = fmt.Sprintf(`if [ "$1" -eq 0 ]; then
+%s
+fi`, indent(stop))
+	default:
+		// apk and archlinux only run these scripts on install and removal
+		postInstall = start
+		preRemove = stop
+	}
+	withSystemctl := func(script string) string {
+		return "if command -v systemctl >/dev/null 2>&1; then\n" + indent(script) + "\nfi\n"
+	}
+
+	var err error
+	if users := createUsers(services, format); users != "" {
+		if info.Scripts.PreInstall, err = writeScript(dir, "preinstall", users, info.Scripts.PreInstall, true); err != nil {
+			return err
+		}
+	}
+	if info.Scripts.PostInstall, err = writeScript(dir, "postinstall", withSystemctl(reload+"\n"+postInstall), info.Scripts.PostInstall, false); err != nil {
+		return err
+	}
+	if info.Scripts.PreRemove, err = writeScript(dir, "preremove", withSystemctl(preRemove), info.Scripts.PreRemove, true); err != nil {
+		return err
+	}
+	if info.Scripts.PostRemove, err = writeScript(dir, "postremove", withSystemctl(reload), info.Scripts.PostRemove, false); err != nil {
+		return err
+	}
+	if format == "apk" {
+		if info.APK.Scripts.PostUpgrade, err = writeScript(dir, "postupgrade", withSystemctl(reload+"\n"+restart), info.APK.Scripts.PostUpgrade, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createUsers creates the users and groups the services run as, if they
+// don't exist yet.
+func createUsers(services []service, format string) string {
+	var b strings.Builder
+	seen := map[string]bool{}
+	for _, s := range services {
+		if s.User == "" || s.User == "root" || seen[s.User] {
+			continue
+		}
+		seen[s.User] = true
+		if format == "apk" {
+			fmt.Fprintf(&b, `if ! getent group %[2]s >/dev/null 2>&1; then
+	addgroup -S %[2]s
+fi
+if ! getent passwd %[1]s >/dev/null 2>&1; then
+	adduser -S -D -H -h /nonexistent -s /sbin/nologin -G %[2]s %[1]s
+fi
+`, s.User, s.Group)
+			continue
+		}
+		fmt.Fprintf(&b, `if ! getent group %[2]s >/dev/null 2>&1; then
+	groupadd --system %[2]s
+fi
+if ! getent passwd %[1]s >/dev/null 2>&1; then
+	useradd --system --gid %[2]s --no-create-home --home-dir /nonexistent --shell /usr/sbin/nologin %[1]s
+fi
+`, s.User, s.Group)
+	}
+	return b.String()
+}
+
+// writeScript writes a script with the given content and the content of the
+// user provided script, if any, before or after it, returning its path.
+func writeScript(dir, name, content, userScript string, first bool) (string, error) {
+	parts := []string{content}
+	if userScript != "" {
+		bts, err := os.ReadFile(userScript)
+		if err != nil {
+			return "", err
+		}
+		user := strings.TrimPrefix(string(bts), "#!/bin/sh\n")
+		if first {
+			parts = append(parts, user)
+		} else {
+			parts = append([]string{user}, parts...)
+		}
+	}
+	path := filepath.Join(dir, "scripts", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	script := "#!/bin/sh\n" + strings.Join(parts, "\n")
+	return path, os.WriteFile(path, []byte(script), 0o755)
+}
+
+// indent indents the non empty lines of a script by one tab.
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "\t" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
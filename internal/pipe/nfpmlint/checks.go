@@ -0,0 +1,184 @@
+package nfpmlint
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/linuxpkg"
+)
+
+const (
+	levelError   = "error"
+	levelWarning = "warning"
+)
+
+// problem is an issue found in a package.
+type problem struct {
+	Check   string
+	Level   string
+	Message string
+}
+
+// pkg is the information of a deb or rpm package the checks run against.
+type pkg struct {
+	Format      string
+	Name        string
+	Version     string
+	Release     string // rpm only, debs have it inside the version
+	Maintainer  string
+	Description string
+	License     string // rpm only, debs have a copyright file instead
+	Files       []linuxpkg.File
+	Conffiles   []string
+}
+
+var checks = []func(p pkg) []problem{
+	checkMaintainer,
+	checkDescription,
+	checkLicense,
+	checkVersion,
+	checkBinaryPaths,
+	checkWorldWritable,
+	checkConffiles,
+}
+
+// lint runs all the checks against the package.
+func lint(p pkg) []problem {
+	var problems []problem
+	for _, check := range checks {
+		problems = append(problems, check(p)...)
+	}
+	return problems
+}
+
+var maintainerRe = regexp.MustCompile(`^[^<>]+ <[^<>@\s]+@[^<>@\s]+>$`)
+
+func checkMaintainer(p pkg) []problem {
+	if strings.TrimSpace(p.Maintainer) == "" {
+		return []problem{{"maintainer-missing", levelError, "the package has no maintainer"}}
+	}
+	if !maintainerRe.MatchString(p.Maintainer) {
+		return []problem{{"maintainer-malformed", levelWarning, fmt.Sprintf("maintainer %q is not in the 'Name <email>' format", p.Maintainer)}}
+	}
+	return nil
+}
+
+func checkDescription(p pkg) []problem {
+	if strings.TrimSpace(p.Description) == "" {
+		return []problem{{"description-missing", levelError, "the package has no description"}}
+	}
+	return nil
+}
+
+func checkLicense(p pkg) []problem {
+	if p.Format == "rpm" {
+		if strings.TrimSpace(p.License) == "" {
+			return []problem{{"license-missing", levelError, "the package has no license"}}
+		}
+		return nil
+	}
+	copyright := "/usr/share/doc/" + p.Name + "/copyright"
+	for _, f := range p.Files {
+		if f.Path == copyright {
+			return nil
+		}
+	}
+	return []problem{{"license-missing", levelWarning, "the package has no " + copyright + " file"}}
+}
+
+var (
+	debVersionRe = regexp.MustCompile(`^([0-9]+:)?[0-9][A-Za-z0-9.+~-]*$`)
+	rpmVersionRe = regexp.MustCompile(`^[A-Za-z0-9._+~^]+$`)
+)
+
+func checkVersion(p pkg) []problem {
+	invalid := func(field, value string) []problem {
+		return []problem{{"version-invalid", levelError, fmt.Sprintf("%s %q is not a valid %s %s", field, value, p.Format, field)}}
+	}
+	if p.Format == "rpm" {
+		if !rpmVersionRe.MatchString(p.Version) {
+			return invalid("version", p.Version)
+		}
+		if !rpmVersionRe.MatchString(p.Release) {
+			return invalid("release", p.Release)
+		}
+		return nil
+	}
+	if !debVersionRe.MatchString(p.Version) {
+		return invalid("version", p.Version)
+	}
+	return nil
+}
+
+// binaryDirs are the directories executables are expected to be in.
+var binaryDirs = []string{
+	"/bin/",
+	"/sbin/",
+	"/usr/bin/",
+	"/usr/sbin/",
+	"/usr/local/bin/",
+	"/usr/local/sbin/",
+	"/usr/games/",
+	"/lib/",
+	"/usr/lib/",
+	"/usr/lib64/",
+	"/usr/libexec/",
+	"/usr/local/lib/",
+	"/usr/share/",
+	"/etc/",
+	"/opt/",
+}
+
+func checkBinaryPaths(p pkg) []problem {
+	var problems []problem
+	for _, f := range p.Files {
+		if !f.Mode.IsRegular() || f.Mode.Perm()&0o111 == 0 || hasAnyPrefix(f.Path, binaryDirs) {
+			continue
+		}
+		problems = append(problems, problem{"binary-outside-standard-path", levelWarning, fmt.Sprintf("executable %s is outside the standard paths", f.Path)})
+	}
+	return problems
+}
+
+func checkWorldWritable(p pkg) []problem {
+	var problems []problem
+	for _, f := range p.Files {
+		if f.Mode&os.ModeSymlink != 0 || f.Mode.Perm()&0o002 == 0 {
+			continue
+		}
+		if f.Mode.IsDir() && f.Mode&os.ModeSticky != 0 {
+			// like /tmp
+			continue
+		}
+		problems = append(problems, problem{"world-writable", levelError, fmt.Sprintf("%s is world-writable (%s)", f.Path, f.Mode)})
+	}
+	return problems
+}
+
+func checkConffiles(p pkg) []problem {
+	conffiles := p.Conffiles
+	for _, f := range p.Files {
+		if f.Config {
+			conffiles = append(conffiles, f.Path)
+		}
+	}
+	var problems []problem
+	for _, name := range conffiles {
+		if !strings.HasPrefix(path.Clean(name), "/etc/") {
+			problems = append(problems, problem{"conffile-outside-etc", levelError, fmt.Sprintf("config file %s is outside /etc", name)})
+		}
+	}
+	return problems
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package nfpmlint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/linuxpkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintClean(t *testing.T) {
+	for _, p := range []pkg{
+		{
+			Format:      "deb",
+			Name:        "foo",
+			Version:     "1:1.0.0-1~rc1",
+			Maintainer:  "Foo <foo@bar.com>",
+			Description: "foo",
+			Files: []linuxpkg.File{
+				{Path: "/usr/bin/foo", Mode: 0o755},
+				{Path: "/usr/share/doc/foo/copyright", Mode: 0o644},
+				{Path: "/etc/foo.conf", Mode: 0o644},
+				{Path: "/var/tmp/foo", Mode: os.ModeDir | os.ModeSticky | 0o777},
+				{Path: "/usr/bin/bar", Mode: os.ModeSymlink | 0o777},
+			},
+			Conffiles: []string{"/etc/foo.conf"},
+		},
+		{
+			Format:      "rpm",
+			Name:        "foo",
+			Version:     "1.0.0~rc1",
+			Release:     "1",
+			Maintainer:  "Foo <foo@bar.com>",
+			Description: "foo",
+			License:     "MIT",
+			Files: []linuxpkg.File{
+				{Path: "/opt/foo/foo", Mode: 0o755},
+				{Path: "/etc/foo.conf", Mode: 0o644, Config: true},
+			},
+		},
+	} {
+		require.Empty(t, lint(p), p.Format)
+	}
+}
+
+func TestLintProblems(t *testing.T) {
+	for name, tt := range map[string]struct {
+		pkg      pkg
+		expected []problem
+	}{
+		"missing fields": {
+			pkg: pkg{Format: "rpm", Name: "foo", Version: "1.0.0", Release: "1"},
+			expected: []problem{
+				{"maintainer-missing", levelError, "the package has no maintainer"},
+				{"description-missing", levelError, "the package has no description"},
+				{"license-missing", levelError, "the package has no license"},
+			},
+		},
+		"malformed maintainer": {
+			pkg: pkg{Format: "rpm", Name: "foo", Version: "1.0.0", Release: "1", Maintainer: "foo@bar.com", Description: "foo", License: "MIT"},
+			expected: []problem{
+				{"maintainer-malformed", levelWarning, `maintainer "foo@bar.com" is not in the 'Name <email>' format`},
+			},
+		},
+		"no copyright": {
+			pkg: pkg{Format: "deb", Name: "foo", Version: "1.0.0", Maintainer: "Foo <foo@bar.com>", Description: "foo"},
+			expected: []problem{
+				{"license-missing", levelWarning, "the package has no /usr/share/doc/foo/copyright file"},
+			},
+		},
+		"invalid deb version": {
+			pkg: pkg{Format: "deb", Name: "foo", Version: "v1.0.0", Maintainer: "Foo <foo@bar.com>", Description: "foo", Files: []linuxpkg.File{{Path: "/usr/share/doc/foo/copyright"}}},
+			expected: []problem{
+				{"version-invalid", levelError, `version "v1.0.0" is not a valid deb version`},
+			},
+		},
+		"invalid rpm version": {
+			pkg: pkg{Format: "rpm", Name: "foo", Version: "1.0.0-rc1", Release: "1", Maintainer: "Foo <foo@bar.com>", Description: "foo", License: "MIT"},
+			expected: []problem{
+				{"version-invalid", levelError, `version "1.0.0-rc1" is not a valid rpm version`},
+			},
+		},
+		"invalid rpm release": {
+			pkg: pkg{Format: "rpm", Name: "foo", Version: "1.0.0", Maintainer: "Foo <foo@bar.com>", Description: "foo", License: "MIT"},
+			expected: []problem{
+				{"version-invalid", levelError, `release "" is not a valid rpm release`},
+			},
+		},
+		"files": {
+			pkg: pkg{
+				Format: "rpm", Name: "foo", Version: "1.0.0", Release: "1", Maintainer: "Foo <foo@bar.com>", Description: "foo", License: "MIT",
+				Files: []linuxpkg.File{
+					{Path: "/srv/foo/run.sh", Mode: 0o755},
+					{Path: "/var/lib/foo/data", Mode: 0o666},
+					{Path: "/var/lib/foo", Mode: os.ModeDir | 0o777},
+					{Path: "/var/lib/foo/foo.conf", Mode: 0o644, Config: true},
+				},
+			},
+			expected: []problem{
+				{"binary-outside-standard-path", levelWarning, "executable /srv/foo/run.sh is outside the standard paths"},
+				{"world-writable", levelError, "/var/lib/foo/data is world-writable (-rw-rw-rw-)"},
+				{"world-writable", levelError, "/var/lib/foo is world-writable (drwxrwxrwx)"},
+				{"conffile-outside-etc", levelError, "config file /var/lib/foo/foo.conf is outside /etc"},
+			},
+		},
+		"deb conffiles": {
+			pkg: pkg{
+				Format: "deb", Name: "foo", Version: "1.0.0", Maintainer: "Foo <foo@bar.com>", Description: "foo",
+				Files:     []linuxpkg.File{{Path: "/usr/share/doc/foo/copyright"}},
+				Conffiles: []string{"/etc/foo.conf", "/usr/share/foo/foo.conf"},
+			},
+			expected: []problem{
+				{"conffile-outside-etc", levelError, "config file /usr/share/foo/foo.conf is outside /etc"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, lint(tt.pkg))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package nfpmlint implements the Pipe interface checking the deb and rpm
+// packages created by nfpm for common problems.
+package nfpmlint
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/linuxpkg"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe for linting linux packages.
+type Pipe struct{}
+
+func (Pipe) String() string { return "linting linux packages" }
+
+func (Pipe) Skip(ctx *context.Context) bool {
+	for _, fpm := range ctx.Config.NFPMs {
+		if fpm.Lint.Enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	for _, fpm := range ctx.Config.NFPMs {
+		if !fpm.Lint.Enabled {
+			continue
+		}
+		if err := doRun(ctx, fpm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func doRun(ctx *context.Context, fpm config.NFPM) error {
+	ignored := map[string]bool{}
+	for _, check := range fpm.Lint.Ignore {
+		ignored[check] = true
+	}
+
+	var errors int
+	for _, a := range ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByIDs(fpm.ID),
+		artifact.ByFormats("deb", "rpm"),
+	)).List() {
+		p, err := read(a)
+		if err != nil {
+			return fmt.Errorf("failed to lint %s: %w", a.Name, err)
+		}
+		log := log.WithField("package", a.Name)
+		problems := 0
+		for _, problem := range lint(p) {
+			if ignored[problem.Check] {
+				continue
+			}
+			problems++
+			entry := log.WithField("check", problem.Check)
+			if problem.Level == levelError {
+				errors++
+				entry.Error(problem.Message)
+				continue
+			}
+			entry.Warn(problem.Message)
+		}
+		if problems == 0 {
+			log.Debug("no problems found")
+		}
+	}
+	if errors > 0 && fpm.Lint.Fail {
+		return fmt.Errorf("linting found %d errors in the packages of nfpm %s", errors, fpm.ID)
+	}
+	return nil
+}
+
+func read(a *artifact.Artifact) (pkg, error) {
+	if a.Format() == "rpm" {
+		return readRPM(a.Path)
+	}
+	deb, err := linuxpkg.ReadDeb(a.Path)
+	if err != nil {
+		return pkg{}, err
+	}
+	return pkg{
+		Format:      "deb",
+		Name:        deb.Control.Get("Package"),
+		Version:     deb.Control.Get("Version"),
+		Maintainer:  deb.Control.Get("Maintainer"),
+		Description: deb.Control.Get("Description"),
+		Files:       deb.Files,
+		Conffiles:   deb.Conffiles,
+	}, nil
+}
+
+func readRPM(path string) (pkg, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return pkg{}, err
+	}
+	defer f.Close()
+	_, hdr, err := linuxpkg.ReadRPM(f)
+	if err != nil {
+		return pkg{}, err
+	}
+	description := hdr.GetString(linuxpkg.TagSummary)
+	if description == "" {
+		description = hdr.GetString(linuxpkg.TagDescription)
+	}
+	return pkg{
+		Format:      "rpm",
+		Name:        hdr.GetString(linuxpkg.TagName),
+		Version:     hdr.GetString(linuxpkg.TagVersion),
+		Release:     hdr.GetString(linuxpkg.TagRelease),
+		Maintainer:  hdr.GetString(linuxpkg.TagPackager),
+		Description: description,
+		License:     hdr.GetString(linuxpkg.TagLicense),
+		Files:       hdr.Files(),
+	}, nil
+}
@@ -0,0 +1,152 @@
+package nfpmlint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/goreleaser/nfpm/v2"
+	_ "github.com/goreleaser/nfpm/v2/deb" // register the deb packager
+	"github.com/goreleaser/nfpm/v2/files"
+	_ "github.com/goreleaser/nfpm/v2/rpm" // register the rpm packager
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.True(t, Pipe{}.Skip(context.New(config.Project{
+		NFPMs: []config.NFPM{{}},
+	})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		NFPMs: []config.NFPM{{}, {Lint: config.NFPMLint{Enabled: true}}},
+	})))
+}
+
+func TestRun(t *testing.T) {
+	for name, tt := range map[string]struct {
+		lint     config.NFPMLint
+		expected string
+	}{
+		"report only": {
+			lint: config.NFPMLint{Enabled: true},
+		},
+		"fail": {
+			lint:     config.NFPMLint{Enabled: true, Fail: true},
+			expected: "linting found 3 errors in the packages of nfpm foo",
+		},
+		"fail ignored": {
+			lint: config.NFPMLint{Enabled: true, Fail: true, Ignore: []string{"world-writable", "license-missing"}},
+		},
+		"disabled": {
+			lint: config.NFPMLint{Fail: true},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				NFPMs: []config.NFPM{{ID: "foo", Lint: tt.lint}},
+			})
+			folder := t.TempDir()
+			addPackage(t, ctx, folder, "deb", "foo")
+			addPackage(t, ctx, folder, "rpm", "foo")
+			addPackage(t, ctx, folder, "rpm", "bar")
+			err := Pipe{}.Run(ctx)
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestRunInvalidPackage(t *testing.T) {
+	ctx := context.New(config.Project{
+		NFPMs: []config.NFPM{{ID: "foo", Lint: config.NFPMLint{Enabled: true}}},
+	})
+	path := filepath.Join(t.TempDir(), "foo.rpm")
+	require.NoError(t, os.WriteFile(path, []byte("nope"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo.rpm",
+		Path: path,
+		Type: artifact.LinuxPackage,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraFormat: "rpm",
+		},
+	})
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to lint foo.rpm: not a valid rpm package")
+}
+
+func TestRead(t *testing.T) {
+	ctx := context.New(config.Project{})
+	folder := t.TempDir()
+	addPackage(t, ctx, folder, "deb", "foo")
+	addPackage(t, ctx, folder, "rpm", "foo")
+	for _, a := range ctx.Artifacts.List() {
+		p, err := read(a)
+		require.NoError(t, err)
+		require.Equal(t, a.Format(), p.Format)
+		require.Equal(t, "foo", p.Name)
+		require.Equal(t, "Foo <foo@bar.com>", p.Maintainer)
+		require.Equal(t, "foo does things", p.Description)
+		require.Equal(t, []problem{
+			{"world-writable", levelError, "/usr/bin/foo is world-writable (-rwxrwxrwx)"},
+		}, withoutLicense(lint(p)))
+	}
+}
+
+// addPackage creates a package with a world-writable binary and no license.
+func addPackage(tb testing.TB, ctx *context.Context, folder, format, id string) {
+	tb.Helper()
+	binary := filepath.Join(folder, "foo")
+	require.NoError(tb, os.WriteFile(binary, []byte("#!/bin/sh"), 0o755))
+	info := nfpm.WithDefaults(&nfpm.Info{
+		Name:        "foo",
+		Arch:        "amd64",
+		Platform:    "linux",
+		Version:     "1.0.0",
+		Maintainer:  "Foo <foo@bar.com>",
+		Description: "foo does things",
+		Overridables: nfpm.Overridables{
+			Contents: files.Contents{
+				{Source: binary, Destination: "/usr/bin/foo", FileInfo: &files.ContentFileInfo{Mode: 0o777}},
+			},
+		},
+	})
+	packager, err := nfpm.Get(format)
+	require.NoError(tb, err)
+	path := filepath.Join(folder, id+"."+format)
+	f, err := os.Create(path)
+	require.NoError(tb, err)
+	require.NoError(tb, packager.Package(info, f))
+	require.NoError(tb, f.Close())
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filepath.Base(path),
+		Path: path,
+		Type: artifact.LinuxPackage,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     id,
+			artifact.ExtraFormat: format,
+		},
+	})
+}
+
+func withoutLicense(problems []problem) []problem {
+	var result []problem
+	for _, p := range problems {
+		if p.Check != "license-missing" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
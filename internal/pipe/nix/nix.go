@@ -0,0 +1,354 @@
+// Package nix implements the Pipe, providing Nix derivation generation and
+// uploading it to a configured repo, e.g. a Nix User Repository.
+package nix
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const nixConfigExtra = "NixConfig"
+
+var (
+	// ErrNoArchivesFound happens when no linux or macos archives are found.
+	ErrNoArchivesFound = errors.New("no linux/macos archives found")
+
+	// ErrMultipleArchivesSamePlatform happens when the config yields multiple
+	// archives for the same nix system.
+	ErrMultipleArchivesSamePlatform = errors.New("one nix derivation can handle only one archive per platform. Consider using ids in the nix section")
+)
+
+// systems maps the go platforms to the Nix system strings.
+var systems = map[string][]string{
+	"linux386":    {"i686-linux"},
+	"linuxamd64":  {"x86_64-linux"},
+	"linuxarm64":  {"aarch64-linux"},
+	"linuxarm6":   {"armv6l-linux"},
+	"linuxarm7":   {"armv7l-linux"},
+	"darwinamd64": {"x86_64-darwin"},
+	"darwinarm64": {"aarch64-darwin"},
+	"darwinall":   {"x86_64-darwin", "aarch64-darwin"},
+}
+
+// Pipe for nix derivations.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "nix derivations" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Nix) == 0 }
+
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Nix {
+		nix := &ctx.Config.Nix[i]
+
+		if nix.CommitAuthor.Name == "" {
+			nix.CommitAuthor.Name = "goreleaserbot"
+		}
+		if nix.CommitAuthor.Email == "" {
+			nix.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+		if nix.CommitMessageTemplate == "" {
+			nix.CommitMessageTemplate = "Nix derivation update for {{ .ProjectName }} version {{ .Tag }}"
+		}
+		if nix.Name == "" {
+			nix.Name = ctx.Config.ProjectName
+		}
+	}
+	return nil
+}
+
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish nix derivations.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, nix := range ctx.Config.Nix {
+		if err := doRun(ctx, nix, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, nix := range ctx.Artifacts.Filter(artifact.ByType(artifact.Nixpkg)).List() {
+		err := doPublish(ctx, nix, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doPublish(ctx *context.Context, derivation *artifact.Artifact, cl client.Client) error {
+	nix := derivation.Extra[nixConfigExtra].(config.Nix)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, nix.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(nix.SkipUpload) == "true" {
+		return pipe.Skip("nix.skip_upload is set")
+	}
+
+	if strings.TrimSpace(nix.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping nix publish")
+	}
+
+	log.WithField("derivation", nix.Path).
+		WithField("repo", client.RepoFromRef(nix.Repository).String()).
+		Info("pushing")
+
+	msg, err := tmpl.New(ctx).Apply(nix.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(derivation.Path)
+	if err != nil {
+		return err
+	}
+
+	return client.PublishFile(ctx, cl, nix.Repository, nix.CommitAuthor, content, nix.Path, msg)
+}
+
+func doRun(ctx *context.Context, nix config.Nix, cl client.Client) error {
+	if nix.Repository.Name == "" && nix.Repository.Git.URL == "" {
+		return pipe.Skip("nix repository name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.Or(
+			artifact.ByGoos("darwin"),
+			artifact.ByGoos("linux"),
+		),
+		artifact.Or(
+			artifact.ByGoarch("386"),
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("all"),
+			artifact.And(
+				artifact.ByGoarch("arm"),
+				artifact.Or(
+					artifact.ByGoarm("6"),
+					artifact.ByGoarm("7"),
+				),
+			),
+		),
+		artifact.ByFormats("zip", "tar.gz", "tgz", "tar.xz", "txz"),
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.OnlyReplacingUnibins,
+	}
+	if len(nix.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(nix.IDs...))
+	}
+
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(archives) == 0 {
+		return ErrNoArchivesFound
+	}
+
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&nix.Name,
+		&nix.Path,
+		&nix.Description,
+		&nix.Homepage,
+		&nix.Install,
+		&nix.PostInstall,
+		&nix.Repository.Owner,
+		&nix.Repository.Name,
+		&nix.SkipUpload,
+	} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return err
+		}
+	}
+	if nix.Path == "" {
+		nix.Path = path.Join("pkgs", nix.Name, "default.nix")
+	}
+
+	data, err := dataFor(ctx, nix, cl, archives)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := derivationTemplate.Execute(&out, data); err != nil {
+		return err
+	}
+
+	filename := nix.Name + ".nix"
+	dest := filepath.Join(ctx.Config.Dist, "nix", filename)
+	log.WithField("derivation", dest).Info("writing")
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("failed to write nix derivation: %w", err)
+	}
+	if err := os.WriteFile(dest, out.Bytes(), 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to write nix derivation: %w", err)
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: filename,
+		Path: dest,
+		Type: artifact.Nixpkg,
+		Extra: map[string]interface{}{
+			nixConfigExtra: nix,
+		},
+	})
+
+	return nil
+}
+
+func dataFor(ctx *context.Context, nix config.Nix, cl client.Client, archives []*artifact.Artifact) (templateData, error) {
+	data := templateData{
+		Name:        nix.Name,
+		Version:     ctx.Version,
+		Desc:        nix.Description,
+		Homepage:    nix.Homepage,
+		License:     nix.License,
+		PostInstall: nix.PostInstall,
+		Inputs:      []string{"installShellFiles"},
+	}
+
+	if nix.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return data, err
+		}
+		nix.URLTemplate = url
+	}
+
+	var binaries []string
+	hasZip := false
+	seen := map[string]bool{}
+	for _, art := range archives {
+		url, err := tmpl.New(ctx).WithArtifact(art, map[string]string{}).Apply(nix.URLTemplate)
+		if err != nil {
+			return data, err
+		}
+
+		hash, err := sriHash(art)
+		if err != nil {
+			return data, err
+		}
+
+		root := art.ExtraOr(artifact.ExtraWrappedIn, "").(string)
+		if root == "" {
+			root = "."
+		}
+
+		for _, system := range systems[art.Goos+art.Goarch+art.Goarm] {
+			if seen[system] {
+				return data, ErrMultipleArchivesSamePlatform
+			}
+			seen[system] = true
+			data.Platforms = append(data.Platforms, system)
+			data.Sources = append(data.Sources, releasePackage{
+				System:      system,
+				DownloadURL: url,
+				Hash:        hash,
+				SourceRoot:  root,
+			})
+		}
+
+		if art.Format() == "zip" {
+			hasZip = true
+		}
+		if len(binaries) == 0 {
+			binaries = art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string)
+		}
+	}
+
+	if hasZip {
+		data.Inputs = append(data.Inputs, "unzip")
+	}
+	if len(nix.Dependencies) > 0 {
+		data.Inputs = append(data.Inputs, "makeWrapper")
+		data.Deps = nix.Dependencies
+	}
+	sort.Strings(data.Platforms)
+	sort.Slice(data.Sources, func(i, j int) bool {
+		return data.Sources[i].System < data.Sources[j].System
+	})
+
+	data.Install = installPhase(nix, binaries)
+	return data, nil
+}
+
+// installPhase installs the binaries, unless a custom install is set, then
+// the completions and manpages.
+// The binaries it installs are wrapped to find the dependencies in the PATH.
+func installPhase(nix config.Nix, binaries []string) string {
+	var lines []string
+	if nix.Install != "" {
+		lines = append(lines, nix.Install)
+	} else {
+		lines = append(lines, "mkdir -p $out/bin")
+		for _, bin := range binaries {
+			lines = append(lines, fmt.Sprintf("cp -vr ./%s $out/bin/%s", bin, path.Base(bin)))
+		}
+		if len(nix.Dependencies) > 0 {
+			deps := strings.Join(nix.Dependencies, " ")
+			for _, bin := range binaries {
+				lines = append(lines, fmt.Sprintf("wrapProgram $out/bin/%s --prefix PATH : ${lib.makeBinPath [ %s ]}", path.Base(bin), deps))
+			}
+		}
+	}
+	for _, completion := range nix.Completions {
+		lines = append(lines, "installShellCompletion ./"+completion)
+	}
+	for _, manpage := range nix.Manpages {
+		lines = append(lines, "installManPage ./"+manpage)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sriHash returns the sha256 of the artifact in the SRI form used by nix,
+// e.g. "sha256-<base64>".
+func sriHash(art *artifact.Artifact) (string, error) {
+	sum, err := art.Checksum("sha256")
+	if err != nil {
+		return "", err
+	}
+	bts, err := hex.DecodeString(sum)
+	if err != nil {
+		return "", err
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(bts), nil
+}
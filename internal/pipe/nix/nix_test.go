@@ -0,0 +1,244 @@
+package nix
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Nix: []config.Nix{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Nix:         []config.Nix{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Nix{
+		Name:                  "foo",
+		CommitMessageTemplate: "Nix derivation update for {{ .ProjectName }} version {{ .Tag }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.Nix[0])
+}
+
+var defaultPlatforms = []testlib.FakeArchive{
+	{Goos: "linux", Goarch: "amd64"},
+	{Goos: "linux", Goarch: "arm64"},
+	{Goos: "linux", Goarch: "arm", Goarm: "7"},
+	{Goos: "linux", Goarch: "386"},
+	{Goos: "linux", Goarch: "mips"},
+	{Goos: "darwin", Goarch: "amd64"},
+	{Goos: "darwin", Goarch: "arm64"},
+	{Goos: "windows", Goarch: "amd64", Format: "zip"},
+}
+
+func newContext(tb testing.TB, nix config.Nix, platforms []testlib.FakeArchive) *context.Context {
+	tb.Helper()
+	ctx := context.New(config.Project{
+		Dist:        tb.TempDir(),
+		ProjectName: "foo",
+		Nix:         []config.Nix{nix},
+	})
+	testlib.FakeRelease(tb, ctx, "v1.0.1")
+	require.NoError(tb, Pipe{}.Default(ctx))
+	testlib.FakeArchives(tb, ctx, platforms, func(testlib.FakeArchive) artifact.Extras {
+		return artifact.Extras{
+			artifact.ExtraBinaries: []string{"foo"},
+		}
+	})
+	return ctx
+}
+
+func validConfig() config.Nix {
+	return config.Nix{
+		Description:  "A {{ .ProjectName }} tool",
+		Homepage:     "https://example.com/foo",
+		License:      "mit",
+		Completions:  []string{"completions/*"},
+		Manpages:     []string{"manpages/foo.1.gz"},
+		PostInstall:  "mkdir -p $out/share/foo\ncp -r ./config $out/share/foo/",
+		Dependencies: []string{"git", "zsh"},
+		Repository: config.RepoRef{
+			Owner: "foo",
+			Name:  "nur",
+		},
+	}
+}
+
+func TestRunPipe(t *testing.T) {
+	ctx := newContext(t, validConfig(), defaultPlatforms)
+	require.NoError(t, runAll(ctx, client.NewMock()))
+
+	derivations := ctx.Artifacts.Filter(artifact.ByType(artifact.Nixpkg)).List()
+	require.Len(t, derivations, 1)
+	require.Equal(t, "foo.nix", derivations[0].Name)
+	require.Equal(t, "pkgs/foo/default.nix", derivations[0].Extra[nixConfigExtra].(config.Nix).Path)
+
+	bts, err := os.ReadFile(derivations[0].Path)
+	require.NoError(t, err)
+	golden.RequireEqualNix(t, bts)
+}
+
+func TestRunPipeUniversalBinary(t *testing.T) {
+	cfg := validConfig()
+	cfg.Install = "mkdir -p $out/bin\ncp -vr ./bin/foo $out/bin/foo"
+	cfg.Completions = nil
+	cfg.Manpages = nil
+	cfg.PostInstall = ""
+	ctx := newContext(t, cfg, []testlib.FakeArchive{
+		{Goos: "linux", Goarch: "amd64", Format: "zip"},
+		{Goos: "darwin", Goarch: "all", Format: "zip"},
+	})
+	for _, art := range ctx.Artifacts.List() {
+		art.Extra[artifact.ExtraWrappedIn] = "foo_" + art.Goos
+	}
+	require.NoError(t, runAll(ctx, client.NewMock()))
+
+	derivations := ctx.Artifacts.Filter(artifact.ByType(artifact.Nixpkg)).List()
+	require.Len(t, derivations, 1)
+	bts, err := os.ReadFile(derivations[0].Path)
+	require.NoError(t, err)
+	golden.RequireEqualNix(t, bts)
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		modify   func(cfg *config.Nix)
+		expected string
+	}{
+		"no archives": {
+			modify:   func(cfg *config.Nix) { cfg.IDs = []string{"nope"} },
+			expected: ErrNoArchivesFound.Error(),
+		},
+		"invalid template": {
+			modify:   func(cfg *config.Nix) { cfg.Description = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+		"invalid url template": {
+			modify:   func(cfg *config.Nix) { cfg.URLTemplate = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+			ctx := newContext(t, cfg, defaultPlatforms)
+			err := runAll(ctx, client.NewMock())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestRunPipeMultipleArchivesSamePlatform(t *testing.T) {
+	ctx := newContext(t, validConfig(), []testlib.FakeArchive{
+		{Goos: "darwin", Goarch: "arm64"},
+		{Goos: "darwin", Goarch: "all"},
+	})
+	require.Equal(t, ErrMultipleArchivesSamePlatform, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeNoRepository(t *testing.T) {
+	cfg := validConfig()
+	cfg.Repository = config.RepoRef{}
+	ctx := newContext(t, cfg, defaultPlatforms)
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestPublish(t *testing.T) {
+	cfg := validConfig()
+	cfg.Path = "pkgs/{{ .ProjectName }}.nix"
+	ctx := newContext(t, cfg, defaultPlatforms)
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+
+	require.True(t, cli.CreatedFile)
+	require.Equal(t, "pkgs/foo.nix", cli.Path)
+	bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "nix", "foo.nix"))
+	require.NoError(t, err)
+	require.Equal(t, string(bts), cli.Content)
+}
+
+func TestPublishGitRepository(t *testing.T) {
+	bare := filepath.Join(t.TempDir(), "nur.git")
+	work := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", "--initial-branch", "main", bare},
+		{"-C", work, "init", "--initial-branch", "main"},
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgSign=false", "commit", "--allow-empty", "-m", "init"},
+		{"-C", work, "push", bare, "HEAD:main"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	cfg := validConfig()
+	cfg.Repository = config.RepoRef{
+		Git: config.GitRepoRef{
+			URL: "file://" + bare,
+		},
+	}
+	ctx := newContext(t, cfg, defaultPlatforms)
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.False(t, cli.CreatedFile)
+
+	out, err := exec.Command("git", "-C", bare, "show", "main:pkgs/foo/default.nix").CombinedOutput()
+	require.NoError(t, err, string(out))
+	bts, err := os.ReadFile(filepath.Join(ctx.Config.Dist, "nix", "foo.nix"))
+	require.NoError(t, err)
+	require.Equal(t, string(bts), string(out))
+}
+
+func TestPublishSkipUpload(t *testing.T) {
+	for name, tt := range map[string]struct {
+		skipUpload string
+		prerelease string
+	}{
+		"true": {skipUpload: "true"},
+		"auto": {skipUpload: "auto", prerelease: "beta1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.SkipUpload = tt.skipUpload
+			ctx := newContext(t, cfg, defaultPlatforms)
+			ctx.Semver.Prerelease = tt.prerelease
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			testlib.AssertSkipped(t, publishAll(ctx, cli))
+			require.False(t, cli.CreatedFile)
+		})
+	}
+}
+
+func TestSRIHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo")
+	require.NoError(t, os.WriteFile(path, []byte("foo"), 0o644))
+	hash, err := sriHash(&artifact.Artifact{Path: path})
+	require.NoError(t, err)
+	// nix hash to-sri --type sha256 2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae
+	require.Equal(t, "sha256-LCa0a2j/xo/5m0U8HTBBNBNCLXBkg7+g+YpeiGJm564=", hash)
+}
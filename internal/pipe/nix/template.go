@@ -0,0 +1,129 @@
+package nix
+
+import (
+	"strings"
+	"text/template"
+)
+
+type templateData struct {
+	Name        string
+	Version     string
+	Desc        string
+	Homepage    string
+	License     string
+	Install     string
+	PostInstall string
+	Inputs      []string
+	Deps        []string
+	Platforms   []string
+	Sources     []releasePackage
+}
+
+type releasePackage struct {
+	System      string
+	DownloadURL string
+	Hash        string
+	SourceRoot  string
+}
+
+var stringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `${`, `\${`)
+
+var funcs = template.FuncMap{
+	// str quotes s as a nix string.
+	"str": func(s string) string {
+		return `"` + stringReplacer.Replace(s) + `"`
+	},
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		lines := strings.Split(strings.Trim(s, "\n"), "\n")
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				lines[i] = ""
+				continue
+			}
+			lines[i] = pad + strings.TrimRight(line, " \t")
+		}
+		return strings.Join(lines, "\n")
+	},
+}
+
+var derivationTemplate = template.Must(template.New("nix").Funcs(funcs).Parse(`# This file was generated by GoReleaser. DO NOT EDIT.
+# vim: set ft=nix ts=2 sw=2 sts=2 et sta
+{
+system ? builtins.currentSystem
+, lib
+, fetchurl
+{{- range .Inputs }}
+, {{ . }}
+{{- end }}
+{{- range .Deps }}
+, {{ . }}
+{{- end }}
+, stdenvNoCC
+}:
+let
+  shaMap = {
+    {{- range .Sources }}
+    {{ .System }} = {{ str .Hash }};
+    {{- end }}
+  };
+
+  urlMap = {
+    {{- range .Sources }}
+    {{ .System }} = {{ str .DownloadURL }};
+    {{- end }}
+  };
+
+  sourceRootMap = {
+    {{- range .Sources }}
+    {{ .System }} = {{ str .SourceRoot }};
+    {{- end }}
+  };
+in
+stdenvNoCC.mkDerivation {
+  pname = {{ str .Name }};
+  version = {{ str .Version }};
+  src = fetchurl {
+    url = urlMap.${system};
+    sha256 = shaMap.${system};
+  };
+
+  sourceRoot = sourceRootMap.${system};
+
+  nativeBuildInputs = [ {{ range .Inputs }}{{ . }} {{ end }}];
+
+  installPhase = ''
+    runHook preInstall
+{{ indent 4 .Install }}
+    runHook postInstall
+  '';
+  {{- with .PostInstall }}
+
+  postInstall = ''
+{{ indent 4 . }}
+  '';
+  {{- end }}
+
+  system = system;
+
+  meta = {
+    {{- with .Desc }}
+    description = {{ str . }};
+    {{- end }}
+    {{- with .Homepage }}
+    homepage = {{ str . }};
+    {{- end }}
+    {{- with .License }}
+    license = lib.licenses.{{ . }};
+    {{- end }}
+
+    sourceProvenance = [ lib.sourceTypes.binaryNativeCode ];
+
+    platforms = [
+      {{- range .Platforms }}
+      {{ str . }}
+      {{- end }}
+    ];
+  };
+}
+`))
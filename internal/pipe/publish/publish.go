@@ -4,22 +4,28 @@ package publish
 import (
 	"fmt"
 
+	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/internal/middleware/errhandler"
 	"github.com/goreleaser/goreleaser/internal/middleware/logging"
 	"github.com/goreleaser/goreleaser/internal/middleware/skip"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifactory"
+	"github.com/goreleaser/goreleaser/internal/pipe/aur"
 	"github.com/goreleaser/goreleaser/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
+	"github.com/goreleaser/goreleaser/internal/pipe/cask"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
 	"github.com/goreleaser/goreleaser/internal/pipe/custompublishers"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
 	"github.com/goreleaser/goreleaser/internal/pipe/gofish"
 	"github.com/goreleaser/goreleaser/internal/pipe/krew"
 	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/upload"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -45,9 +51,16 @@ var publishers = []Publisher{
 	release.Pipe{},
 	// brew and scoop use the release URL, so, they should be last
 	brew.Pipe{},
+	cask.Pipe{},
 	gofish.Pipe{},
 	krew.Pipe{},
 	scoop.Pipe{},
+	winget.Pipe{},
+	chocolatey.Pipe{},
+	aur.Pipe{},
+	nix.Pipe{},
+	// the files of the package managers above are committed all at once
+	batchCommitter{},
 	milestone.Pipe{},
 }
 
@@ -58,6 +71,7 @@ func (Pipe) String() string                 { return "publishing" }
 func (Pipe) Skip(ctx *context.Context) bool { return ctx.SkipPublish }
 
 func (Pipe) Run(ctx *context.Context) error {
+	client.StartBatch(ctx)
 	for _, publisher := range publishers {
 		if err := skip.Maybe(
 			publisher,
@@ -72,3 +86,13 @@ func (Pipe) Run(ctx *context.Context) error {
 	}
 	return nil
 }
+
+// batchCommitter commits the files published by the package managers,
+// creating a single commit per repository and branch.
+type batchCommitter struct{}
+
+func (batchCommitter) String() string { return "repository updates" }
+
+func (batchCommitter) Publish(ctx *context.Context) error {
+	return client.CommitBatch(ctx)
+}
@@ -156,6 +156,7 @@ func doPublish(ctx *context.Context, client client.Client) error {
 		artifact.ByType(artifact.Certificate),
 		artifact.ByType(artifact.LinuxPackage),
 		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.UploadableDebugSymbols),
 	)
 
 	if len(ctx.Config.Release.IDs) > 0 {
@@ -127,6 +127,39 @@ func TestRunPipeWithoutIDsThenDoesNotFilter(t *testing.T) {
 	require.Contains(t, client.UploadedFileNames, "checksum.sig")
 }
 
+func TestRunPipeDebugSymbols(t *testing.T) {
+	folder := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: folder,
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.DebugSymbols,
+		Name: "foo.debug",
+		Path: createTmpFile(t, folder, "foo.debug"),
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "foo-debug",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableDebugSymbols,
+		Name: "bar.debug",
+		Path: createTmpFile(t, folder, "bar.debug"),
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "bar-debug",
+		},
+	})
+	client := &client.Mock{}
+	require.NoError(t, doPublish(ctx, client))
+	require.Equal(t, []string{"bar.debug"}, client.UploadedFileNames)
+}
+
 func TestRunPipeWithIDsThenFilters(t *testing.T) {
 	folder := t.TempDir()
 	tarfile, err := os.Create(filepath.Join(folder, "bin.tar.gz"))
@@ -14,6 +14,7 @@ import (
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/deprecate"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -23,13 +24,25 @@ import (
 // ErrNoWindows when there is no build for windows (goos doesn't contain windows).
 var ErrNoWindows = errors.New("scoop requires a windows build")
 
+// errAutoUpdateNoCheckVer happens when autoupdate is enabled without a way
+// for scoop to check for new versions.
+var errAutoUpdateNoCheckVer = errors.New("scoop: autoupdate requires checkver to be set")
+
 const scoopConfigExtra = "ScoopConfig"
 
 // Pipe that builds and publishes scoop manifests.
 type Pipe struct{}
 
-func (Pipe) String() string                 { return "scoop manifests" }
-func (Pipe) Skip(ctx *context.Context) bool { return ctx.Config.Scoop.Bucket.Name == "" }
+func (Pipe) String() string { return "scoop manifests" }
+func (Pipe) Skip(ctx *context.Context) bool {
+	return len(ctx.Config.Scoops) == 0 && !hasBucket(ctx.Config.Scoop)
+}
+
+// hasBucket tells whether the scoop has a bucket configured, either in
+// GitHub, GitLab or Gitea, or in any git server.
+func hasBucket(scoop config.Scoop) bool {
+	return scoop.Bucket.Name != "" || scoop.Bucket.Git.URL != ""
+}
 
 // Run creates the scoop manifest locally.
 func (Pipe) Run(ctx *context.Context) error {
@@ -37,7 +50,7 @@ func (Pipe) Run(ctx *context.Context) error {
 	if err != nil {
 		return err
 	}
-	return doRun(ctx, client)
+	return runAll(ctx, client)
 }
 
 // Publish scoop manifest.
@@ -46,47 +59,85 @@ func (Pipe) Publish(ctx *context.Context) error {
 	if err != nil {
 		return err
 	}
-	return doPublish(ctx, client)
+	return publishAll(ctx, client)
 }
 
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
-	if ctx.Config.Scoop.Name == "" {
-		ctx.Config.Scoop.Name = ctx.Config.ProjectName
-	}
-	if ctx.Config.Scoop.CommitAuthor.Name == "" {
-		ctx.Config.Scoop.CommitAuthor.Name = "goreleaserbot"
+	if hasBucket(ctx.Config.Scoop) {
+		deprecate.Notice(ctx, "scoop")
+		ctx.Config.Scoops = append(ctx.Config.Scoops, ctx.Config.Scoop)
+		ctx.Config.Scoop = config.Scoop{}
 	}
-	if ctx.Config.Scoop.CommitAuthor.Email == "" {
-		ctx.Config.Scoop.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+
+	for i := range ctx.Config.Scoops {
+		scoop := &ctx.Config.Scoops[i]
+		if scoop.Name == "" {
+			scoop.Name = ctx.Config.ProjectName
+		}
+		if scoop.CommitAuthor.Name == "" {
+			scoop.CommitAuthor.Name = "goreleaserbot"
+		}
+		if scoop.CommitAuthor.Email == "" {
+			scoop.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+		if scoop.CommitMessageTemplate == "" {
+			scoop.CommitMessageTemplate = "Scoop update for {{ .ProjectName }} version {{ .Tag }}"
+		}
 	}
-	if ctx.Config.Scoop.CommitMessageTemplate == "" {
-		ctx.Config.Scoop.CommitMessageTemplate = "Scoop update for {{ .ProjectName }} version {{ .Tag }}"
+	return nil
+}
+
+func runAll(ctx *context.Context, cl client.Client) error {
+	for _, scoop := range ctx.Config.Scoops {
+		if err := doRun(ctx, scoop, cl); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func doRun(ctx *context.Context, cl client.Client) error {
-	scoop := ctx.Config.Scoop
+func publishAll(ctx *context.Context, cl client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, manifest := range ctx.Artifacts.Filter(artifact.ByType(artifact.ScoopManifest)).List() {
+		err := doPublish(ctx, manifest, cl)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, scoop config.Scoop, cl client.Client) error {
+	if !hasBucket(scoop) {
+		return pipe.Skip("scoop bucket name is not set")
+	}
 
 	// TODO: multiple archives
 	if ctx.Config.Archives[0].Format == "binary" {
 		return pipe.Skip("archive format is binary")
 	}
 
-	archives := ctx.Artifacts.Filter(
-		artifact.And(
-			artifact.ByGoos("windows"),
-			artifact.ByType(artifact.UploadableArchive),
-		),
-	).List()
+	filters := []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.ByType(artifact.UploadableArchive),
+	}
+	if len(scoop.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(scoop.IDs...))
+	}
+
+	archives := ctx.Artifacts.Filter(artifact.And(filters...)).List()
 	if len(archives) == 0 {
 		return ErrNoWindows
 	}
 
 	filename := scoop.Name + ".json"
 
-	data, err := dataFor(ctx, cl, archives)
+	data, err := dataFor(ctx, scoop, cl, archives)
 	if err != nil {
 		return err
 	}
@@ -112,13 +163,7 @@ func doRun(ctx *context.Context, cl client.Client) error {
 	return nil
 }
 
-func doPublish(ctx *context.Context, cl client.Client) error {
-	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.ScoopManifest)).List()
-	if len(manifests) == 0 { // should never happen
-		return nil
-	}
-
-	manifest := manifests[0]
+func doPublish(ctx *context.Context, manifest *artifact.Artifact, cl client.Client) error {
 	scoop := manifest.Extra[scoopConfigExtra].(config.Scoop)
 
 	var err error
@@ -150,11 +195,11 @@ func doPublish(ctx *context.Context, cl client.Client) error {
 		return err
 	}
 
-	repo := client.RepoFromRef(scoop.Bucket)
-	return cl.CreateFile(
+	return client.PublishFile(
 		ctx,
+		cl,
+		scoop.Bucket,
 		scoop.CommitAuthor,
-		repo,
 		content,
 		path.Join(scoop.Folder, manifest.Name),
 		commitMessage,
@@ -169,16 +214,41 @@ type Manifest struct {
 	Homepage     string              `json:"homepage,omitempty"`     // `homepage`: The home page for the program.
 	License      string              `json:"license,omitempty"`      // `license`: The software license for the program. For well-known licenses, this will be a string like "MIT" or "GPL2". For custom licenses, this should be the URL of the license.
 	Description  string              `json:"description,omitempty"`  // Description of the app
+	Depends      []string            `json:"depends,omitempty"`      // Other apps this app depends on
 	Persist      []string            `json:"persist,omitempty"`      // Persist data between updates
 	PreInstall   []string            `json:"pre_install,omitempty"`  // An array of strings, of the commands to be executed before an application is installed.
 	PostInstall  []string            `json:"post_install,omitempty"` // An array of strings, of the commands to be executed after an application is installed.
+	CheckVer     *CheckVer           `json:"checkver,omitempty"`     // How to check for new versions of the app
+	AutoUpdate   *AutoUpdate         `json:"autoupdate,omitempty"`   // How to update the manifest for new versions of the app
 }
 
 // Resource represents a combination of a url and a binary name for an architecture.
 type Resource struct {
-	URL  string   `json:"url"`  // URL to the archive
-	Bin  []string `json:"bin"`  // name of binary inside the archive
-	Hash string   `json:"hash"` // the archive checksum
+	URL        string        `json:"url"`                   // URL to the archive
+	Bin        []interface{} `json:"bin"`                   // name of binary inside the archive, or a [binary, alias, args] shim
+	Hash       string        `json:"hash"`                  // the archive checksum
+	ExtractDir string        `json:"extract_dir,omitempty"` // the directory inside the archive to extract
+	Shortcuts  [][]string    `json:"shortcuts,omitempty"`   // start menu shortcuts, as [binary, name] pairs
+}
+
+// CheckVer represents how scoop checks for new versions of an app.
+type CheckVer struct {
+	GitHub   string `json:"github,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	JSONPath string `json:"jsonpath,omitempty"`
+}
+
+// AutoUpdate represents how scoop updates the manifest for a new version.
+type AutoUpdate struct {
+	Architecture map[string]AutoUpdateResource `json:"architecture"`
+}
+
+// AutoUpdateResource is the url and extract dir of an architecture, with the
+// version replaced by the `$version` variable.
+type AutoUpdateResource struct {
+	URL        string `json:"url"`
+	ExtractDir string `json:"extract_dir,omitempty"`
 }
 
 func doBuildManifest(manifest Manifest) (bytes.Buffer, error) {
@@ -191,76 +261,122 @@ func doBuildManifest(manifest Manifest) (bytes.Buffer, error) {
 	return result, err
 }
 
-func dataFor(ctx *context.Context, cl client.Client, artifacts []*artifact.Artifact) (Manifest, error) {
+func dataFor(ctx *context.Context, scoop config.Scoop, cl client.Client, artifacts []*artifact.Artifact) (Manifest, error) {
 	manifest := Manifest{
 		Version:      ctx.Version,
 		Architecture: map[string]Resource{},
-		Homepage:     ctx.Config.Scoop.Homepage,
-		License:      ctx.Config.Scoop.License,
-		Description:  ctx.Config.Scoop.Description,
-		Persist:      ctx.Config.Scoop.Persist,
-		PreInstall:   ctx.Config.Scoop.PreInstall,
-		PostInstall:  ctx.Config.Scoop.PostInstall,
+		Homepage:     scoop.Homepage,
+		License:      scoop.License,
+		Description:  scoop.Description,
+		Depends:      scoop.Depends,
+		Persist:      scoop.Persist,
+		PreInstall:   scoop.PreInstall,
+		PostInstall:  scoop.PostInstall,
 	}
 
-	if ctx.Config.Scoop.URLTemplate == "" {
+	if scoop.CheckVer != (config.ScoopCheckVer{}) {
+		manifest.CheckVer = &CheckVer{
+			GitHub:   scoop.CheckVer.GitHub,
+			URL:      scoop.CheckVer.URL,
+			Regex:    scoop.CheckVer.Regex,
+			JSONPath: scoop.CheckVer.JSONPath,
+		}
+	}
+	if scoop.AutoUpdate {
+		if manifest.CheckVer == nil {
+			return manifest, errAutoUpdateNoCheckVer
+		}
+		manifest.AutoUpdate = &AutoUpdate{
+			Architecture: map[string]AutoUpdateResource{},
+		}
+	}
+
+	if scoop.URLTemplate == "" {
 		url, err := cl.ReleaseURLTemplate(ctx)
 		if err != nil {
 			return manifest, err
 		}
-		ctx.Config.Scoop.URLTemplate = url
+		scoop.URLTemplate = url
 	}
 
-	for _, artifact := range artifacts {
-		if artifact.Goos != "windows" {
+	for _, art := range artifacts {
+		if art.Goos != "windows" {
 			continue
 		}
 
 		var arch string
 		switch {
-		case artifact.Goarch == "386":
+		case art.Goarch == "386":
 			arch = "32bit"
-		case artifact.Goarch == "amd64":
+		case art.Goarch == "amd64":
 			arch = "64bit"
 		default:
 			continue
 		}
 
 		url, err := tmpl.New(ctx).
-			WithArtifact(artifact, map[string]string{}).
-			Apply(ctx.Config.Scoop.URLTemplate)
+			WithArtifact(art, map[string]string{}).
+			Apply(scoop.URLTemplate)
 		if err != nil {
 			return manifest, err
 		}
 
-		sum, err := artifact.Checksum("sha256")
+		sum, err := art.Checksum("sha256")
 		if err != nil {
 			return manifest, err
 		}
 
 		log.WithFields(log.Fields{
-			"artifactExtras":   artifact.Extra,
-			"fromURLTemplate":  ctx.Config.Scoop.URLTemplate,
+			"artifactExtras":   art.Extra,
+			"fromURLTemplate":  scoop.URLTemplate,
 			"templatedBrewURL": url,
 			"sum":              sum,
 		}).Debug("scoop url templating")
 
-		manifest.Architecture[arch] = Resource{
-			URL:  url,
-			Bin:  binaries(artifact),
-			Hash: sum,
+		resource := Resource{
+			URL:        url,
+			Bin:        binaries(art, scoop.BinAliases),
+			Hash:       sum,
+			ExtractDir: art.ExtraOr(artifact.ExtraWrappedIn, "").(string),
+			Shortcuts:  scoop.Shortcuts,
+		}
+		manifest.Architecture[arch] = resource
+
+		if manifest.AutoUpdate != nil {
+			manifest.AutoUpdate.Architecture[arch] = AutoUpdateResource{
+				URL:        strings.ReplaceAll(resource.URL, ctx.Version, "$version"),
+				ExtractDir: strings.ReplaceAll(resource.ExtractDir, ctx.Version, "$version"),
+			}
 		}
 	}
 
 	return manifest, nil
 }
 
-func binaries(a *artifact.Artifact) []string {
+// binaries returns the bin entries of the archive, which are relative to its
+// extract dir.
+// Binaries with aliases are shimmed as [binary, alias] or
+// [binary, alias, args] instead.
+func binaries(a *artifact.Artifact, aliases []config.ScoopBinAlias) []interface{} {
 	// nolint: prealloc
-	var bins []string
-	wrap := a.ExtraOr(artifact.ExtraWrappedIn, "").(string)
+	var bins []interface{}
 	for _, b := range a.ExtraOr(artifact.ExtraBuilds, []*artifact.Artifact{}).([]*artifact.Artifact) {
-		bins = append(bins, filepath.Join(wrap, b.Name))
+		var shims []interface{}
+		for _, alias := range aliases {
+			if strings.TrimSuffix(alias.Binary, ".exe") != strings.TrimSuffix(b.Name, ".exe") {
+				continue
+			}
+			shim := []string{b.Name, alias.Alias}
+			if alias.Args != "" {
+				shim = append(shim, alias.Args)
+			}
+			shims = append(shims, shim)
+		}
+		if len(shims) == 0 {
+			bins = append(bins, b.Name)
+			continue
+		}
+		bins = append(bins, shims...)
 	}
 	return bins
 }
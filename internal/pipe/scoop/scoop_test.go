@@ -26,6 +26,7 @@ func TestDefault(t *testing.T) {
 		TokenType: context.TokenTypeGitHub,
 		Config: config.Project{
 			ProjectName: "barr",
+			Scoops:      []config.Scoop{{}},
 			Builds: []config.Build{
 				{
 					Binary: "foo",
@@ -49,10 +50,10 @@ func TestDefault(t *testing.T) {
 		},
 	}
 	require.NoError(t, Pipe{}.Default(ctx))
-	require.Equal(t, ctx.Config.ProjectName, ctx.Config.Scoop.Name)
-	require.NotEmpty(t, ctx.Config.Scoop.CommitAuthor.Name)
-	require.NotEmpty(t, ctx.Config.Scoop.CommitAuthor.Email)
-	require.NotEmpty(t, ctx.Config.Scoop.CommitMessageTemplate)
+	require.Equal(t, ctx.Config.ProjectName, ctx.Config.Scoops[0].Name)
+	require.NotEmpty(t, ctx.Config.Scoops[0].CommitAuthor.Name)
+	require.NotEmpty(t, ctx.Config.Scoops[0].CommitAuthor.Email)
+	require.NotEmpty(t, ctx.Config.Scoops[0].CommitMessageTemplate)
 }
 
 func Test_doRun(t *testing.T) {
@@ -114,7 +115,7 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
@@ -122,7 +123,7 @@ func Test_doRun(t *testing.T) {
 							Folder:      "scoops",
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -162,14 +163,14 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -223,14 +224,14 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -270,14 +271,14 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://gitlab.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -325,14 +326,14 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://gitlab.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -379,14 +380,14 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -420,14 +421,14 @@ func Test_doRun(t *testing.T) {
 						Release: config.Release{
 							Draft: true,
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -470,7 +471,7 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							SkipUpload: "auto",
 							Bucket: config.RepoRef{
 								Owner: "test",
@@ -478,7 +479,7 @@ func Test_doRun(t *testing.T) {
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -515,7 +516,7 @@ func Test_doRun(t *testing.T) {
 								Name:  "test",
 							},
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							SkipUpload: "true",
 							Bucket: config.RepoRef{
 								Owner: "test",
@@ -523,7 +524,7 @@ func Test_doRun(t *testing.T) {
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -557,14 +558,14 @@ func Test_doRun(t *testing.T) {
 						Release: config.Release{
 							Disable: true,
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -598,14 +599,14 @@ func Test_doRun(t *testing.T) {
 						Release: config.Release{
 							Draft: true,
 						},
-						Scoop: config.Scoop{
+						Scoops: []config.Scoop{{
 							Bucket: config.RepoRef{
 								Owner: "test",
 								Name:  "test",
 							},
 							Description: "A run pipe test formula",
 							Homepage:    "https://github.com/goreleaser",
-						},
+						}},
 					},
 				},
 				client.NewMock(),
@@ -629,8 +630,8 @@ func Test_doRun(t *testing.T) {
 			}
 			require.NoError(t, Pipe{}.Default(ctx))
 
-			tt.assertRunError(t, doRun(ctx, tt.args.client))
-			tt.assertPublishError(t, doPublish(ctx, tt.args.client))
+			tt.assertRunError(t, runAll(ctx, tt.args.client))
+			tt.assertPublishError(t, publishAll(ctx, tt.args.client))
 			tt.assert(t, tt.args)
 		})
 	}
@@ -670,7 +671,7 @@ func Test_buildManifest(t *testing.T) {
 							Name:  "test",
 						},
 					},
-					Scoop: config.Scoop{
+					Scoops: []config.Scoop{{
 						Bucket: config.RepoRef{
 							Owner: "test",
 							Name:  "test",
@@ -678,7 +679,7 @@ func Test_buildManifest(t *testing.T) {
 						Description: "A run pipe test formula",
 						Homepage:    "https://github.com/goreleaser",
 						Persist:     []string{"data", "config", "test.ini"},
-					},
+					}},
 				},
 			},
 		},
@@ -707,7 +708,7 @@ func Test_buildManifest(t *testing.T) {
 							Name:  "test",
 						},
 					},
-					Scoop: config.Scoop{
+					Scoops: []config.Scoop{{
 						Bucket: config.RepoRef{
 							Owner: "test",
 							Name:  "test",
@@ -717,7 +718,7 @@ func Test_buildManifest(t *testing.T) {
 						Persist:     []string{"data", "config", "test.ini"},
 						PreInstall:  []string{"Write-Host 'Running preinstall command'"},
 						PostInstall: []string{"Write-Host 'Running postinstall command'"},
-					},
+					}},
 				},
 			},
 		},
@@ -749,7 +750,7 @@ func Test_buildManifest(t *testing.T) {
 							Name:  "test",
 						},
 					},
-					Scoop: config.Scoop{
+					Scoops: []config.Scoop{{
 						Bucket: config.RepoRef{
 							Owner: "test",
 							Name:  "test",
@@ -759,7 +760,7 @@ func Test_buildManifest(t *testing.T) {
 						URLTemplate:           "http://github.mycompany.com/foo/bar/{{ .Tag }}/{{ .ArtifactName }}",
 						CommitMessageTemplate: "chore(scoop): update {{ .ProjectName }} version {{ .Tag }}",
 						Persist:               []string{"data.cfg", "etc"},
-					},
+					}},
 				},
 			},
 		},
@@ -791,7 +792,7 @@ func Test_buildManifest(t *testing.T) {
 							Name:  "test",
 						},
 					},
-					Scoop: config.Scoop{
+					Scoops: []config.Scoop{{
 						Bucket: config.RepoRef{
 							Owner: "test",
 							Name:  "test",
@@ -801,7 +802,7 @@ func Test_buildManifest(t *testing.T) {
 						URLTemplate:           "http://gitlab.mycompany.com/foo/bar/-/releases/{{ .Tag }}/downloads/{{ .ArtifactName }}",
 						CommitMessageTemplate: "chore(scoop): update {{ .ProjectName }} version {{ .Tag }}",
 						Persist:               []string{"data.cfg", "etc"},
-					},
+					}},
 				},
 			},
 		},
@@ -816,7 +817,7 @@ func Test_buildManifest(t *testing.T) {
 			cl, err := client.New(ctx)
 			require.NoError(t, err)
 
-			mf, err := dataFor(ctx, cl, []*artifact.Artifact{
+			mf, err := dataFor(ctx, ctx.Config.Scoops[0], cl, []*artifact.Artifact{
 				{
 					Name:   "foo_1.0.1_windows_amd64.tar.gz",
 					Goos:   "windows",
@@ -892,7 +893,7 @@ func getScoopPipeSkipCtx(folder string) (*context.Context, string) {
 			},
 			Dist:        folder,
 			ProjectName: "run-pipe",
-			Scoop: config.Scoop{
+			Scoops: []config.Scoop{{
 				Bucket: config.RepoRef{
 					Owner: "test",
 					Name:  "test",
@@ -900,7 +901,7 @@ func getScoopPipeSkipCtx(folder string) (*context.Context, string) {
 				Description: "A run pipe test formula",
 				Homepage:    "https://github.com/goreleaser",
 				Name:        "run-pipe",
-			},
+			}},
 		},
 	}
 
@@ -924,17 +925,17 @@ func getScoopPipeSkipCtx(folder string) (*context.Context, string) {
 func TestRunPipeScoopWithSkipUpload(t *testing.T) {
 	folder := t.TempDir()
 	ctx, path := getScoopPipeSkipCtx(folder)
-	ctx.Config.Scoop.SkipUpload = "true"
+	ctx.Config.Scoops[0].SkipUpload = "true"
 
 	f, err := os.Create(path)
 	require.NoError(t, err)
 	require.NoError(t, f.Close())
 
 	cli := client.NewMock()
-	require.NoError(t, doRun(ctx, cli))
-	require.EqualError(t, doPublish(ctx, cli), `scoop.skip_upload is true`)
+	require.NoError(t, runAll(ctx, cli))
+	require.EqualError(t, publishAll(ctx, cli), `scoop.skip_upload is true`)
 
-	distFile := filepath.Join(folder, ctx.Config.Scoop.Name+".json")
+	distFile := filepath.Join(folder, ctx.Config.Scoops[0].Name+".json")
 	_, err = os.Stat(distFile)
 	require.NoError(t, err, "file should exist: "+distFile)
 }
@@ -968,7 +969,7 @@ func TestWrapInDirectory(t *testing.T) {
 					Name:  "test",
 				},
 			},
-			Scoop: config.Scoop{
+			Scoops: []config.Scoop{{
 				Bucket: config.RepoRef{
 					Owner: "test",
 					Name:  "test",
@@ -978,13 +979,13 @@ func TestWrapInDirectory(t *testing.T) {
 				URLTemplate:           "http://gitlab.mycompany.com/foo/bar/-/releases/{{ .Tag }}/downloads/{{ .ArtifactName }}",
 				CommitMessageTemplate: "chore(scoop): update {{ .ProjectName }} version {{ .Tag }}",
 				Persist:               []string{"data.cfg", "etc"},
-			},
+			}},
 		},
 	}
 	require.NoError(t, Pipe{}.Default(ctx))
 	cl, err := client.New(ctx)
 	require.NoError(t, err)
-	mf, err := dataFor(ctx, cl, []*artifact.Artifact{
+	mf, err := dataFor(ctx, ctx.Config.Scoops[0], cl, []*artifact.Artifact{
 		{
 			Name:   "foo_1.0.1_windows_amd64.tar.gz",
 			Goos:   "windows",
@@ -1017,12 +1018,158 @@ func TestSkip(t *testing.T) {
 
 	t.Run("dont skip", func(t *testing.T) {
 		ctx := context.New(config.Project{
-			Scoop: config.Scoop{
+			Scoops: []config.Scoop{{
 				Bucket: config.RepoRef{
 					Name: "a",
 				},
-			},
+			}},
 		})
 		require.False(t, Pipe{}.Skip(ctx))
 	})
 }
+
+func TestDefaultDeprecatedScoop(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Scoop: config.Scoop{
+			Bucket: config.RepoRef{
+				Owner: "foo",
+				Name:  "scoop-bucket",
+			},
+		},
+	})
+	require.False(t, Pipe{}.Skip(ctx))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.True(t, ctx.Deprecated)
+	require.Len(t, ctx.Config.Scoops, 1)
+	require.Equal(t, "scoop-bucket", ctx.Config.Scoops[0].Bucket.Name)
+	require.Equal(t, "foo", ctx.Config.Scoops[0].Name)
+	require.Equal(t, config.Scoop{}, ctx.Config.Scoop)
+}
+
+func TestDefaultDeprecatedScoopGitBucket(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Scoop: config.Scoop{
+			Bucket: config.RepoRef{
+				Git: config.GitRepoRef{
+					URL: "git@example.com:foo/scoop-bucket.git",
+				},
+			},
+		},
+	})
+	require.False(t, Pipe{}.Skip(ctx))
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.True(t, ctx.Deprecated)
+	require.Len(t, ctx.Config.Scoops, 1)
+	require.Equal(t, "git@example.com:foo/scoop-bucket.git", ctx.Config.Scoops[0].Bucket.Git.URL)
+	require.Equal(t, config.Scoop{}, ctx.Config.Scoop)
+}
+
+func newMultipleContext(tb testing.TB, scoops ...config.Scoop) *context.Context {
+	tb.Helper()
+	folder := tb.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Archives:    []config.Archive{{Format: "zip"}},
+		Scoops:      scoops,
+	})
+	testlib.FakeRelease(tb, ctx, "v1.0.1")
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	var archives []testlib.FakeArchive
+	for _, id := range []string{"foo", "bar"} {
+		for _, goarch := range []string{"386", "amd64"} {
+			archives = append(archives, testlib.FakeArchive{ID: id, Goos: "windows", Goarch: goarch, Format: "zip"})
+		}
+	}
+	testlib.FakeArchives(tb, ctx, archives, func(a testlib.FakeArchive) artifact.Extras {
+		return artifact.Extras{
+			artifact.ExtraWrappedIn: a.ID + "_windows_" + a.Goarch,
+			artifact.ExtraBuilds: []*artifact.Artifact{
+				{Name: a.ID + ".exe"},
+			},
+		}
+	})
+	return ctx
+}
+
+func TestRunPipeMultipleScoops(t *testing.T) {
+	bucket := config.RepoRef{
+		Owner: "test",
+		Name:  "scoop-bucket",
+	}
+	ctx := newMultipleContext(t,
+		config.Scoop{Name: "foo", IDs: []string{"foo"}, Bucket: bucket},
+		config.Scoop{Name: "bar", IDs: []string{"bar"}, Bucket: bucket},
+	)
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+
+	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.ScoopManifest)).List()
+	require.Len(t, manifests, 2)
+	for i, tt := range []struct {
+		name  string
+		other string
+	}{
+		{"foo", "bar"},
+		{"bar", "foo"},
+	} {
+		require.Equal(t, tt.name+".json", manifests[i].Name)
+		bts, err := os.ReadFile(manifests[i].Path)
+		require.NoError(t, err)
+		require.Contains(t, string(bts), tt.name+"_windows_amd64.zip")
+		require.NotContains(t, string(bts), tt.other+"_windows")
+	}
+
+	require.NoError(t, publishAll(ctx, cli))
+	require.Equal(t, "bar.json", cli.Path)
+}
+
+func TestFullManifest(t *testing.T) {
+	ctx := newMultipleContext(t, config.Scoop{
+		Name: "foo",
+		IDs:  []string{"foo"},
+		Bucket: config.RepoRef{
+			Owner: "test",
+			Name:  "scoop-bucket",
+		},
+		Description: "A foo tool",
+		Homepage:    "https://example.com/foo",
+		License:     "MIT",
+		Depends:     []string{"git", "extras/vcredist2022"},
+		Shortcuts:   [][]string{{"foo.exe", "Foo"}},
+		BinAliases: []config.ScoopBinAlias{
+			{Binary: "foo", Alias: "foo"},
+			{Binary: "foo.exe", Alias: "f", Args: "--verbose"},
+		},
+		CheckVer: config.ScoopCheckVer{
+			GitHub: "https://github.com/test/foo",
+		},
+		AutoUpdate: true,
+	})
+	require.NoError(t, runAll(ctx, client.NewMock()))
+
+	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.ScoopManifest)).List()
+	require.Len(t, manifests, 1)
+	bts, err := os.ReadFile(manifests[0].Path)
+	require.NoError(t, err)
+	golden.RequireEqualJSON(t, bts)
+}
+
+func TestRunPipeAutoUpdateNoCheckVer(t *testing.T) {
+	ctx := newMultipleContext(t, config.Scoop{
+		Bucket: config.RepoRef{
+			Owner: "test",
+			Name:  "scoop-bucket",
+		},
+		AutoUpdate: true,
+	})
+	require.Equal(t, errAutoUpdateNoCheckVer, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeNoBucket(t *testing.T) {
+	ctx := newMultipleContext(t, config.Scoop{})
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
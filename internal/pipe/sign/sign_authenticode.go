@@ -0,0 +1,134 @@
+package sign
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/authenticode"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// AuthenticodePipe that signs windows binaries with Authenticode.
+type AuthenticodePipe struct{}
+
+func (AuthenticodePipe) String() string { return "signing windows binaries" }
+
+func (AuthenticodePipe) Skip(ctx *context.Context) bool {
+	return ctx.SkipSign || len(ctx.Config.WindowsSigns) == 0
+}
+
+// Default sets the Pipes defaults.
+func (AuthenticodePipe) Default(ctx *context.Context) error {
+	ids := ids.New("windows_signs")
+	for i := range ctx.Config.WindowsSigns {
+		cfg := &ctx.Config.WindowsSigns[i]
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// Run executes the Pipe.
+func (AuthenticodePipe) Run(ctx *context.Context) error {
+	for _, cfg := range ctx.Config.WindowsSigns {
+		signer, err := authenticodeSigner(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("windows sign failed: %s: %w", cfg.ID, err)
+		}
+
+		filters := []artifact.Filter{
+			artifact.Or(
+				artifact.ByType(artifact.Binary),
+				artifact.ByType(artifact.UploadableBinary),
+			),
+			artifact.ByGoos("windows"),
+		}
+		if len(cfg.IDs) > 0 {
+			filters = append(filters, artifact.ByIDs(cfg.IDs...))
+		}
+
+		// uploadable binaries usually share the path of the binary they
+		// were created from, so we only sign each file once.
+		seen := map[string]bool{}
+		g := semerrgroup.New(ctx.Parallelism)
+		for _, art := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+			art := art
+			if seen[art.Path] {
+				continue
+			}
+			seen[art.Path] = true
+			g.Go(func() error {
+				log.WithField("binary", art.Path).Info("signing")
+				if err := signer.SignFile(art.Path); err != nil {
+					return fmt.Errorf("windows sign failed: %w", err)
+				}
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func authenticodeSigner(ctx *context.Context, cfg config.WindowsSign) (authenticode.Signer, error) {
+	var signer authenticode.Signer
+	t := tmpl.New(ctx)
+	fields := []*string{
+		&cfg.Certificate,
+		&cfg.Key,
+		&cfg.Password,
+		&cfg.TimestampURL,
+		&cfg.Description,
+		&cfg.URL,
+	}
+	for _, field := range fields {
+		s, err := t.Apply(*field)
+		if err != nil {
+			return signer, err
+		}
+		*field = s
+	}
+
+	if cfg.Certificate == "" {
+		return signer, errors.New("certificate is required")
+	}
+	cert, err := os.ReadFile(cfg.Certificate)
+	if err != nil {
+		return signer, err
+	}
+
+	switch strings.ToLower(filepath.Ext(cfg.Certificate)) {
+	case ".p12", ".pfx":
+		signer.Certificates, signer.Key, err = authenticode.LoadPKCS12(cert, cfg.Password)
+	default:
+		key := cert
+		if cfg.Key != "" {
+			key, err = os.ReadFile(cfg.Key)
+			if err != nil {
+				return signer, err
+			}
+		}
+		signer.Certificates, signer.Key, err = authenticode.LoadPEM(cert, key)
+	}
+	if err != nil {
+		return signer, err
+	}
+
+	signer.TimestampURL = cfg.TimestampURL
+	signer.Description = cfg.Description
+	signer.URL = cfg.URL
+	return signer, nil
+}
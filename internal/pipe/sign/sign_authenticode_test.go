@@ -0,0 +1,216 @@
+package sign
+
+import (
+	"debug/pe"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const authenticodeTestdata = "../../authenticode/testdata"
+
+func TestAuthenticodeDescription(t *testing.T) {
+	require.NotEmpty(t, AuthenticodePipe{}.String())
+}
+
+func TestAuthenticodeSkip(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		require.True(t, AuthenticodePipe{}.Skip(context.New(config.Project{})))
+	})
+
+	t.Run("skip sign", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			WindowsSigns: []config.WindowsSign{{}},
+		})
+		ctx.SkipSign = true
+		require.True(t, AuthenticodePipe{}.Skip(ctx))
+	})
+
+	t.Run("dont skip", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			WindowsSigns: []config.WindowsSign{{}},
+		})
+		require.False(t, AuthenticodePipe{}.Skip(ctx))
+	})
+}
+
+func TestAuthenticodeDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		WindowsSigns: []config.WindowsSign{{}},
+	})
+	require.NoError(t, AuthenticodePipe{}.Default(ctx))
+	require.Equal(t, "default", ctx.Config.WindowsSigns[0].ID)
+}
+
+func TestAuthenticodeDefaultDuplicatedIDs(t *testing.T) {
+	ctx := context.New(config.Project{
+		WindowsSigns: []config.WindowsSign{{}, {}},
+	})
+	require.EqualError(t, AuthenticodePipe{}.Default(ctx), "found 2 windows_signs with the ID 'default', please fix your config")
+}
+
+func buildWindowsBinary(tb testing.TB, dir string) string {
+	tb.Helper()
+	path := filepath.Join(dir, "app.exe")
+	testlib.BuildBinary(tb, "windows", "amd64", path, nil)
+	return path
+}
+
+func requireSigned(tb testing.TB, path string, signed bool) {
+	tb.Helper()
+	f, err := pe.Open(path)
+	require.NoError(tb, err)
+	defer f.Close()
+	dir := f.OptionalHeader.(*pe.OptionalHeader64).DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_SECURITY]
+	if signed {
+		require.NotZero(tb, dir.Size, "%s is not signed", path)
+	} else {
+		require.Zero(tb, dir.Size, "%s is signed", path)
+	}
+}
+
+func TestAuthenticodeRun(t *testing.T) {
+	for name, cfg := range map[string]config.WindowsSign{
+		"pem": {
+			Certificate: filepath.Join(authenticodeTestdata, "cert.pem"),
+			Key:         filepath.Join(authenticodeTestdata, "key.pem"),
+			Description: "{{ .ProjectName }}",
+			URL:         "https://goreleaser.com",
+		},
+		"pkcs12": {
+			Certificate: filepath.Join(authenticodeTestdata, "cert.p12"),
+			Password:    "{{ .Env.P12_PASSWORD }}",
+		},
+	} {
+		cfg := cfg
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			signed := buildWindowsBinary(t, filepath.Join(folder, "signed"))
+			other := buildWindowsBinary(t, filepath.Join(folder, "other"))
+
+			cfg.IDs = []string{"foo"}
+			ctx := context.New(config.Project{
+				ProjectName:  "myapp",
+				WindowsSigns: []config.WindowsSign{cfg},
+			})
+			ctx.Env["P12_PASSWORD"] = "secret"
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "app.exe",
+				Path:   signed,
+				Goos:   "windows",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					artifact.ExtraID: "foo",
+				},
+			})
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "app.exe",
+				Path:   signed,
+				Goos:   "windows",
+				Goarch: "amd64",
+				Type:   artifact.UploadableBinary,
+				Extra: map[string]interface{}{
+					artifact.ExtraID: "foo",
+				},
+			})
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "app.exe",
+				Path:   other,
+				Goos:   "windows",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					artifact.ExtraID: "bar",
+				},
+			})
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "app",
+				Path:   filepath.Join(folder, "linux"),
+				Goos:   "linux",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					artifact.ExtraID: "foo",
+				},
+			})
+
+			require.NoError(t, AuthenticodePipe{}.Default(ctx))
+			require.NoError(t, AuthenticodePipe{}.Run(ctx))
+			requireSigned(t, signed, true)
+			requireSigned(t, other, false)
+		})
+	}
+}
+
+func TestAuthenticodeRunErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg config.WindowsSign
+		err string
+	}{
+		"no certificate": {
+			cfg: config.WindowsSign{},
+			err: "windows sign failed: default: certificate is required",
+		},
+		"missing certificate": {
+			cfg: config.WindowsSign{Certificate: "testdata/nope.pem"},
+			err: "windows sign failed: default: open testdata/nope.pem: no such file or directory",
+		},
+		"wrong password": {
+			cfg: config.WindowsSign{
+				Certificate: filepath.Join(authenticodeTestdata, "cert.p12"),
+				Password:    "wrong",
+			},
+			err: "windows sign failed: default: failed to decode pkcs12: pkcs12: decryption password incorrect",
+		},
+		"key does not match": {
+			cfg: config.WindowsSign{
+				Certificate: filepath.Join(authenticodeTestdata, "cert.pem"),
+				Key:         filepath.Join(authenticodeTestdata, "ec_key.pem"),
+			},
+			err: "windows sign failed: default: no certificate matches the private key",
+		},
+		"invalid template": {
+			cfg: config.WindowsSign{
+				Certificate: "{{ .Nope }",
+			},
+			err: `windows sign failed: default: template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				WindowsSigns: []config.WindowsSign{tt.cfg},
+			})
+			require.NoError(t, AuthenticodePipe{}.Default(ctx))
+			require.EqualError(t, AuthenticodePipe{}.Run(ctx), tt.err)
+		})
+	}
+}
+
+func TestAuthenticodeRunNotAPE(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.exe")
+	require.NoError(t, os.WriteFile(path, []byte("not really a windows binary, just some text in a file"), 0o644))
+
+	ctx := context.New(config.Project{
+		WindowsSigns: []config.WindowsSign{{
+			Certificate: filepath.Join(authenticodeTestdata, "cert.pem"),
+			Key:         filepath.Join(authenticodeTestdata, "key.pem"),
+		}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "app.exe",
+		Path:   path,
+		Goos:   "windows",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
+	require.NoError(t, AuthenticodePipe{}.Default(ctx))
+	require.EqualError(t, AuthenticodePipe{}.Run(ctx), "windows sign failed: "+path+": not a PE file")
+}
@@ -0,0 +1,290 @@
+// Package sizebudget checks the size of the built binaries against absolute
+// limits and against the previous release.
+package sizebudget
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe that checks the binaries sizes.
+type Pipe struct{}
+
+func (Pipe) String() string { return "checking binaries sizes" }
+
+func enabled(budget config.SizeBudget) bool {
+	return budget.Previous != "" || budget.MaxSize != ""
+}
+
+// previousArtifact is an artifact as stored in a previous artifacts.json.
+type previousArtifact struct {
+	Name   string                 `json:"name"`
+	Path   string                 `json:"path"`
+	Goos   string                 `json:"goos"`
+	Goarch string                 `json:"goarch"`
+	Goarm  string                 `json:"goarm"`
+	Gomips string                 `json:"gomips"`
+	Type   string                 `json:"type"`
+	Extra  map[string]interface{} `json:"extra"`
+}
+
+type row struct {
+	build    string
+	target   string
+	size     int64
+	previous int64
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	// the size of all binaries is stored, so the artifacts.json of this
+	// release can be used as the previous one of the next release.
+	for _, bin := range ctx.Artifacts.Filter(artifact.ByType(artifact.Binary)).List() {
+		stat, err := os.Stat(bin.Path)
+		if err != nil {
+			return err
+		}
+		bin.Extra[artifact.ExtraSize] = stat.Size()
+	}
+
+	previousCache := map[string][]previousArtifact{}
+	var rows []row
+	var failures int
+	for _, build := range ctx.Config.Builds {
+		budget := build.SizeBudget
+		if build.Skip || !enabled(budget) {
+			continue
+		}
+
+		maxSize, err := maxSize(ctx, budget)
+		if err != nil {
+			return fmt.Errorf("invalid size budget for build %s: %w", build.ID, err)
+		}
+
+		var previous []previousArtifact
+		if budget.Previous != "" {
+			path, err := tmpl.New(ctx).Apply(budget.Previous)
+			if err != nil {
+				return err
+			}
+			if _, ok := previousCache[path]; !ok {
+				previousCache[path], err = loadPrevious(ctx, path)
+				if err != nil {
+					return fmt.Errorf("failed to load previous artifacts: %w", err)
+				}
+			}
+			previous = previousCache[path]
+		}
+
+		for _, bin := range ctx.Artifacts.Filter(artifact.And(
+			artifact.ByType(artifact.Binary),
+			artifact.ByIDs(build.ID),
+		)).List() {
+			size := bin.Extra[artifact.ExtraSize].(int64)
+			r := row{
+				build:    build.ID,
+				target:   target(bin.Goos, bin.Goarch, bin.Goarm, bin.Gomips),
+				size:     size,
+				previous: previousSize(previous, build.ID, bin),
+			}
+			if budget.ReleaseNotes {
+				rows = append(rows, r)
+			}
+
+			logger := log.WithField("binary", bin.Path).WithField("size", formatSize(size))
+			if maxSize > 0 && size > maxSize {
+				logger.Errorf("binary is bigger than %s", formatSize(maxSize))
+				failures++
+				continue
+			}
+			if r.previous <= 0 {
+				continue
+			}
+			growth := r.growth()
+			logger = logger.WithField("previous", formatSize(r.previous)).WithField("change", formatGrowth(growth))
+			switch {
+			case budget.Fail > 0 && growth > budget.Fail:
+				logger.Errorf("binary grew more than %s", formatGrowth(budget.Fail))
+				failures++
+			case budget.Warn > 0 && growth > budget.Warn:
+				logger.Warnf("binary grew more than %s", formatGrowth(budget.Warn))
+			default:
+				logger.Debug("binary size within budget")
+			}
+		}
+	}
+
+	if len(rows) > 0 {
+		ctx.ReleaseNotes = strings.TrimRight(ctx.ReleaseNotes, "\n") + "\n\n" + table(rows)
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d binaries exceeded their size budget", failures)
+	}
+	return nil
+}
+
+func (r row) growth() float64 {
+	return float64(r.size-r.previous) / float64(r.previous) * 100
+}
+
+func maxSize(ctx *context.Context, budget config.SizeBudget) (int64, error) {
+	if budget.MaxSize == "" {
+		return 0, nil
+	}
+	s, err := tmpl.New(ctx).Apply(budget.MaxSize)
+	if err != nil {
+		return 0, err
+	}
+	return parseSize(s)
+}
+
+func loadPrevious(ctx *context.Context, path string) ([]previousArtifact, error) {
+	var bts []byte
+	var err error
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		bts, err = download(ctx, path)
+	} else {
+		bts, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []previousArtifact
+	if err := json.Unmarshal(bts, &artifacts); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return artifacts, nil
+}
+
+// httpClient downloads the previous artifacts.
+// nolint: gochecknoglobals
+var httpClient = &http.Client{Timeout: time.Minute}
+
+func download(ctx *context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// previousSize finds the size of the same binary in the previous release,
+// either from its metadata or from the file itself if it is still around.
+func previousSize(previous []previousArtifact, id string, bin *artifact.Artifact) int64 {
+	for _, prev := range previous {
+		if prev.Type != artifact.Binary.String() ||
+			prev.Extra[artifact.ExtraID] != id ||
+			prev.Goos != bin.Goos ||
+			prev.Goarch != bin.Goarch ||
+			prev.Goarm != bin.Goarm ||
+			prev.Gomips != bin.Gomips {
+			continue
+		}
+		if size, ok := prev.Extra[artifact.ExtraSize].(float64); ok {
+			return int64(size)
+		}
+		if stat, err := os.Stat(prev.Path); err == nil {
+			return stat.Size()
+		}
+	}
+	return 0
+}
+
+func target(goos, goarch, goarm, gomips string) string {
+	t := goos + "_" + goarch
+	if goarm != "" {
+		t += "_" + goarm
+	}
+	if gomips != "" {
+		t += "_" + gomips
+	}
+	return t
+}
+
+func table(rows []row) string {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].build != rows[j].build {
+			return rows[i].build < rows[j].build
+		}
+		return rows[i].target < rows[j].target
+	})
+	var sb strings.Builder
+	sb.WriteString("## Binary sizes\n\n")
+	sb.WriteString("| Build | Target | Size | Previous | Change |\n")
+	sb.WriteString("|---|---|---:|---:|---:|\n")
+	for _, r := range rows {
+		previous, change := "-", "-"
+		if r.previous > 0 {
+			previous = formatSize(r.previous)
+			change = formatGrowth(r.growth())
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", r.build, r.target, formatSize(r.size), previous, change)
+	}
+	return sb.String()
+}
+
+func formatGrowth(growth float64) string {
+	return fmt.Sprintf("%+.1f%%", growth)
+}
+
+// nolint: gochecknoglobals
+var units = []struct {
+	suffix string
+	size   int64
+}{
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"GB", 1000 * 1000 * 1000},
+	{"MB", 1000 * 1000},
+	{"KB", 1000},
+	{"B", 1},
+}
+
+func formatSize(size int64) string {
+	for _, unit := range units[:3] {
+		if size >= unit.size {
+			return fmt.Sprintf("%.2f %s", float64(size)/float64(unit.size), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// parseSize parses sizes like 1024, 500KB, 20MiB or 1.5 GB.
+func parseSize(size string) (int64, error) {
+	s := strings.TrimSpace(size)
+	mult := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(strings.ToUpper(s), strings.ToUpper(unit.suffix)) {
+			s = strings.TrimSpace(s[:len(s)-len(unit.suffix)])
+			mult = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %q", size)
+	}
+	return int64(n * float64(mult)), nil
+}
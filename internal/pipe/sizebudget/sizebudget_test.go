@@ -0,0 +1,322 @@
+package sizebudget
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestRunNoBudget(t *testing.T) {
+	ctx := context.New(config.Project{
+		Builds: []config.Build{{ID: "foo"}},
+	})
+	bin := addBinary(t, ctx, "foo", "linux", "amd64", 4096)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, int64(4096), bin.Extra[artifact.ExtraSize])
+	require.Empty(t, ctx.ReleaseNotes)
+}
+
+func TestRunMissingBinary(t *testing.T) {
+	ctx := context.New(config.Project{})
+	bin := addBinary(t, ctx, "foo", "linux", "amd64", 1)
+	require.NoError(t, os.Remove(bin.Path))
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func addBinary(tb testing.TB, ctx *context.Context, id, goos, goarch string, size int) *artifact.Artifact {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "bin")
+	require.NoError(tb, os.WriteFile(path, make([]byte, size), 0o755))
+	bin := &artifact.Artifact{
+		Name:   "bin",
+		Path:   path,
+		Goos:   goos,
+		Goarch: goarch,
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: id,
+		},
+	}
+	ctx.Artifacts.Add(bin)
+	return bin
+}
+
+// writePrevious writes the artifacts.json of a previous release.
+func writePrevious(tb testing.TB, artifacts ...*artifact.Artifact) string {
+	tb.Helper()
+	bts, err := json.Marshal(artifacts)
+	require.NoError(tb, err)
+	path := filepath.Join(tb.TempDir(), "artifacts.json")
+	require.NoError(tb, os.WriteFile(path, bts, 0o644))
+	return path
+}
+
+func previousBinary(id, goos, goarch string, size int64) *artifact.Artifact {
+	return &artifact.Artifact{
+		Name:   "bin",
+		Path:   "dist/nope/bin",
+		Goos:   goos,
+		Goarch: goarch,
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:   id,
+			artifact.ExtraSize: size,
+		},
+	}
+}
+
+func TestRunMaxSize(t *testing.T) {
+	ctx := context.New(config.Project{
+		Builds: []config.Build{{
+			ID:         "foo",
+			SizeBudget: config.SizeBudget{MaxSize: "1KiB"},
+		}},
+	})
+	small := addBinary(t, ctx, "foo", "linux", "amd64", 1024)
+	other := addBinary(t, ctx, "bar", "linux", "amd64", 4096)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, int64(1024), small.Extra[artifact.ExtraSize])
+	require.Equal(t, int64(4096), other.Extra[artifact.ExtraSize])
+
+	addBinary(t, ctx, "foo", "linux", "arm64", 1025)
+	require.EqualError(t, Pipe{}.Run(ctx), "1 binaries exceeded their size budget")
+}
+
+func TestRunInvalidMaxSize(t *testing.T) {
+	ctx := context.New(config.Project{
+		Builds: []config.Build{{
+			ID:         "foo",
+			SizeBudget: config.SizeBudget{MaxSize: "a lot"},
+		}},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), `invalid size budget for build foo: invalid size: "a lot"`)
+}
+
+func TestRunPrevious(t *testing.T) {
+	previous := writePrevious(
+		t,
+		previousBinary("foo", "linux", "amd64", 1000),
+		previousBinary("foo", "darwin", "amd64", 1000),
+		previousBinary("foo", "windows", "amd64", 1000),
+	)
+
+	for name, tt := range map[string]struct {
+		size int
+		err  string
+	}{
+		"within budget": {size: 1050},
+		"warn":          {size: 1150},
+		"shrunk":        {size: 500},
+		"fail": {
+			size: 1300,
+			err:  "1 binaries exceeded their size budget",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{
+				Builds: []config.Build{{
+					ID: "foo",
+					SizeBudget: config.SizeBudget{
+						Previous: previous,
+						Warn:     10,
+						Fail:     25,
+					},
+				}},
+			})
+			addBinary(t, ctx, "foo", "linux", "amd64", tt.size)
+			// no previous binary to compare with
+			addBinary(t, ctx, "foo", "linux", "arm64", 10000)
+			err := Pipe{}.Run(ctx)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRunPreviousFromFile(t *testing.T) {
+	// old artifacts.json files don't have the size, but the binary might
+	// still be around.
+	path := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.WriteFile(path, make([]byte, 1000), 0o755))
+	prev := previousBinary("foo", "linux", "amd64", 0)
+	prev.Path = path
+	delete(prev.Extra, artifact.ExtraSize)
+
+	ctx := context.New(config.Project{
+		Builds: []config.Build{{
+			ID: "foo",
+			SizeBudget: config.SizeBudget{
+				Previous: writePrevious(t, prev),
+				Fail:     10,
+			},
+		}},
+	})
+	addBinary(t, ctx, "foo", "linux", "amd64", 2000)
+	require.EqualError(t, Pipe{}.Run(ctx), "1 binaries exceeded their size budget")
+}
+
+func TestRunPreviousURL(t *testing.T) {
+	bts, err := json.Marshal([]*artifact.Artifact{previousBinary("foo", "linux", "amd64", 1000)})
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/artifacts.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(bts)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.New(config.Project{
+		Builds: []config.Build{{
+			ID: "foo",
+			SizeBudget: config.SizeBudget{
+				Previous: srv.URL + "/artifacts.json",
+				Fail:     10,
+			},
+		}},
+	})
+	addBinary(t, ctx, "foo", "linux", "amd64", 2000)
+	require.EqualError(t, Pipe{}.Run(ctx), "1 binaries exceeded their size budget")
+
+	ctx.Config.Builds[0].SizeBudget.Previous = srv.URL + "/nope.json"
+	require.EqualError(t, Pipe{}.Run(ctx), "failed to load previous artifacts: "+srv.URL+"/nope.json: 404 Not Found")
+}
+
+func TestRunPreviousURLCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.NewWithTimeout(config.Project{
+		Builds: []config.Build{{
+			ID: "foo",
+			SizeBudget: config.SizeBudget{
+				Previous: srv.URL + "/artifacts.json",
+			},
+		}},
+	}, 10*time.Millisecond)
+	defer cancel()
+	addBinary(t, ctx, "foo", "linux", "amd64", 2000)
+	err := Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "context deadline exceeded")
+}
+
+func TestRunPreviousErrors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Builds: []config.Build{{
+				ID:         "foo",
+				SizeBudget: config.SizeBudget{Previous: "testdata/nope.json"},
+			}},
+		})
+		require.EqualError(t, Pipe{}.Run(ctx), "failed to load previous artifacts: open testdata/nope.json: no such file or directory")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "artifacts.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+		ctx := context.New(config.Project{
+			Builds: []config.Build{{
+				ID:         "foo",
+				SizeBudget: config.SizeBudget{Previous: path},
+			}},
+		})
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			Builds: []config.Build{{
+				ID:         "foo",
+				SizeBudget: config.SizeBudget{Previous: "{{ .Nope }"},
+			}},
+		})
+		require.Error(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestRunReleaseNotes(t *testing.T) {
+	ctx := context.New(config.Project{
+		Builds: []config.Build{
+			{
+				ID: "foo",
+				SizeBudget: config.SizeBudget{
+					Previous: writePrevious(
+						t,
+						previousBinary("foo", "linux", "amd64", 2*1024*1024),
+						previousBinary("bar", "linux", "amd64", 1000),
+					),
+					ReleaseNotes: true,
+				},
+			},
+			{
+				ID: "bar",
+				SizeBudget: config.SizeBudget{
+					MaxSize: "1MB",
+				},
+			},
+		},
+	})
+	ctx.ReleaseNotes = "## Changelog\n\n* foo\n"
+	addBinary(t, ctx, "foo", "linux", "amd64", 3*1024*1024)
+	addBinary(t, ctx, "foo", "darwin", "arm64", 512)
+	addBinary(t, ctx, "bar", "linux", "amd64", 1000)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, `## Changelog
+
+* foo
+
+## Binary sizes
+
+| Build | Target | Size | Previous | Change |
+|---|---|---:|---:|---:|
+| foo | darwin_arm64 | 512 B | - | - |
+| foo | linux_amd64 | 3.00 MiB | 2.00 MiB | +50.0% |
+`, ctx.ReleaseNotes)
+}
+
+func TestParseSize(t *testing.T) {
+	for input, expected := range map[string]int64{
+		"1024":    1024,
+		"10B":     10,
+		"1KB":     1000,
+		"1 KiB":   1024,
+		"1.5MB":   1500000,
+		"20mib":   20 * 1024 * 1024,
+		"2GB":     2000000000,
+		" 1 GiB ": 1 << 30,
+	} {
+		t.Run(input, func(t *testing.T) {
+			size, err := parseSize(input)
+			require.NoError(t, err)
+			require.Equal(t, expected, size)
+		})
+	}
+
+	for _, input := range []string{"", "MB", "-1KB", "1TB"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseSize(input)
+			require.Error(t, err)
+		})
+	}
+}
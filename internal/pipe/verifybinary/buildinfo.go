@@ -0,0 +1,230 @@
+package verifybinary
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// buildInfo is the information the go linker embeds in binaries.
+type buildInfo struct {
+	GoVersion string
+	Path      string
+	Version   string
+	Settings  map[string]string
+}
+
+// errNoBuildInfo happens when the binary was not built by go, or was
+// compressed or otherwise modified after the build.
+var errNoBuildInfo = errors.New("no go build information found")
+
+// buildInfoMagic is the start of the build information blob.
+// nolint: gochecknoglobals
+var buildInfoMagic = []byte("\xff Go buildinf:")
+
+// exe is an executable file we can read virtual memory from.
+type exe interface {
+	// dataStart returns the address where the build information should be.
+	dataStart() uint64
+	// readData reads up to size bytes of the memory at addr.
+	readData(addr, size uint64) ([]byte, error)
+}
+
+// readBuildInfo reads the build information from the given executable, the
+// same way `go version -m` does.
+func readBuildInfo(x exe) (buildInfo, error) {
+	var info buildInfo
+	data, err := x.readData(x.dataStart(), 64*1024)
+	if err != nil {
+		return info, errNoBuildInfo
+	}
+	for {
+		i := bytes.Index(data, buildInfoMagic)
+		if i < 0 || len(data)-i < 32 {
+			return info, errNoBuildInfo
+		}
+		if i%16 == 0 {
+			data = data[i:]
+			break
+		}
+		data = data[(i+15)&^15:]
+	}
+
+	var vers, mod string
+	ptrSize := int(data[14])
+	flags := data[15]
+	if flags&2 != 0 {
+		// go 1.18+ inlines the strings right after the header
+		var rest []byte
+		vers, rest = decodeString(data[32:])
+		mod, _ = decodeString(rest)
+	} else {
+		var bo binary.ByteOrder = binary.LittleEndian
+		if flags != 0 {
+			bo = binary.BigEndian
+		}
+		var readPtr func([]byte) uint64
+		switch ptrSize {
+		case 4:
+			readPtr = func(b []byte) uint64 { return uint64(bo.Uint32(b)) }
+		case 8:
+			readPtr = bo.Uint64
+		default:
+			return info, errNoBuildInfo
+		}
+		vers = readString(x, ptrSize, readPtr, readPtr(data[16:]))
+		mod = readString(x, ptrSize, readPtr, readPtr(data[16+ptrSize:]))
+	}
+	if vers == "" {
+		return info, errNoBuildInfo
+	}
+	info.GoVersion = vers
+
+	// the module information is wrapped in 16 byte sentinels
+	if len(mod) >= 33 && mod[len(mod)-17] == '\n' {
+		mod = mod[16 : len(mod)-16]
+	} else {
+		mod = ""
+	}
+	info.Settings = map[string]string{}
+	for _, line := range strings.Split(mod, "\n") {
+		fields := strings.Split(line, "\t")
+		switch {
+		case len(fields) >= 2 && fields[0] == "path":
+			info.Path = fields[1]
+		case len(fields) >= 3 && fields[0] == "mod":
+			info.Path = fields[1]
+			info.Version = fields[2]
+		case len(fields) >= 2 && fields[0] == "build":
+			if kv := strings.SplitN(fields[1], "=", 2); len(kv) == 2 {
+				info.Settings[kv[0]] = kv[1]
+			}
+		}
+	}
+	return info, nil
+}
+
+func decodeString(data []byte) (string, []byte) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return "", nil
+	}
+	return string(data[n : uint64(n)+size]), data[uint64(n)+size:]
+}
+
+func readString(x exe, ptrSize int, readPtr func([]byte) uint64, addr uint64) string {
+	hdr, err := x.readData(addr, uint64(2*ptrSize))
+	if err != nil || len(hdr) < 2*ptrSize {
+		return ""
+	}
+	dataAddr := readPtr(hdr)
+	dataLen := readPtr(hdr[ptrSize:])
+	if dataLen > 1<<20 {
+		return ""
+	}
+	data, err := x.readData(dataAddr, dataLen)
+	if err != nil || uint64(len(data)) < dataLen {
+		return ""
+	}
+	return string(data)
+}
+
+type elfExe struct{ f *elf.File }
+
+func (x elfExe) dataStart() uint64 {
+	if s := x.f.Section(".go.buildinfo"); s != nil {
+		return s.Addr
+	}
+	for _, p := range x.f.Progs {
+		if p.Type == elf.PT_LOAD && p.Flags&(elf.PF_X|elf.PF_W) == elf.PF_W {
+			return p.Vaddr
+		}
+	}
+	return 0
+}
+
+func (x elfExe) readData(addr, size uint64) ([]byte, error) {
+	for _, p := range x.f.Progs {
+		if p.Vaddr <= addr && addr <= p.Vaddr+p.Filesz-1 {
+			n := p.Vaddr + p.Filesz - addr
+			if n > size {
+				n = size
+			}
+			data := make([]byte, n)
+			_, err := p.ReadAt(data, int64(addr-p.Vaddr))
+			return data, err
+		}
+	}
+	return nil, errNoBuildInfo
+}
+
+type peExe struct{ f *pe.File }
+
+func (x peExe) imageBase() uint64 {
+	switch oh := x.f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		return uint64(oh.ImageBase)
+	case *pe.OptionalHeader64:
+		return oh.ImageBase
+	}
+	return 0
+}
+
+func (x peExe) dataStart() uint64 {
+	for _, s := range x.f.Sections {
+		if s.Name == ".data" {
+			return x.imageBase() + uint64(s.VirtualAddress)
+		}
+	}
+	return 0
+}
+
+func (x peExe) readData(addr, size uint64) ([]byte, error) {
+	addr -= x.imageBase()
+	for _, s := range x.f.Sections {
+		start, end := uint64(s.VirtualAddress), uint64(s.VirtualAddress+s.Size)
+		if start <= addr && addr < end {
+			n := end - addr
+			if n > size {
+				n = size
+			}
+			data := make([]byte, n)
+			_, err := s.ReadAt(data, int64(addr-start))
+			return data, err
+		}
+	}
+	return nil, errNoBuildInfo
+}
+
+type machoExe struct{ f *macho.File }
+
+func (x machoExe) dataStart() uint64 {
+	if s := x.f.Section("__go_buildinfo"); s != nil {
+		return s.Addr
+	}
+	for _, s := range x.f.Sections {
+		if s.Seg == "__DATA" {
+			return s.Addr
+		}
+	}
+	return 0
+}
+
+func (x machoExe) readData(addr, size uint64) ([]byte, error) {
+	for _, s := range x.f.Sections {
+		if s.Addr <= addr && addr < s.Addr+s.Size {
+			n := s.Addr + s.Size - addr
+			if n > size {
+				n = size
+			}
+			data := make([]byte, n)
+			_, err := s.ReadAt(data, int64(addr-s.Addr))
+			return data, err
+		}
+	}
+	return nil, errNoBuildInfo
+}
@@ -0,0 +1,257 @@
+// Package verifybinary verifies that the built binaries are for the
+// operating system and architecture they claim to be.
+package verifybinary
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe that verifies the built binaries.
+type Pipe struct{}
+
+func (Pipe) String() string { return "verifying binaries" }
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	g := semerrgroup.New(ctx.Parallelism)
+	for _, bin := range ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.Binary),
+		artifact.ByType(artifact.UniversalBinary),
+	)).List() {
+		bin := bin
+		g.Go(func() error {
+			if err := verify(ctx, bin); err != nil {
+				return fmt.Errorf("failed to verify %s (%s): %w", bin.Name, bin.Path, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func verify(ctx *context.Context, bin *artifact.Artifact) error {
+	var infos []buildInfo
+	var err error
+	switch bin.Goos {
+	case "windows":
+		infos, err = verifyPE(bin)
+	case "darwin", "ios":
+		if bin.Type == artifact.UniversalBinary {
+			infos, err = verifyFat(bin)
+		} else {
+			infos, err = verifyMachO(bin)
+		}
+	case "linux", "android", "freebsd", "netbsd", "openbsd", "dragonfly", "solaris", "illumos":
+		infos, err = verifyELF(bin)
+	default:
+		log.WithField("binary", bin.Path).Debugf("can't verify %s binaries", bin.Goos)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if err := verifyBuildInfo(ctx, bin, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyBuildInfo(ctx *context.Context, bin *artifact.Artifact, info buildInfo) error {
+	if info.GoVersion == "" {
+		// binary might have been compressed or modified by a hook, nothing
+		// else to check.
+		log.WithField("binary", bin.Path).Warn(errNoBuildInfo.Error())
+		return nil
+	}
+	log.WithField("binary", bin.Path).
+		WithField("go", info.GoVersion).
+		WithField("module", info.Path).
+		WithField("version", info.Version).
+		Debug("build info")
+
+	if goos := info.Settings["GOOS"]; goos != "" && goos != bin.Goos {
+		return fmt.Errorf("binary was built for GOOS=%s, expected %s", goos, bin.Goos)
+	}
+	if goarch := info.Settings["GOARCH"]; goarch != "" && bin.Goarch != "all" && goarch != bin.Goarch {
+		return fmt.Errorf("binary was built for GOARCH=%s, expected %s", goarch, bin.Goarch)
+	}
+	if goarm := info.Settings["GOARM"]; goarm != "" && bin.Goarm != "" &&
+		strings.SplitN(goarm, ",", 2)[0] != bin.Goarm {
+		return fmt.Errorf("binary was built for GOARM=%s, expected %s", goarm, bin.Goarm)
+	}
+
+	// when the main module is proxied, we know exactly which module and
+	// version should be in the binary.
+	if isProxied(ctx, bin) {
+		if info.Path != ctx.ModulePath {
+			return fmt.Errorf("binary was built from module %s, expected %s", info.Path, ctx.ModulePath)
+		}
+		if info.Version != ctx.Git.CurrentTag {
+			return fmt.Errorf("binary was built from %s@%s, expected %s", info.Path, info.Version, ctx.Git.CurrentTag)
+		}
+	}
+	return nil
+}
+
+func isProxied(ctx *context.Context, bin *artifact.Artifact) bool {
+	id := bin.ExtraOr(artifact.ExtraID, "")
+	for _, build := range ctx.Config.Builds {
+		if build.ID == id {
+			return build.UnproxiedMain != ""
+		}
+	}
+	return false
+}
+
+func verifyELF(bin *artifact.Artifact) ([]buildInfo, error) {
+	f, err := elf.Open(bin.Path)
+	if err != nil {
+		return nil, fmt.Errorf("not an ELF binary: %w", err)
+	}
+	defer f.Close()
+
+	if want := elfOSABI(bin.Goos); f.OSABI != want && !(bin.Goos == "linux" && f.OSABI == elf.ELFOSABI_LINUX) {
+		return nil, fmt.Errorf("binary OS/ABI is %s, expected %s", f.OSABI, want)
+	}
+	arch, ok := elfArchs[bin.Goarch]
+	if !ok {
+		log.WithField("binary", bin.Path).Debugf("can't verify %s binaries", bin.Goarch)
+		return nil, nil
+	}
+	if f.Machine != arch.machine || f.Class != arch.class || f.Data != arch.data {
+		return nil, fmt.Errorf("binary is %s %s %s, expected %s %s %s", f.Machine, f.Class, f.Data, arch.machine, arch.class, arch.data)
+	}
+	return readInfos(elfExe{f}), nil
+}
+
+func elfOSABI(goos string) elf.OSABI {
+	switch goos {
+	case "freebsd":
+		return elf.ELFOSABI_FREEBSD
+	case "netbsd":
+		return elf.ELFOSABI_NETBSD
+	case "openbsd":
+		return elf.ELFOSABI_OPENBSD
+	default:
+		return elf.ELFOSABI_NONE
+	}
+}
+
+type elfArch struct {
+	machine elf.Machine
+	class   elf.Class
+	data    elf.Data
+}
+
+// nolint: gochecknoglobals
+var elfArchs = map[string]elfArch{
+	"386":      {elf.EM_386, elf.ELFCLASS32, elf.ELFDATA2LSB},
+	"amd64":    {elf.EM_X86_64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"arm":      {elf.EM_ARM, elf.ELFCLASS32, elf.ELFDATA2LSB},
+	"arm64":    {elf.EM_AARCH64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"mips":     {elf.EM_MIPS, elf.ELFCLASS32, elf.ELFDATA2MSB},
+	"mipsle":   {elf.EM_MIPS, elf.ELFCLASS32, elf.ELFDATA2LSB},
+	"mips64":   {elf.EM_MIPS, elf.ELFCLASS64, elf.ELFDATA2MSB},
+	"mips64le": {elf.EM_MIPS, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"ppc64":    {elf.EM_PPC64, elf.ELFCLASS64, elf.ELFDATA2MSB},
+	"ppc64le":  {elf.EM_PPC64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"riscv64":  {elf.EM_RISCV, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"s390x":    {elf.EM_S390, elf.ELFCLASS64, elf.ELFDATA2MSB},
+}
+
+// nolint: gochecknoglobals
+var peMachines = map[string]uint16{
+	"386":   pe.IMAGE_FILE_MACHINE_I386,
+	"amd64": pe.IMAGE_FILE_MACHINE_AMD64,
+	"arm":   pe.IMAGE_FILE_MACHINE_ARMNT,
+	"arm64": pe.IMAGE_FILE_MACHINE_ARM64,
+}
+
+func verifyPE(bin *artifact.Artifact) ([]buildInfo, error) {
+	f, err := pe.Open(bin.Path)
+	if err != nil {
+		return nil, fmt.Errorf("not a PE binary: %w", err)
+	}
+	defer f.Close()
+
+	machine, ok := peMachines[bin.Goarch]
+	if !ok {
+		log.WithField("binary", bin.Path).Debugf("can't verify %s binaries", bin.Goarch)
+		return nil, nil
+	}
+	if f.Machine != machine {
+		return nil, fmt.Errorf("binary machine is %#x, expected %#x", f.Machine, machine)
+	}
+	return readInfos(peExe{f}), nil
+}
+
+// nolint: gochecknoglobals
+var machoCpus = map[string]macho.Cpu{
+	"amd64": macho.CpuAmd64,
+	"arm64": macho.CpuArm64,
+}
+
+func verifyMachO(bin *artifact.Artifact) ([]buildInfo, error) {
+	f, err := macho.Open(bin.Path)
+	if err != nil {
+		return nil, fmt.Errorf("not a Mach-O binary: %w", err)
+	}
+	defer f.Close()
+
+	cpu, ok := machoCpus[bin.Goarch]
+	if !ok {
+		log.WithField("binary", bin.Path).Debugf("can't verify %s binaries", bin.Goarch)
+		return nil, nil
+	}
+	if f.Cpu != cpu {
+		return nil, fmt.Errorf("binary cpu is %s, expected %s", f.Cpu, cpu)
+	}
+	return readInfos(machoExe{f}), nil
+}
+
+func verifyFat(bin *artifact.Artifact) ([]buildInfo, error) {
+	f, err := macho.OpenFat(bin.Path)
+	if err != nil {
+		return nil, fmt.Errorf("not a universal binary: %w", err)
+	}
+	defer f.Close()
+
+	var infos []buildInfo
+	for _, arch := range f.Arches {
+		if arch.Cpu != macho.CpuAmd64 && arch.Cpu != macho.CpuArm64 {
+			return nil, fmt.Errorf("universal binary has an unexpected %s binary", arch.Cpu)
+		}
+		archInfos := readInfos(machoExe{arch.File})
+		for _, info := range archInfos {
+			if goarch := info.Settings["GOARCH"]; goarch != "" {
+				if want := machoCpus[goarch]; want != arch.Cpu {
+					return nil, fmt.Errorf("universal binary has a GOARCH=%s binary as %s", goarch, arch.Cpu)
+				}
+			}
+		}
+		infos = append(infos, archInfos...)
+	}
+	return infos, nil
+}
+
+// readInfos reads the build info of the given binary. A missing build info
+// is not an error, and results in an empty build info.
+func readInfos(x exe) []buildInfo {
+	info, err := readBuildInfo(x)
+	if err != nil {
+		return []buildInfo{{}}
+	}
+	return []buildInfo{info}
+}
@@ -0,0 +1,222 @@
+package verifybinary
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func build(tb testing.TB, goos, goarch string, env ...string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.17\n"), 0o644))
+	path := filepath.Join(dir, "app_"+goos+"_"+goarch)
+	cmd := exec.Command("go", "build", "-o", path, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), append([]string{"GOOS=" + goos, "GOARCH=" + goarch, "CGO_ENABLED=0"}, env...)...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(tb, err, string(out))
+	return path
+}
+
+func newBinary(path, goos, goarch string) *artifact.Artifact {
+	return &artifact.Artifact{
+		Name:   filepath.Base(path),
+		Path:   path,
+		Goos:   goos,
+		Goarch: goarch,
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID: "foo",
+		},
+	}
+}
+
+func requireErrorContains(tb testing.TB, err error, s string) {
+	tb.Helper()
+	require.Error(tb, err)
+	require.Contains(tb, err.Error(), s)
+}
+
+func TestRun(t *testing.T) {
+	for _, target := range [][2]string{
+		{"linux", "amd64"},
+		{"linux", "386"},
+		{"linux", "arm64"},
+		{"linux", "mips64le"},
+		{"linux", "ppc64"},
+		{"freebsd", "amd64"},
+		{"openbsd", "arm64"},
+		{"netbsd", "386"},
+		{"windows", "amd64"},
+		{"windows", "386"},
+		{"windows", "arm64"},
+		{"darwin", "amd64"},
+		{"darwin", "arm64"},
+		{"js", "wasm"},
+	} {
+		goos, goarch := target[0], target[1]
+		t.Run(goos+"_"+goarch, func(t *testing.T) {
+			ctx := context.New(config.Project{})
+			ctx.Artifacts.Add(newBinary(build(t, goos, goarch), goos, goarch))
+			require.NoError(t, Pipe{}.Run(ctx))
+		})
+	}
+}
+
+func TestRunGoarm(t *testing.T) {
+	path := build(t, "linux", "arm", "GOARM=7")
+
+	t.Run("match", func(t *testing.T) {
+		bin := newBinary(path, "linux", "arm")
+		bin.Goarm = "7"
+		ctx := context.New(config.Project{})
+		ctx.Artifacts.Add(bin)
+		require.NoError(t, Pipe{}.Run(ctx))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		bin := newBinary(path, "linux", "arm")
+		bin.Goarm = "6"
+		ctx := context.New(config.Project{})
+		ctx.Artifacts.Add(bin)
+		requireErrorContains(t, Pipe{}.Run(ctx), "binary was built for GOARM=7, expected 6")
+	})
+}
+
+func TestRunMismatch(t *testing.T) {
+	linux := build(t, "linux", "amd64")
+	windows := build(t, "windows", "amd64")
+	darwin := build(t, "darwin", "amd64")
+	freebsd := build(t, "freebsd", "amd64")
+
+	for name, tt := range map[string]struct {
+		bin *artifact.Artifact
+		err string
+	}{
+		"wrong arch": {
+			bin: newBinary(linux, "linux", "arm64"),
+			err: "binary is EM_X86_64 ELFCLASS64 ELFDATA2LSB, expected EM_AARCH64 ELFCLASS64 ELFDATA2LSB",
+		},
+		"wrong bits": {
+			bin: newBinary(linux, "linux", "386"),
+			err: "binary is EM_X86_64 ELFCLASS64 ELFDATA2LSB, expected EM_386 ELFCLASS32 ELFDATA2LSB",
+		},
+		"wrong os abi": {
+			bin: newBinary(freebsd, "linux", "amd64"),
+			err: "binary OS/ABI is ELFOSABI_FREEBSD, expected ELFOSABI_NONE",
+		},
+		"wrong goos": {
+			bin: newBinary(linux, "android", "amd64"),
+			err: "binary was built for GOOS=linux, expected android",
+		},
+		"elf as windows": {
+			bin: newBinary(linux, "windows", "amd64"),
+			err: "not a PE binary",
+		},
+		"windows as darwin": {
+			bin: newBinary(windows, "darwin", "amd64"),
+			err: "not a Mach-O binary",
+		},
+		"darwin as linux": {
+			bin: newBinary(darwin, "linux", "amd64"),
+			err: "not an ELF binary",
+		},
+		"wrong windows arch": {
+			bin: newBinary(windows, "windows", "arm64"),
+			err: "binary machine is 0x8664, expected 0xaa64",
+		},
+		"wrong darwin arch": {
+			bin: newBinary(darwin, "darwin", "arm64"),
+			err: "binary cpu is CpuAmd64, expected CpuArm64",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ctx := context.New(config.Project{})
+			ctx.Artifacts.Add(tt.bin)
+			err := Pipe{}.Run(ctx)
+			requireErrorContains(t, err, "failed to verify "+tt.bin.Name)
+			requireErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestRunNoBuildInfo(t *testing.T) {
+	// binaries modified after the build (e.g. by upx) might not have the build
+	// info anymore, that's only a warning.
+	path := build(t, "linux", "amd64")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data = bytes.ReplaceAll(data, buildInfoMagic, []byte("\xff Go nothing:  "))
+	require.NoError(t, os.WriteFile(path, data, 0o755))
+
+	ctx := context.New(config.Project{})
+	ctx.Artifacts.Add(newBinary(path, "linux", "amd64"))
+	require.NoError(t, Pipe{}.Run(ctx))
+}
+
+func TestRunProxied(t *testing.T) {
+	path := build(t, "linux", "amd64")
+
+	ctx := context.New(config.Project{
+		Builds: []config.Build{
+			{ID: "foo", UnproxiedMain: "."},
+		},
+	})
+	ctx.ModulePath = "github.com/goreleaser/nope"
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Artifacts.Add(newBinary(path, "linux", "amd64"))
+	requireErrorContains(t, Pipe{}.Run(ctx), "binary was built from module example.com/app, expected github.com/goreleaser/nope")
+
+	ctx.ModulePath = "example.com/app"
+	requireErrorContains(t, Pipe{}.Run(ctx), "binary was built from example.com/app@(devel), expected v1.2.3")
+}
+
+func TestBuildInfo(t *testing.T) {
+	path := build(t, "linux", "arm64")
+	bin := newBinary(path, "linux", "arm64")
+	infos, err := verifyELF(bin)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.NotEmpty(t, infos[0].GoVersion)
+	require.Equal(t, "example.com/app", infos[0].Path)
+	require.Equal(t, "linux", infos[0].Settings["GOOS"])
+	require.Equal(t, "arm64", infos[0].Settings["GOARCH"])
+}
+
+func TestRunUniversalBinary(t *testing.T) {
+	dist := t.TempDir()
+	ctx := context.New(config.Project{
+		Dist: dist,
+		UniversalBinaries: []config.UniversalBinary{
+			{ID: "foo", NameTemplate: "app"},
+		},
+	})
+	ctx.Artifacts.Add(newBinary(build(t, "darwin", "amd64"), "darwin", "amd64"))
+	ctx.Artifacts.Add(newBinary(build(t, "darwin", "arm64"), "darwin", "arm64"))
+	require.NoError(t, universalbinary.Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.UniversalBinary)).List(), 1)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	t.Run("not fat", func(t *testing.T) {
+		ctx := context.New(config.Project{})
+		bin := newBinary(build(t, "darwin", "amd64"), "darwin", "all")
+		bin.Type = artifact.UniversalBinary
+		ctx.Artifacts.Add(bin)
+		requireErrorContains(t, Pipe{}.Run(ctx), "not a universal binary")
+	})
+}
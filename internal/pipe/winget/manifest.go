@@ -0,0 +1,67 @@
+package winget
+
+const (
+	manifestVersion = "1.4.0"
+	defaultLocale   = "en-US"
+)
+
+// Version is the version manifest of a package.
+type Version struct {
+	PackageIdentifier string `yaml:"PackageIdentifier"`
+	PackageVersion    string `yaml:"PackageVersion"`
+	DefaultLocale     string `yaml:"DefaultLocale"`
+	ManifestType      string `yaml:"ManifestType"`
+	ManifestVersion   string `yaml:"ManifestVersion"`
+}
+
+// Installer is the installer manifest of a package.
+type Installer struct {
+	PackageIdentifier string           `yaml:"PackageIdentifier"`
+	PackageVersion    string           `yaml:"PackageVersion"`
+	InstallerLocale   string           `yaml:"InstallerLocale"`
+	ReleaseDate       string           `yaml:"ReleaseDate"`
+	Installers        []InstallerEntry `yaml:"Installers"`
+	ManifestType      string           `yaml:"ManifestType"`
+	ManifestVersion   string           `yaml:"ManifestVersion"`
+}
+
+// InstallerEntry is the installer of a package for an architecture.
+type InstallerEntry struct {
+	Architecture         string                `yaml:"Architecture"`
+	InstallerType        string                `yaml:"InstallerType"`
+	NestedInstallerType  string                `yaml:"NestedInstallerType,omitempty"`
+	NestedInstallerFiles []NestedInstallerFile `yaml:"NestedInstallerFiles,omitempty"`
+	Commands             []string              `yaml:"Commands,omitempty"`
+	InstallerURL         string                `yaml:"InstallerUrl"`
+	InstallerSha256      string                `yaml:"InstallerSha256"`
+	UpgradeBehavior      string                `yaml:"UpgradeBehavior"`
+}
+
+// NestedInstallerFile is a portable executable inside a zip installer.
+type NestedInstallerFile struct {
+	RelativeFilePath     string `yaml:"RelativeFilePath"`
+	PortableCommandAlias string `yaml:"PortableCommandAlias"`
+}
+
+// Locale is the default locale manifest of a package.
+type Locale struct {
+	PackageIdentifier   string   `yaml:"PackageIdentifier"`
+	PackageVersion      string   `yaml:"PackageVersion"`
+	PackageLocale       string   `yaml:"PackageLocale"`
+	Publisher           string   `yaml:"Publisher"`
+	PublisherURL        string   `yaml:"PublisherUrl,omitempty"`
+	PublisherSupportURL string   `yaml:"PublisherSupportUrl,omitempty"`
+	Author              string   `yaml:"Author,omitempty"`
+	PackageName         string   `yaml:"PackageName"`
+	PackageURL          string   `yaml:"PackageUrl,omitempty"`
+	License             string   `yaml:"License"`
+	LicenseURL          string   `yaml:"LicenseUrl,omitempty"`
+	Copyright           string   `yaml:"Copyright,omitempty"`
+	ShortDescription    string   `yaml:"ShortDescription"`
+	Description         string   `yaml:"Description,omitempty"`
+	Tags                []string `yaml:"Tags,omitempty"`
+	ReleaseNotes        string   `yaml:"ReleaseNotes,omitempty"`
+	ReleaseNotesURL     string   `yaml:"ReleaseNotesUrl,omitempty"`
+	ManifestType        string   `yaml:"ManifestType"`
+	ManifestVersion     string   `yaml:"ManifestVersion"`
+}
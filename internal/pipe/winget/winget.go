@@ -0,0 +1,421 @@
+// Package winget implements the Pipe, providing winget manifests generation
+// and uploading them to a winget-pkgs like repository.
+package winget
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	wingetConfigExtra = "WingetConfig"
+
+	// maxReleaseNotesLength is the maximum length of the release notes
+	// allowed by the manifest schema.
+	maxReleaseNotesLength = 10000
+	ellipsis              = "..."
+)
+
+var (
+	// ErrNoWindowsArtifacts happens when no windows zip archives or binaries
+	// are found.
+	ErrNoWindowsArtifacts = errors.New("no windows zip archives or binaries found")
+
+	// ErrMultipleInstallersSameArch happens when more than one archive or
+	// binary is found for the same architecture, as a manifest can only list
+	// one installer per architecture.
+	ErrMultipleInstallersSameArch = errors.New("found multiple winget installers for the same architecture. Consider using ids in the winget section")
+
+	errNoPublisher        = errors.New("winget: publisher is required")
+	errNoLicense          = errors.New("winget: license is required")
+	errNoShortDescription = errors.New("winget: short_description is required")
+)
+
+// architectures maps the go architectures to the winget ones.
+var architectures = map[string]string{
+	"386":   "x86",
+	"amd64": "x64",
+	"arm64": "arm64",
+}
+
+// identifierRe matches a valid winget package identifier, e.g.
+// Publisher.Package.
+var identifierRe = regexp.MustCompile(`^[^.\s\\/:*?"<>|\x01-\x1f]{1,32}(\.[^.\s\\/:*?"<>|\x01-\x1f]{1,32}){1,7}$`)
+
+// Pipe for winget manifests.
+type Pipe struct{}
+
+func (Pipe) String() string                 { return "winget manifests" }
+func (Pipe) Skip(ctx *context.Context) bool { return len(ctx.Config.Winget) == 0 }
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Winget {
+		winget := &ctx.Config.Winget[i]
+
+		if winget.Name == "" {
+			winget.Name = ctx.Config.ProjectName
+		}
+		if winget.CommitAuthor.Name == "" {
+			winget.CommitAuthor.Name = "goreleaserbot"
+		}
+		if winget.CommitAuthor.Email == "" {
+			winget.CommitAuthor.Email = "goreleaser@carlosbecker.com"
+		}
+		if winget.CommitMessageTemplate == "" {
+			winget.CommitMessageTemplate = "Winget manifests update for {{ .ProjectName }} version {{ .Tag }}"
+		}
+		if winget.ReleaseNotes == "" {
+			winget.ReleaseNotes = "{{ .ReleaseNotes }}"
+		}
+	}
+	return nil
+}
+
+// Run creates the winget manifests locally.
+func (Pipe) Run(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, cli)
+}
+
+// Publish winget manifests.
+func (Pipe) Publish(ctx *context.Context) error {
+	cli, err := client.New(ctx)
+	if err != nil {
+		return err
+	}
+	return publishAll(ctx, cli)
+}
+
+func runAll(ctx *context.Context, cli client.Client) error {
+	for _, winget := range ctx.Config.Winget {
+		if err := doRun(ctx, winget, cli); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func publishAll(ctx *context.Context, cli client.Client) error {
+	skips := pipe.SkipMemento{}
+	for _, manifest := range ctx.Artifacts.Filter(artifact.ByType(artifact.WingetManifest)).List() {
+		err := doPublish(ctx, manifest, cli)
+		if err != nil && pipe.IsSkip(err) {
+			skips.Remember(err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return skips.Evaluate()
+}
+
+func doRun(ctx *context.Context, winget config.Winget, cl client.Client) error {
+	if winget.Repository.Name == "" && winget.Repository.Git.URL == "" {
+		return pipe.Skip("winget repository name is not set")
+	}
+
+	filters := []artifact.Filter{
+		artifact.ByGoos("windows"),
+		artifact.Or(
+			artifact.ByGoarch("386"),
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarch("arm64"),
+		),
+		artifact.Or(
+			artifact.And(
+				artifact.ByFormats("zip"),
+				artifact.ByType(artifact.UploadableArchive),
+			),
+			artifact.ByType(artifact.UploadableBinary),
+		),
+	}
+	if len(winget.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(winget.IDs...))
+	}
+
+	artifacts := ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	if len(artifacts) == 0 {
+		return ErrNoWindowsArtifacts
+	}
+
+	winget, err := templateFields(ctx, winget)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := manifestsFor(ctx, winget, cl, artifacts)
+	if err != nil {
+		return err
+	}
+
+	for _, manifest := range manifests {
+		path := filepath.Join(ctx.Config.Dist, "winget", winget.Path, manifest.name)
+		log.WithField("manifest", path).Info("writing")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to write winget manifest: %w", err)
+		}
+		if err := os.WriteFile(path, manifest.content, 0o644); err != nil { //nolint: gosec
+			return fmt.Errorf("failed to write winget manifest: %w", err)
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: manifest.name,
+			Path: path,
+			Type: artifact.WingetManifest,
+			Extra: map[string]interface{}{
+				wingetConfigExtra: winget,
+			},
+		})
+	}
+	return nil
+}
+
+func doPublish(ctx *context.Context, manifest *artifact.Artifact, cl client.Client) error {
+	winget := manifest.Extra[wingetConfigExtra].(config.Winget)
+	var err error
+	cl, err = client.NewIfToken(ctx, cl, winget.Repository.Token)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(winget.SkipUpload) == "true" {
+		return pipe.Skip("winget.skip_upload is set")
+	}
+
+	if strings.TrimSpace(winget.SkipUpload) == "auto" && ctx.Semver.Prerelease != "" {
+		return pipe.Skip("prerelease detected with 'auto' upload, skipping winget publish")
+	}
+
+	gpath := path.Join(winget.Path, manifest.Name)
+	log.WithField("manifest", gpath).
+		WithField("repo", client.RepoFromRef(winget.Repository).String()).
+		Info("pushing")
+
+	msg, err := tmpl.New(ctx).Apply(winget.CommitMessageTemplate)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(manifest.Path)
+	if err != nil {
+		return err
+	}
+
+	return client.PublishFile(ctx, cl, winget.Repository, winget.CommitAuthor, content, gpath, msg)
+}
+
+// templateFields applies the templates of the config, and sets the defaults
+// that depend on them.
+func templateFields(ctx *context.Context, winget config.Winget) (config.Winget, error) {
+	t := tmpl.New(ctx)
+	for _, field := range []*string{
+		&winget.Name,
+		&winget.PackageIdentifier,
+		&winget.Publisher,
+		&winget.Copyright,
+		&winget.ShortDescription,
+		&winget.Description,
+		&winget.ReleaseNotes,
+		&winget.ReleaseNotesURL,
+		&winget.Path,
+		&winget.Repository.Owner,
+		&winget.Repository.Name,
+		&winget.SkipUpload,
+	} {
+		var err error
+		if *field, err = t.Apply(*field); err != nil {
+			return winget, err
+		}
+	}
+
+	if notes := []rune(winget.ReleaseNotes); len(notes) > maxReleaseNotesLength {
+		winget.ReleaseNotes = string(notes[:maxReleaseNotesLength-len(ellipsis)]) + ellipsis
+	}
+
+	switch {
+	case winget.Publisher == "":
+		return winget, errNoPublisher
+	case winget.License == "":
+		return winget, errNoLicense
+	case winget.ShortDescription == "":
+		return winget, errNoShortDescription
+	}
+
+	if winget.PackageIdentifier == "" {
+		winget.PackageIdentifier = removeSpaces(winget.Publisher) + "." + removeSpaces(winget.Name)
+	}
+	if !identifierRe.MatchString(winget.PackageIdentifier) {
+		return winget, fmt.Errorf("winget: invalid package identifier: %s", winget.PackageIdentifier)
+	}
+	if winget.Path == "" {
+		winget.Path = path.Join(
+			"manifests",
+			strings.ToLower(winget.PackageIdentifier[:1]),
+			strings.ReplaceAll(winget.PackageIdentifier, ".", "/"),
+			ctx.Version,
+		)
+	}
+	return winget, nil
+}
+
+type manifest struct {
+	name    string
+	content []byte
+}
+
+// manifestsFor builds the version, installer and default locale manifests.
+func manifestsFor(ctx *context.Context, winget config.Winget, cl client.Client, artifacts []*artifact.Artifact) ([]manifest, error) {
+	installer, err := installerFor(ctx, winget, cl, artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	version := Version{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		DefaultLocale:     defaultLocale,
+		ManifestType:      "version",
+		ManifestVersion:   manifestVersion,
+	}
+
+	locale := Locale{
+		PackageIdentifier:   winget.PackageIdentifier,
+		PackageVersion:      ctx.Version,
+		PackageLocale:       defaultLocale,
+		Publisher:           winget.Publisher,
+		PublisherURL:        winget.PublisherURL,
+		PublisherSupportURL: winget.PublisherSupportURL,
+		Author:              winget.Author,
+		PackageName:         winget.Name,
+		PackageURL:          winget.Homepage,
+		License:             winget.License,
+		LicenseURL:          winget.LicenseURL,
+		Copyright:           winget.Copyright,
+		ShortDescription:    winget.ShortDescription,
+		Description:         winget.Description,
+		Tags:                winget.Tags,
+		ReleaseNotes:        winget.ReleaseNotes,
+		ReleaseNotesURL:     winget.ReleaseNotesURL,
+		ManifestType:        "defaultLocale",
+		ManifestVersion:     manifestVersion,
+	}
+
+	var result []manifest
+	for _, m := range []struct {
+		suffix string
+		kind   string
+		data   interface{}
+	}{
+		{".yaml", "version", version},
+		{".installer.yaml", "installer", installer},
+		{".locale." + defaultLocale + ".yaml", "defaultLocale", locale},
+	} {
+		bts, err := yaml.Marshal(m.data)
+		if err != nil {
+			return nil, err
+		}
+		header := fmt.Sprintf(
+			"# This file was generated by GoReleaser. DO NOT EDIT.\n# yaml-language-server: $schema=https://aka.ms/winget-manifest.%s.%s.schema.json\n",
+			m.kind,
+			manifestVersion,
+		)
+		result = append(result, manifest{
+			name:    winget.PackageIdentifier + m.suffix,
+			content: append([]byte(header), bts...),
+		})
+	}
+	return result, nil
+}
+
+func installerFor(ctx *context.Context, winget config.Winget, cl client.Client, artifacts []*artifact.Artifact) (Installer, error) {
+	installer := Installer{
+		PackageIdentifier: winget.PackageIdentifier,
+		PackageVersion:    ctx.Version,
+		InstallerLocale:   defaultLocale,
+		ReleaseDate:       ctx.Date.Format("2006-01-02"),
+		ManifestType:      "installer",
+		ManifestVersion:   manifestVersion,
+	}
+
+	if winget.URLTemplate == "" {
+		url, err := cl.ReleaseURLTemplate(ctx)
+		if err != nil {
+			return installer, err
+		}
+		winget.URLTemplate = url
+	}
+
+	seen := map[string]bool{}
+	for _, art := range artifacts {
+		arch := architectures[art.Goarch]
+		if seen[arch] {
+			return installer, ErrMultipleInstallersSameArch
+		}
+		seen[arch] = true
+
+		url, err := tmpl.New(ctx).WithArtifact(art, map[string]string{}).Apply(winget.URLTemplate)
+		if err != nil {
+			return installer, err
+		}
+
+		sum, err := art.Checksum("sha256")
+		if err != nil {
+			return installer, err
+		}
+
+		entry := InstallerEntry{
+			Architecture:    arch,
+			InstallerURL:    url,
+			InstallerSha256: strings.ToUpper(sum),
+			UpgradeBehavior: "uninstallPrevious",
+		}
+		if art.Type == artifact.UploadableBinary {
+			entry.InstallerType = "portable"
+			entry.Commands = []string{commandFor(art.ExtraOr(artifact.ExtraBinary, art.Name).(string))}
+		} else {
+			entry.InstallerType = "zip"
+			entry.NestedInstallerType = "portable"
+			wrap := art.ExtraOr(artifact.ExtraWrappedIn, "").(string)
+			for _, bin := range art.ExtraOr(artifact.ExtraBinaries, []string{}).([]string) {
+				entry.NestedInstallerFiles = append(entry.NestedInstallerFiles, NestedInstallerFile{
+					RelativeFilePath:     strings.ReplaceAll(path.Join(wrap, bin), "/", "\\"),
+					PortableCommandAlias: commandFor(bin),
+				})
+			}
+		}
+		installer.Installers = append(installer.Installers, entry)
+	}
+
+	sort.Slice(installer.Installers, func(i, j int) bool {
+		return installer.Installers[i].Architecture < installer.Installers[j].Architecture
+	})
+	return installer, nil
+}
+
+// commandFor returns the command of a binary, e.g. bin/foo.exe becomes foo.
+func commandFor(bin string) string {
+	return strings.TrimSuffix(path.Base(bin), ".exe")
+}
+
+func removeSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
@@ -0,0 +1,242 @@
+package winget
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/golden"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSkip(t *testing.T) {
+	require.True(t, Pipe{}.Skip(context.New(config.Project{})))
+	require.False(t, Pipe{}.Skip(context.New(config.Project{
+		Winget: []config.Winget{{}},
+	})))
+}
+
+func TestDefault(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Winget:      []config.Winget{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.Winget{
+		Name:                  "foo",
+		CommitMessageTemplate: "Winget manifests update for {{ .ProjectName }} version {{ .Tag }}",
+		ReleaseNotes:          "{{ .ReleaseNotes }}",
+		CommitAuthor: config.CommitAuthor{
+			Name:  "goreleaserbot",
+			Email: "goreleaser@carlosbecker.com",
+		},
+	}, ctx.Config.Winget[0])
+}
+
+func newContext(tb testing.TB, winget config.Winget) *context.Context {
+	tb.Helper()
+	folder := tb.TempDir()
+	ctx := context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+		Winget:      []config.Winget{winget},
+	})
+	testlib.FakeRelease(tb, ctx, "v1.0.1")
+	ctx.ReleaseNotes = "## Changelog\n\n* abc123 feat: foo\n"
+	require.NoError(tb, Pipe{}.Default(ctx))
+
+	var archives []testlib.FakeArchive
+	for _, goarch := range []string{"386", "amd64", "arm64"} {
+		for _, goos := range []string{"windows", "linux"} {
+			archives = append(archives, testlib.FakeArchive{Goos: goos, Goarch: goarch, Format: "zip"})
+		}
+	}
+	testlib.FakeArchives(tb, ctx, archives, func(a testlib.FakeArchive) artifact.Extras {
+		return artifact.Extras{
+			artifact.ExtraWrappedIn: "foo_" + a.Goarch,
+			artifact.ExtraBinaries:  []string{"foo.exe"},
+		}
+	})
+	return ctx
+}
+
+func validConfig() config.Winget {
+	return config.Winget{
+		Publisher:        "Foo Inc",
+		PublisherURL:     "https://example.com",
+		License:          "MIT",
+		ShortDescription: "A {{ .ProjectName }} tool",
+		Homepage:         "https://example.com/foo",
+		Tags:             []string{"cli", "foo"},
+		Repository: config.RepoRef{
+			Owner: "foo",
+			Name:  "winget-pkgs",
+		},
+	}
+}
+
+func TestRunPipe(t *testing.T) {
+	ctx := newContext(t, validConfig())
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+
+	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.WingetManifest)).List()
+	require.Len(t, manifests, 3)
+	for _, manifest := range manifests {
+		t.Run(manifest.Name, func(t *testing.T) {
+			require.Equal(t, filepath.Join(ctx.Config.Dist, "winget", "manifests", "f", "FooInc", "foo", "1.0.1", manifest.Name), manifest.Path)
+			bts, err := os.ReadFile(manifest.Path)
+			require.NoError(t, err)
+			golden.RequireEqual(t, bts)
+		})
+	}
+
+	require.NoError(t, publishAll(ctx, cli))
+	require.True(t, cli.CreatedFile)
+	require.Equal(t, "manifests/f/FooInc/foo/1.0.1/FooInc.foo.locale.en-US.yaml", cli.Path)
+}
+
+func TestRunPipeBinary(t *testing.T) {
+	cfg := validConfig()
+	cfg.PackageIdentifier = "FooInc.Tools.Foo"
+	cfg.IDs = []string{"bin"}
+	ctx := newContext(t, cfg)
+	path := filepath.Join(ctx.Config.Dist, "foo_windows_amd64.exe")
+	require.NoError(t, os.WriteFile(path, []byte("fake exe"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo_windows_amd64.exe",
+		Path:   path,
+		Goos:   "windows",
+		Goarch: "amd64",
+		Type:   artifact.UploadableBinary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "bin",
+			artifact.ExtraBinary: "foo.exe",
+		},
+	})
+
+	require.NoError(t, runAll(ctx, client.NewMock()))
+	manifests := ctx.Artifacts.Filter(artifact.ByType(artifact.WingetManifest)).List()
+	require.Len(t, manifests, 3)
+	require.Equal(t, "FooInc.Tools.Foo.installer.yaml", manifests[1].Name)
+	require.Equal(t, filepath.Join(ctx.Config.Dist, "winget", "manifests", "f", "FooInc", "Tools", "Foo", "1.0.1", manifests[1].Name), manifests[1].Path)
+	bts, err := os.ReadFile(manifests[1].Path)
+	require.NoError(t, err)
+	golden.RequireEqualYaml(t, bts)
+}
+
+func TestRunPipeCustomPath(t *testing.T) {
+	cfg := validConfig()
+	cfg.Path = "pkgs/{{ .ProjectName }}"
+	ctx := newContext(t, cfg)
+	cli := client.NewMock()
+	require.NoError(t, runAll(ctx, cli))
+	require.NoError(t, publishAll(ctx, cli))
+	require.Equal(t, "pkgs/foo/FooInc.foo.locale.en-US.yaml", cli.Path)
+}
+
+func TestRunPipeReleaseNotesTruncated(t *testing.T) {
+	ctx := newContext(t, validConfig())
+	ctx.ReleaseNotes = strings.Repeat("a", maxReleaseNotesLength+10)
+	winget, err := templateFields(ctx, ctx.Config.Winget[0])
+	require.NoError(t, err)
+	require.Len(t, winget.ReleaseNotes, maxReleaseNotesLength)
+	require.True(t, strings.HasSuffix(winget.ReleaseNotes, ellipsis))
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		modify   func(cfg *config.Winget)
+		expected string
+	}{
+		"no publisher": {
+			modify:   func(cfg *config.Winget) { cfg.Publisher = "" },
+			expected: errNoPublisher.Error(),
+		},
+		"no license": {
+			modify:   func(cfg *config.Winget) { cfg.License = "" },
+			expected: errNoLicense.Error(),
+		},
+		"no short description": {
+			modify:   func(cfg *config.Winget) { cfg.ShortDescription = "" },
+			expected: errNoShortDescription.Error(),
+		},
+		"invalid identifier": {
+			modify:   func(cfg *config.Winget) { cfg.PackageIdentifier = "foo" },
+			expected: "winget: invalid package identifier: foo",
+		},
+		"no artifacts": {
+			modify:   func(cfg *config.Winget) { cfg.IDs = []string{"nope"} },
+			expected: ErrNoWindowsArtifacts.Error(),
+		},
+		"invalid template": {
+			modify:   func(cfg *config.Winget) { cfg.Publisher = "{{ .Nope }" },
+			expected: "template: tmpl:1: unexpected",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+			ctx := newContext(t, cfg)
+			err := runAll(ctx, client.NewMock())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestRunPipeMultipleArtifactsSameArch(t *testing.T) {
+	ctx := newContext(t, validConfig())
+	path := filepath.Join(ctx.Config.Dist, "foo_windows_amd64.exe")
+	require.NoError(t, os.WriteFile(path, []byte("fake exe"), 0o644))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo_windows_amd64.exe",
+		Path:   path,
+		Goos:   "windows",
+		Goarch: "amd64",
+		Type:   artifact.UploadableBinary,
+		Extra: map[string]interface{}{
+			artifact.ExtraID:     "foo",
+			artifact.ExtraBinary: "foo.exe",
+		},
+	})
+	require.Equal(t, ErrMultipleInstallersSameArch, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeNoRepository(t *testing.T) {
+	cfg := validConfig()
+	cfg.Repository = config.RepoRef{}
+	ctx := newContext(t, cfg)
+	testlib.AssertSkipped(t, runAll(ctx, client.NewMock()))
+}
+
+func TestRunPipeSkipUpload(t *testing.T) {
+	for name, tt := range map[string]struct {
+		skipUpload string
+		prerelease string
+	}{
+		"true": {skipUpload: "true"},
+		"auto": {skipUpload: "auto", prerelease: "beta1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.SkipUpload = tt.skipUpload
+			ctx := newContext(t, cfg)
+			ctx.Semver.Prerelease = tt.prerelease
+			cli := client.NewMock()
+			require.NoError(t, runAll(ctx, cli))
+			testlib.AssertSkipped(t, publishAll(ctx, cli))
+			require.False(t, cli.CreatedFile)
+		})
+	}
+}
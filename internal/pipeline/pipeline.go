@@ -7,11 +7,15 @@ import (
 	"github.com/goreleaser/goreleaser/internal/pipe/announce"
 	"github.com/goreleaser/goreleaser/internal/pipe/archive"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifacts"
+	"github.com/goreleaser/goreleaser/internal/pipe/aur"
 	"github.com/goreleaser/goreleaser/internal/pipe/before"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
 	"github.com/goreleaser/goreleaser/internal/pipe/build"
+	"github.com/goreleaser/goreleaser/internal/pipe/cask"
 	"github.com/goreleaser/goreleaser/internal/pipe/changelog"
 	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
+	"github.com/goreleaser/goreleaser/internal/pipe/chocolatey"
+	"github.com/goreleaser/goreleaser/internal/pipe/debugsymbols"
 	"github.com/goreleaser/goreleaser/internal/pipe/defaults"
 	"github.com/goreleaser/goreleaser/internal/pipe/dist"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
@@ -21,16 +25,22 @@ import (
 	"github.com/goreleaser/goreleaser/internal/pipe/gofish"
 	"github.com/goreleaser/goreleaser/internal/pipe/gomod"
 	"github.com/goreleaser/goreleaser/internal/pipe/krew"
+	"github.com/goreleaser/goreleaser/internal/pipe/linuxrepos"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/nfpmlint"
+	"github.com/goreleaser/goreleaser/internal/pipe/nix"
 	"github.com/goreleaser/goreleaser/internal/pipe/publish"
 	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
 	"github.com/goreleaser/goreleaser/internal/pipe/semver"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
+	"github.com/goreleaser/goreleaser/internal/pipe/sizebudget"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
 	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
 	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
+	"github.com/goreleaser/goreleaser/internal/pipe/verifybinary"
+	"github.com/goreleaser/goreleaser/internal/pipe/winget"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -57,30 +67,41 @@ var BuildPipeline = []Piper{
 	effectiveconfig.Pipe{}, // writes the actual config (with defaults et al set) to dist
 	changelog.Pipe{},       // builds the release changelog
 	build.Pipe{},           // build
+	debugsymbols.Pipe{},    // split debug symbols out of the binaries
 	universalbinary.Pipe{}, // universal binary handling
+	verifybinary.Pipe{},    // verify binaries os and arch
 }
 
 // BuildCmdPipeline is the pipeline run by goreleaser build.
 // nolint:gochecknoglobals
-var BuildCmdPipeline = append(BuildPipeline, artifacts.Pipe{})
+var BuildCmdPipeline = append(BuildPipeline, sizebudget.Pipe{}, artifacts.Pipe{})
 
 // Pipeline contains all pipe implementations in order.
 // nolint: gochecknoglobals
 var Pipeline = append(
 	BuildPipeline,
-	archive.Pipe{},       // archive in tar.gz, zip or binary (which does no archiving at all)
-	sourcearchive.Pipe{}, // archive the source code using git-archive
-	nfpm.Pipe{},          // archive via fpm (deb, rpm) using "native" go impl
-	snapcraft.Pipe{},     // archive via snapcraft (snap)
-	brew.Pipe{},          // create brew tap
-	gofish.Pipe{},        // create gofish rig
-	krew.Pipe{},          // krew plugins
-	scoop.Pipe{},         // create scoop buckets
-	sbom.Pipe{},          // create SBOMs of artifacts
-	checksums.Pipe{},     // checksums of the files
-	sign.Pipe{},          // sign artifacts
-	docker.Pipe{},        // create and push docker images
-	artifacts.Pipe{},     // creates an artifacts.json in the dist folder
-	publish.Pipe{},       // publishes artifacts
-	announce.Pipe{},      // announce releases
+	sign.AuthenticodePipe{}, // sign windows binaries
+	sizebudget.Pipe{},       // check binaries sizes, after signing
+	archive.Pipe{},          // archive in tar.gz, zip or binary (which does no archiving at all)
+	sourcearchive.Pipe{},    // archive the source code using git-archive
+	nfpm.Pipe{},             // archive via fpm (deb, rpm) using "native" go impl
+	nfpmlint.Pipe{},         // check the linux packages for common problems
+	linuxrepos.Pipe{},       // create apt and yum repositories from the linux packages
+	snapcraft.Pipe{},        // archive via snapcraft (snap)
+	brew.Pipe{},             // create brew tap
+	cask.Pipe{},             // create brew casks
+	gofish.Pipe{},           // create gofish rig
+	krew.Pipe{},             // krew plugins
+	scoop.Pipe{},            // create scoop buckets
+	winget.Pipe{},           // create winget manifests
+	chocolatey.Pipe{},       // create chocolatey packages
+	aur.Pipe{},              // create arch user repository packages
+	nix.Pipe{},              // create nix derivations
+	sbom.Pipe{},             // create SBOMs of artifacts
+	checksums.Pipe{},        // checksums of the files
+	sign.Pipe{},             // sign artifacts
+	docker.Pipe{},           // create and push docker images
+	artifacts.Pipe{},        // creates an artifacts.json in the dist folder
+	publish.Pipe{},          // publishes artifacts
+	announce.Pipe{},         // announce releases
 )
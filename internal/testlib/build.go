@@ -0,0 +1,34 @@
+package testlib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	binaryGoMod  = "module app\n\ngo 1.17\n"
+	binaryMainGo = "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hello\") }\n"
+)
+
+// BuildBinary builds a hello world program for the given goos and goarch to
+// the given path, which must be absolute.
+// The given files, e.g. .syso objects, are added to its main package.
+func BuildBinary(tb testing.TB, goos, goarch, path string, files map[string][]byte) {
+	tb.Helper()
+	dir := tb.TempDir()
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(binaryGoMod), 0o644))
+	require.NoError(tb, os.WriteFile(filepath.Join(dir, "main.go"), []byte(binaryMainGo), 0o644))
+	for name, content := range files {
+		require.NoError(tb, os.WriteFile(filepath.Join(dir, name), content, 0o644))
+	}
+
+	cmd := exec.Command("go", "build", "-o", path, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+	out, err := cmd.CombinedOutput()
+	require.NoError(tb, err, string(out))
+}
@@ -0,0 +1,37 @@
+package testlib
+
+import (
+	"debug/elf"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBinary(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "app")
+	BuildBinary(t, "linux", "arm64", bin, nil)
+
+	f, err := elf.Open(bin)
+	require.NoError(t, err)
+	defer f.Close()
+	require.Equal(t, elf.EM_AARCH64, f.Machine)
+}
+
+func TestBuildBinaryExtraFiles(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "app")
+	BuildBinary(t, "linux", "amd64", bin, map[string][]byte{
+		"version.go": []byte("package main\n\nvar version = \"dev\"\n\nfunc init() { println(version) }\n"),
+	})
+
+	f, err := elf.Open(bin)
+	require.NoError(t, err)
+	defer f.Close()
+	syms, err := f.Symbols()
+	require.NoError(t, err)
+	var found bool
+	for _, sym := range syms {
+		found = found || sym.Name == "main.version"
+	}
+	require.True(t, found, "main.version not found")
+}
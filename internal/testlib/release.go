@@ -0,0 +1,92 @@
+package testlib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeRelease sets the git tag, version and date of the given context, as
+// the pipes run before the package managers would, for the given tag.
+func FakeRelease(tb testing.TB, ctx *context.Context, tag string) {
+	tb.Helper()
+	sv, err := semver.NewVersion(tag)
+	require.NoError(tb, err)
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Git = context.GitInfo{CurrentTag: tag}
+	ctx.Version = strings.TrimPrefix(tag, "v")
+	ctx.Semver = context.Semver{
+		Major:      sv.Major(),
+		Minor:      sv.Minor(),
+		Patch:      sv.Patch(),
+		Prerelease: sv.Prerelease(),
+	}
+	ctx.Date = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+}
+
+// FakeArchive is an archive created by FakeArchives.
+type FakeArchive struct {
+	// ID defaults to the project name.
+	ID     string
+	Goos   string
+	Goarch string
+	Goarm  string
+	// Format defaults to tar.gz.
+	Format string
+}
+
+// Name is the file name of the archive, <id>_<goos>_<goarch><goarm>.<format>.
+func (a FakeArchive) Name() string {
+	return a.ID + "_" + a.Goos + "_" + a.Goarch + a.Goarm + "." + a.Format
+}
+
+// FakeArchives writes the given archives to the dist folder of the context,
+// each with its own name as its contents, and adds them to the artifacts as
+// the archive pipe would.
+// The extra fields returned by extra, if not nil, are added to those of each
+// archive.
+func FakeArchives(
+	tb testing.TB,
+	ctx *context.Context,
+	archives []FakeArchive,
+	extra func(FakeArchive) artifact.Extras,
+) {
+	tb.Helper()
+	for _, archive := range archives {
+		if archive.ID == "" {
+			archive.ID = ctx.Config.ProjectName
+		}
+		if archive.Format == "" {
+			archive.Format = "tar.gz"
+		}
+		name := archive.Name()
+		path := filepath.Join(ctx.Config.Dist, name)
+		require.NoError(tb, os.WriteFile(path, []byte("fake "+name), 0o644))
+
+		fields := artifact.Extras{
+			artifact.ExtraID:     archive.ID,
+			artifact.ExtraFormat: archive.Format,
+		}
+		if extra != nil {
+			for k, v := range extra(archive) {
+				fields[k] = v
+			}
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   name,
+			Path:   path,
+			Goos:   archive.Goos,
+			Goarch: archive.Goarch,
+			Goarm:  archive.Goarm,
+			Type:   artifact.UploadableArchive,
+			Extra:  fields,
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package testlib
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeRelease(t *testing.T) {
+	ctx := context.New(config.Project{})
+	FakeRelease(t, ctx, "v1.2.3-beta.1")
+	require.Equal(t, "v1.2.3-beta.1", ctx.Git.CurrentTag)
+	require.Equal(t, "1.2.3-beta.1", ctx.Version)
+	require.Equal(t, context.Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta.1"}, ctx.Semver)
+	require.False(t, ctx.Date.IsZero())
+}
+
+func TestFakeArchives(t *testing.T) {
+	ctx := context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        t.TempDir(),
+	})
+	FakeArchives(t, ctx, []FakeArchive{
+		{Goos: "linux", Goarch: "arm", Goarm: "7"},
+		{ID: "bar", Goos: "windows", Goarch: "amd64", Format: "zip"},
+	}, func(a FakeArchive) artifact.Extras {
+		return artifact.Extras{
+			artifact.ExtraWrappedIn: a.ID + "_" + a.Goarch,
+		}
+	})
+
+	archives := ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 2)
+
+	require.Equal(t, "foo_linux_arm7.tar.gz", archives[0].Name)
+	require.Equal(t, "7", archives[0].Goarm)
+	require.Equal(t, artifact.Extras{
+		artifact.ExtraID:        "foo",
+		artifact.ExtraFormat:    "tar.gz",
+		artifact.ExtraWrappedIn: "foo_arm",
+	}, archives[0].Extra)
+
+	require.Equal(t, "bar_windows_amd64.zip", archives[1].Name)
+	bts, err := os.ReadFile(archives[1].Path)
+	require.NoError(t, err)
+	require.Equal(t, "fake bar_windows_amd64.zip", string(bts))
+}
@@ -4,6 +4,7 @@ package tmpl
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -195,6 +196,33 @@ func (t *Template) Apply(s string) (string, error) {
 	return out.String(), err
 }
 
+// ApplyFile applies the template to the contents of the src file, writing the
+// result to dst with the same permissions and modification time as src.
+func (t *Template) ApplyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	bts, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	out, err := t.Apply(string(bts))
+	if err != nil {
+		return fmt.Errorf("failed to apply template to %s: %w", src, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, []byte(out), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
 type ExpectedSingleEnvErr struct{}
 
 func (e ExpectedSingleEnvErr) Error() string {
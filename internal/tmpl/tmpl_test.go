@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -330,3 +331,34 @@ func TestWithExtraFields(t *testing.T) {
 	}).Apply("{{ .MyCustomField }}")
 	require.Equal(t, "foo", out)
 }
+
+func TestApplyFile(t *testing.T) {
+	ctx := context.New(config.Project{ProjectName: "proj"})
+	ctx.Version = "1.2.3"
+	folder := t.TempDir()
+	src := filepath.Join(folder, "src.sh")
+	mtime := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, os.WriteFile(src, []byte("#!/bin/sh\necho {{ .ProjectName }} {{ .Version }}\n"), 0o755))
+	require.NoError(t, os.Chtimes(src, mtime, mtime))
+
+	dst := filepath.Join(folder, "out", "dst.sh")
+	require.NoError(t, New(ctx).ApplyFile(src, dst))
+	bts, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\necho proj 1.2.3\n", string(bts))
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	require.Equal(t, mtime, info.ModTime().UTC())
+
+	t.Run("invalid template", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(src, []byte("{{ .Nope }}"), 0o644))
+		err := New(ctx).ApplyFile(src, dst)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to apply template to "+src)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		require.Error(t, New(ctx).ApplyFile(filepath.Join(folder, "nope"), dst))
+	})
+}
@@ -0,0 +1,458 @@
+// Package winres generates COFF objects (.syso files) containing Windows
+// resources, such as the version information, icons and manifests.
+//
+// Those objects are picked up automatically by the go linker when building
+// windows binaries, as long as they live in the main package directory.
+package winres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Resource types, see https://docs.microsoft.com/en-us/windows/win32/menurc/resource-types
+const (
+	rtIcon      = 3
+	rtGroupIcon = 14
+	rtVersion   = 16
+	rtManifest  = 24
+)
+
+const (
+	langEnUS    = 0x0409
+	codepageUTF = 0x04B0 // unicode
+)
+
+// Version holds the version information of a binary.
+type Version struct {
+	ProductName      string
+	ProductVersion   string
+	FileVersion      string
+	FileDescription  string
+	CompanyName      string
+	LegalCopyright   string
+	InternalName     string
+	OriginalFilename string
+}
+
+// Resources to be embedded in a windows binary.
+type Resources struct {
+	Version  Version
+	Icon     []byte // contents of a .ico file
+	Manifest []byte // contents of an application manifest
+}
+
+// ErrUnsupportedArch happens when trying to create resources for an
+// architecture which is not supported.
+var ErrUnsupportedArch = errors.New("unsupported architecture")
+
+type machine struct {
+	id      uint16
+	relType uint16
+}
+
+// nolint: gochecknoglobals
+var machines = map[string]machine{
+	"386":   {id: 0x014c, relType: 0x0007}, // IMAGE_REL_I386_DIR32NB
+	"amd64": {id: 0x8664, relType: 0x0003}, // IMAGE_REL_AMD64_ADDR32NB
+	"arm64": {id: 0xaa64, relType: 0x0002}, // IMAGE_REL_ARM64_ADDR32NB
+}
+
+// Supported returns true if resources can be generated for the given
+// GOARCH.
+func Supported(goarch string) bool {
+	_, ok := machines[goarch]
+	return ok
+}
+
+// COFF creates a COFF object file for the given GOARCH with the given
+// resources.
+func COFF(goarch string, res Resources) ([]byte, error) {
+	mach, ok := machines[goarch]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArch, goarch)
+	}
+
+	var tree resourceTree
+	if err := tree.addIcon(res.Icon); err != nil {
+		return nil, err
+	}
+	if len(res.Manifest) > 0 {
+		tree.add(rtManifest, 1, res.Manifest)
+	}
+	version, err := versionInfo(res.Version)
+	if err != nil {
+		return nil, err
+	}
+	tree.add(rtVersion, 1, version)
+
+	section, relocs := tree.section()
+	return coff(mach, section, relocs), nil
+}
+
+type resource struct {
+	typ  uint32
+	id   uint32
+	data []byte
+}
+
+type resourceTree struct {
+	resources []resource
+}
+
+func (t *resourceTree) add(typ, id uint32, data []byte) {
+	t.resources = append(t.resources, resource{typ: typ, id: id, data: data})
+}
+
+// addIcon adds each image of the given .ico file as a RT_ICON resource, and
+// a RT_GROUP_ICON resource that refers to them.
+func (t *resourceTree) addIcon(ico []byte) error {
+	if len(ico) == 0 {
+		return nil
+	}
+	if len(ico) < 6 ||
+		binary.LittleEndian.Uint16(ico[0:2]) != 0 ||
+		binary.LittleEndian.Uint16(ico[2:4]) != 1 {
+		return errors.New("invalid icon: not an .ico file")
+	}
+	count := int(binary.LittleEndian.Uint16(ico[4:6]))
+	if count == 0 || len(ico) < 6+count*16 {
+		return errors.New("invalid icon: no images")
+	}
+
+	var group bytes.Buffer
+	group.Write(ico[0:6])
+	for i := 0; i < count; i++ {
+		entry := ico[6+i*16 : 6+(i+1)*16]
+		size := binary.LittleEndian.Uint32(entry[8:12])
+		offset := binary.LittleEndian.Uint32(entry[12:16])
+		if uint64(offset)+uint64(size) > uint64(len(ico)) {
+			return fmt.Errorf("invalid icon: image %d is out of bounds", i)
+		}
+		id := uint32(i + 1)
+		t.add(rtIcon, id, ico[offset:offset+size])
+
+		// GRPICONDIRENTRY is the same as ICONDIRENTRY, except that the
+		// image offset is replaced by a 16-bit resource id.
+		group.Write(entry[0:12])
+		_ = binary.Write(&group, binary.LittleEndian, uint16(id))
+	}
+	t.add(rtGroupIcon, 1, group.Bytes())
+	return nil
+}
+
+// section lays out the resource directory tree as described in
+// https://docs.microsoft.com/en-us/windows/win32/debug/pe-format#the-rsrc-section
+//
+// It returns the section contents and the offsets of each data entry RVA
+// that must be relocated.
+func (t *resourceTree) section() ([]byte, []uint32) {
+	resources := append([]resource{}, t.resources...)
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].typ == resources[j].typ {
+			return resources[i].id < resources[j].id
+		}
+		return resources[i].typ < resources[j].typ
+	})
+
+	var types []uint32
+	byType := map[uint32][]resource{}
+	for _, r := range resources {
+		if _, ok := byType[r.typ]; !ok {
+			types = append(types, r.typ)
+		}
+		byType[r.typ] = append(byType[r.typ], r)
+	}
+
+	const (
+		dirSize   = 16
+		entrySize = 8
+		dataSize  = 16
+	)
+
+	// sizes of each level of the tree: types, names and languages.
+	typesSize := dirSize + entrySize*len(types)
+	namesSize := 0
+	for _, typ := range types {
+		namesSize += dirSize + entrySize*len(byType[typ])
+	}
+	langsSize := (dirSize + entrySize) * len(resources)
+	dataEntriesStart := typesSize + namesSize + langsSize
+	rawStart := dataEntriesStart + dataSize*len(resources)
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	writeDir := func(entries int) {
+		_ = binary.Write(&buf, le, [4]uint32{0, 0, 0, uint32(entries)}) // entries are all ids
+	}
+	writeEntry := func(id, offset uint32, subdir bool) {
+		if subdir {
+			offset |= 0x80000000
+		}
+		_ = binary.Write(&buf, le, [2]uint32{id, offset})
+	}
+
+	// types
+	writeDir(len(types))
+	nameOffset := typesSize
+	for _, typ := range types {
+		writeEntry(typ, uint32(nameOffset), true)
+		nameOffset += dirSize + entrySize*len(byType[typ])
+	}
+
+	// names
+	langOffset := typesSize + namesSize
+	for _, typ := range types {
+		writeDir(len(byType[typ]))
+		for _, r := range byType[typ] {
+			writeEntry(r.id, uint32(langOffset), true)
+			langOffset += dirSize + entrySize
+		}
+	}
+
+	// languages
+	for i := range resources {
+		writeDir(1)
+		writeEntry(langEnUS, uint32(dataEntriesStart+dataSize*i), false)
+	}
+
+	// data entries
+	var relocs []uint32
+	offset := rawStart
+	for _, r := range resources {
+		relocs = append(relocs, uint32(buf.Len()))
+		_ = binary.Write(&buf, le, [4]uint32{uint32(offset), uint32(len(r.data)), 0, 0})
+		offset = alignUp(offset+len(r.data), 8)
+	}
+
+	// raw data
+	for _, r := range resources {
+		buf.Write(r.data)
+		pad(&buf, 8)
+	}
+	return buf.Bytes(), relocs
+}
+
+// coff writes a COFF object with a single .rsrc section.
+// See https://docs.microsoft.com/en-us/windows/win32/debug/pe-format#coff-file-header-object-and-image
+func coff(mach machine, section []byte, relocs []uint32) []byte {
+	const (
+		fileHeaderSize    = 20
+		sectionHeaderSize = 40
+		relocSize         = 10
+	)
+	le := binary.LittleEndian
+	rawOffset := uint32(fileHeaderSize + sectionHeaderSize)
+	relocsOffset := rawOffset + uint32(len(section))
+	symbolsOffset := relocsOffset + uint32(relocSize*len(relocs))
+
+	var buf bytes.Buffer
+	// file header
+	_ = binary.Write(&buf, le, mach.id)
+	_ = binary.Write(&buf, le, uint16(1))     // number of sections
+	_ = binary.Write(&buf, le, uint32(0))     // timestamp, 0 for reproducible builds
+	_ = binary.Write(&buf, le, symbolsOffset) // pointer to symbol table
+	_ = binary.Write(&buf, le, uint32(1))     // number of symbols
+	_ = binary.Write(&buf, le, uint16(0))     // size of optional header
+	_ = binary.Write(&buf, le, uint16(0))     // characteristics
+
+	// section header
+	buf.WriteString(".rsrc\x00\x00\x00")
+	_ = binary.Write(&buf, le, uint32(0)) // virtual size
+	_ = binary.Write(&buf, le, uint32(0)) // virtual address
+	_ = binary.Write(&buf, le, uint32(len(section)))
+	_ = binary.Write(&buf, le, rawOffset)
+	_ = binary.Write(&buf, le, relocsOffset)
+	_ = binary.Write(&buf, le, uint32(0)) // pointer to line numbers
+	_ = binary.Write(&buf, le, uint16(len(relocs)))
+	_ = binary.Write(&buf, le, uint16(0))          // number of line numbers
+	_ = binary.Write(&buf, le, uint32(0x40000040)) // IMAGE_SCN_CNT_INITIALIZED_DATA | IMAGE_SCN_MEM_READ
+
+	buf.Write(section)
+
+	// relocations, all relative to the .rsrc section symbol
+	for _, r := range relocs {
+		_ = binary.Write(&buf, le, r)
+		_ = binary.Write(&buf, le, uint32(0)) // symbol index
+		_ = binary.Write(&buf, le, mach.relType)
+	}
+
+	// symbol table
+	buf.WriteString(".rsrc\x00\x00\x00")
+	_ = binary.Write(&buf, le, uint32(0)) // value
+	_ = binary.Write(&buf, le, int16(1))  // section number
+	_ = binary.Write(&buf, le, uint16(0)) // type
+	buf.WriteByte(3)                      // IMAGE_SYM_CLASS_STATIC
+	buf.WriteByte(0)                      // number of aux symbols
+
+	// empty string table
+	_ = binary.Write(&buf, le, uint32(4))
+	return buf.Bytes()
+}
+
+// versionInfo creates a VS_VERSIONINFO resource.
+// See https://docs.microsoft.com/en-us/windows/win32/menurc/vs-versioninfo
+func versionInfo(v Version) ([]byte, error) {
+	fileVersion, err := parseVersion(v.FileVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file version: %w", err)
+	}
+	productVersion, err := parseVersion(v.ProductVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product version: %w", err)
+	}
+
+	var fixed bytes.Buffer
+	_ = binary.Write(&fixed, binary.LittleEndian, [13]uint32{
+		0xFEEF04BD, // signature
+		0x00010000, // struct version
+		fileVersion[0]<<16 | fileVersion[1],
+		fileVersion[2]<<16 | fileVersion[3],
+		productVersion[0]<<16 | productVersion[1],
+		productVersion[2]<<16 | productVersion[3],
+		0x3F,    // file flags mask
+		0,       // file flags
+		0x40004, // VOS_NT_WINDOWS32
+		1,       // VFT_APP
+		0,       // file subtype
+		0,       // file date
+		0,
+	})
+
+	var values []node
+	for _, kv := range [][2]string{
+		{"CompanyName", v.CompanyName},
+		{"FileDescription", v.FileDescription},
+		{"FileVersion", v.FileVersion},
+		{"InternalName", v.InternalName},
+		{"LegalCopyright", v.LegalCopyright},
+		{"OriginalFilename", v.OriginalFilename},
+		{"ProductName", v.ProductName},
+		{"ProductVersion", v.ProductVersion},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		value := utf16z(kv[1])
+		values = append(values, node{
+			key:      kv[0],
+			text:     true,
+			value:    value,
+			valueLen: uint16(len(value) / 2),
+		})
+	}
+
+	translation := make([]byte, 4)
+	binary.LittleEndian.PutUint16(translation[0:2], langEnUS)
+	binary.LittleEndian.PutUint16(translation[2:4], codepageUTF)
+
+	root := node{
+		key:      "VS_VERSION_INFO",
+		value:    fixed.Bytes(),
+		valueLen: uint16(fixed.Len()),
+		children: []node{
+			{
+				key:  "StringFileInfo",
+				text: true,
+				children: []node{{
+					key:      fmt.Sprintf("%04X%04X", langEnUS, codepageUTF),
+					text:     true,
+					children: values,
+				}},
+			},
+			{
+				key:  "VarFileInfo",
+				text: true,
+				children: []node{{
+					key:      "Translation",
+					value:    translation,
+					valueLen: uint16(len(translation)),
+				}},
+			},
+		},
+	}
+	return root.bytes(), nil
+}
+
+// node is any of the structures within a VS_VERSIONINFO, which all share the
+// same layout.
+type node struct {
+	key      string
+	text     bool
+	value    []byte
+	valueLen uint16
+	children []node
+}
+
+func (n node) bytes() []byte {
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	_ = binary.Write(&buf, le, uint16(0)) // length, set below
+	_ = binary.Write(&buf, le, n.valueLen)
+	typ := uint16(0)
+	if n.text {
+		typ = 1
+	}
+	_ = binary.Write(&buf, le, typ)
+	buf.Write(utf16z(n.key))
+	pad(&buf, 4)
+	buf.Write(n.value)
+	for _, child := range n.children {
+		pad(&buf, 4)
+		buf.Write(child.bytes())
+	}
+	b := buf.Bytes()
+	le.PutUint16(b[0:2], uint16(len(b)))
+	return b
+}
+
+// parseVersion parses the numeric parts of a version such as 1.2.3-beta1
+// into the 4 numbers windows expects.
+func parseVersion(s string) ([4]uint32, error) {
+	var result [4]uint32
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return result, nil
+	}
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 4 {
+		return result, fmt.Errorf("%q has more than 4 parts", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return result, fmt.Errorf("%q is not a valid version: %w", s, err)
+		}
+		result[i] = uint32(n)
+	}
+	return result, nil
+}
+
+// utf16z encodes the given string as null-terminated UTF-16LE.
+func utf16z(s string) []byte {
+	codes := utf16.Encode([]rune(s + "\x00"))
+	b := make([]byte, len(codes)*2)
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(b[i*2:], c)
+	}
+	return b
+}
+
+func pad(buf *bytes.Buffer, alignment int) {
+	for buf.Len()%alignment != 0 {
+		buf.WriteByte(0)
+	}
+}
+
+func alignUp(n, alignment int) int {
+	return (n + alignment - 1) / alignment * alignment
+}
@@ -0,0 +1,142 @@
+package winres
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupported(t *testing.T) {
+	require.True(t, Supported("386"))
+	require.True(t, Supported("amd64"))
+	require.True(t, Supported("arm64"))
+	require.False(t, Supported("arm"))
+}
+
+func TestCOFFUnsupportedArch(t *testing.T) {
+	_, err := COFF("arm", Resources{})
+	require.ErrorIs(t, err, ErrUnsupportedArch)
+}
+
+func TestCOFFInvalidIcon(t *testing.T) {
+	_, err := COFF("amd64", Resources{Icon: []byte("not an icon")})
+	require.EqualError(t, err, "invalid icon: not an .ico file")
+}
+
+func TestCOFFInvalidVersion(t *testing.T) {
+	_, err := COFF("amd64", Resources{Version: Version{FileVersion: "a.b.c"}})
+	require.Error(t, err)
+	_, err = COFF("amd64", Resources{Version: Version{ProductVersion: "1.2.3.4.5"}})
+	require.Error(t, err)
+}
+
+func TestParseVersion(t *testing.T) {
+	for input, expected := range map[string][4]uint32{
+		"":               {0, 0, 0, 0},
+		"1":              {1, 0, 0, 0},
+		"v1.2.3":         {1, 2, 3, 0},
+		"1.2.3-beta.1":   {1, 2, 3, 0},
+		"1.2.3+metadata": {1, 2, 3, 0},
+		"1.2.3.4":        {1, 2, 3, 4},
+	} {
+		t.Run(input, func(t *testing.T) {
+			v, err := parseVersion(input)
+			require.NoError(t, err)
+			require.Equal(t, expected, v)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	icon, err := os.ReadFile("testdata/icon.ico")
+	require.NoError(t, err)
+	manifest := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0"></assembly>`)
+
+	for _, goarch := range []string{"386", "amd64", "arm64"} {
+		goarch := goarch
+		t.Run(goarch, func(t *testing.T) {
+			obj, err := COFF(goarch, Resources{
+				Icon:     icon,
+				Manifest: manifest,
+				Version: Version{
+					ProductName:    "My App",
+					ProductVersion: "1.2.3",
+					FileVersion:    "1.2.3-rc1",
+					CompanyName:    "ACME Inc.",
+				},
+			})
+			require.NoError(t, err)
+
+			bin := filepath.Join(t.TempDir(), "app.exe")
+			testlib.BuildBinary(t, "windows", goarch, bin, map[string][]byte{
+				"rsrc_windows_" + goarch + ".syso": obj,
+			})
+
+			f, err := pe.Open(bin)
+			require.NoError(t, err)
+			defer f.Close()
+
+			rsrc := f.Section(".rsrc")
+			require.NotNil(t, rsrc)
+			data, err := rsrc.Data()
+			require.NoError(t, err)
+
+			resources := readResources(t, data, rsrc.VirtualAddress)
+			require.Len(t, resources[rtIcon], 2)
+			require.Equal(t, icon[38:38+28], resources[rtIcon][1])
+			require.Equal(t, icon[38+28:], resources[rtIcon][2])
+			require.Len(t, resources[rtGroupIcon], 1)
+			require.Equal(t, manifest, resources[rtManifest][1])
+
+			version := resources[rtVersion][1]
+			require.True(t, bytes.Contains(version, utf16z("VS_VERSION_INFO")))
+			require.True(t, bytes.Contains(version, utf16z("ACME Inc.")))
+			require.True(t, bytes.Contains(version, utf16z("1.2.3-rc1")))
+			require.Equal(t, uint16(len(version)), binary.LittleEndian.Uint16(version[0:2]))
+			fixed := version[40 : 40+52]
+			require.Equal(t, uint32(0xFEEF04BD), binary.LittleEndian.Uint32(fixed[0:4]))
+			require.Equal(t, uint32(1<<16|2), binary.LittleEndian.Uint32(fixed[8:12]))
+			require.Equal(t, uint32(3<<16), binary.LittleEndian.Uint32(fixed[12:16]))
+		})
+	}
+}
+
+// readResources walks the resource directory of a linked binary, returning
+// the data of each resource by type and id.
+func readResources(tb testing.TB, data []byte, rva uint32) map[uint32]map[uint32][]byte {
+	tb.Helper()
+	le := binary.LittleEndian
+	entries := func(off uint32) [][2]uint32 {
+		n := uint32(le.Uint16(data[off+12:])) + uint32(le.Uint16(data[off+14:]))
+		var result [][2]uint32
+		for i := uint32(0); i < n; i++ {
+			e := off + 16 + i*8
+			result = append(result, [2]uint32{le.Uint32(data[e:]), le.Uint32(data[e+4:])})
+		}
+		return result
+	}
+
+	result := map[uint32]map[uint32][]byte{}
+	for _, typ := range entries(0) {
+		require.NotZero(tb, typ[1]&0x80000000)
+		result[typ[0]] = map[uint32][]byte{}
+		for _, name := range entries(typ[1] &^ 0x80000000) {
+			require.NotZero(tb, name[1]&0x80000000)
+			langs := entries(name[1] &^ 0x80000000)
+			require.Len(tb, langs, 1)
+			require.Equal(tb, uint32(langEnUS), langs[0][0])
+			entry := langs[0][1]
+			dataRVA := le.Uint32(data[entry:])
+			size := le.Uint32(data[entry+4:])
+			start := dataRVA - rva
+			result[typ[0]][name[0]] = data[start : start+size]
+		}
+	}
+	return result
+}
@@ -2,14 +2,22 @@
 package archive
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
 	"github.com/goreleaser/goreleaser/pkg/archive/gzip"
+	"github.com/goreleaser/goreleaser/pkg/archive/makeself"
 	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarbz2"
 	"github.com/goreleaser/goreleaser/pkg/archive/targz"
 	"github.com/goreleaser/goreleaser/pkg/archive/tarxz"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarzst"
 	"github.com/goreleaser/goreleaser/pkg/archive/zip"
+	"github.com/goreleaser/goreleaser/pkg/archive/zstd"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
@@ -19,22 +27,156 @@ type Archive interface {
 	Add(f config.File) error
 }
 
+// Options of the archive.
+type Options struct {
+	// Level is the compression level of tar.zst, tar.bz2 and zst archives.
+	// 0 means the default level of the format.
+	Level int
+
+	// Reproducible sorts the files, and normalizes their ownership,
+	// permissions and timestamps, so the same files always produce the same
+	// archive.
+	Reproducible bool
+
+	// MTime is the modification time of the files without one, when
+	// Reproducible is set. Defaults to the unix epoch.
+	MTime time.Time
+
+	// Makeself are the options of self-extracting run and sh archives.
+	Makeself makeself.Options
+}
+
 // New archive.
 func New(file *os.File) Archive {
+	// the error will be nil since the default compression level is valid
+	a, _ := NewWithOptions(file, Options{})
+	return a
+}
+
+// NewWithOptions creates a new archive with the given options.
+func NewWithOptions(file *os.File, opts Options) (Archive, error) {
+	a, err := newArchive(file, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Reproducible {
+		return newReproducible(a, opts.MTime), nil
+	}
+	return a, nil
+}
+
+func newArchive(file *os.File, opts Options) (Archive, error) {
 	if strings.HasSuffix(file.Name(), ".tar.gz") {
-		return targz.New(file)
+		return targz.New(file), nil
 	}
 	if strings.HasSuffix(file.Name(), ".gz") {
-		return gzip.New(file)
+		return gzip.New(file), nil
 	}
 	if strings.HasSuffix(file.Name(), ".tar.xz") {
-		return tarxz.New(file)
+		return tarxz.New(file), nil
+	}
+	if strings.HasSuffix(file.Name(), ".tar.zst") {
+		return tarzst.New(file, opts.Level)
+	}
+	if strings.HasSuffix(file.Name(), ".zst") {
+		return zstd.New(file, opts.Level)
+	}
+	if strings.HasSuffix(file.Name(), ".tar.bz2") {
+		return tarbz2.New(file, opts.Level)
 	}
 	if strings.HasSuffix(file.Name(), ".zip") {
-		return zip.New(file)
+		return zip.New(file), nil
+	}
+	if strings.HasSuffix(file.Name(), ".run") || strings.HasSuffix(file.Name(), ".sh") {
+		if err := file.Chmod(0o755); err != nil {
+			return nil, err
+		}
+		return makeself.New(file, opts.Makeself)
 	}
 	if strings.HasSuffix(file.Name(), ".tar") {
-		return tar.New(file)
+		return tar.New(file), nil
+	}
+	return targz.New(file), nil
+}
+
+var errUnsupportedFormat = errors.New("unsupported archive format")
+
+// List the entries of the archive at the given path.
+func List(path string) ([]entry.Entry, error) {
+	var entries []entry.Entry
+	err := read(path, func(file *os.File, size int64) error {
+		var err error
+		switch format(path) {
+		case "tar.gz":
+			entries, err = targz.List(file)
+		case "gz":
+			entries, err = gzip.List(file)
+		case "tar.xz":
+			entries, err = tarxz.List(file)
+		case "tar.zst":
+			entries, err = tarzst.List(file)
+		case "tar.bz2":
+			entries, err = tarbz2.List(file)
+		case "zip":
+			entries, err = zip.List(file, size)
+		case "tar":
+			entries, err = tar.List(file)
+		default:
+			err = errUnsupportedFormat
+		}
+		return err
+	})
+	return entries, err
+}
+
+// Extract the archive at the given path into dir.
+//
+// Entries that would be extracted outside of dir are refused.
+func Extract(path, dir string) error {
+	return read(path, func(file *os.File, size int64) error {
+		switch format(path) {
+		case "tar.gz":
+			return targz.Extract(file, dir)
+		case "gz":
+			return gzip.Extract(file, dir)
+		case "tar.xz":
+			return tarxz.Extract(file, dir)
+		case "tar.zst":
+			return tarzst.Extract(file, dir)
+		case "tar.bz2":
+			return tarbz2.Extract(file, dir)
+		case "zip":
+			return zip.Extract(file, size, dir)
+		case "tar":
+			return tar.Extract(file, dir)
+		default:
+			return errUnsupportedFormat
+		}
+	})
+}
+
+func read(path string, fn func(file *os.File, size int64) error) error {
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := fn(file, info.Size()); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
+
+// format returns the archive format based on the file extension.
+func format(path string) string {
+	for _, format := range []string{"tar.gz", "gz", "tar.xz", "tar.zst", "tar.bz2", "zip", "tar"} {
+		if strings.HasSuffix(path, "."+format) {
+			return format
+		}
 	}
-	return targz.New(file)
+	return ""
 }
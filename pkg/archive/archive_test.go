@@ -2,8 +2,10 @@ package archive
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/makeself"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 )
@@ -15,7 +17,7 @@ func TestArchive(t *testing.T) {
 	require.NoError(t, empty.Close())
 	require.NoError(t, os.Mkdir(folder+"/folder-inside", 0o755))
 
-	for _, format := range []string{"tar.gz", "zip", "gz", "tar.xz", "tar", "willbeatargzanyway"} {
+	for _, format := range []string{"tar.gz", "zip", "gz", "tar.xz", "tar", "tar.zst", "zst", "tar.bz2", "run", "sh", "willbeatargzanyway"} {
 		format := format
 		t.Run(format, func(t *testing.T) {
 			file, err := os.Create(folder + "/folder." + format)
@@ -36,3 +38,110 @@ func TestArchive(t *testing.T) {
 		})
 	}
 }
+
+func TestArchiveWithOptions(t *testing.T) {
+	folder := t.TempDir()
+	for format, level := range map[string]int{
+		"tar.zst": 19,
+		"zst":     1,
+		"tar.bz2": 9,
+		"tar.gz":  42, // ignored
+	} {
+		format, level := format, level
+		t.Run(format, func(t *testing.T) {
+			file, err := os.Create(folder + "/folder." + format)
+			require.NoError(t, err)
+			archive, err := NewWithOptions(file, Options{Level: level})
+			require.NoError(t, err)
+			require.NoError(t, archive.Add(config.File{
+				Source:      "testdata/foo.txt",
+				Destination: "foo.txt",
+			}))
+			require.NoError(t, archive.Close())
+			require.NoError(t, file.Close())
+		})
+	}
+}
+
+func TestArchiveMakeself(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "installer.run")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	archive, err := NewWithOptions(file, Options{Makeself: makeself.Options{
+		Label:  "foo",
+		Prefix: "/opt/foo",
+	}})
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{
+		Source:      "testdata/foo.txt",
+		Destination: "foo.txt",
+	}))
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	bts, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), "#!/bin/sh\n")
+	require.Contains(t, string(bts), "PREFIX='/opt/foo'")
+}
+
+func TestArchiveWithInvalidLevel(t *testing.T) {
+	folder := t.TempDir()
+	for _, format := range []string{"tar.zst", "zst", "tar.bz2"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			file, err := os.Create(folder + "/folder." + format)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, file.Close()) })
+			_, err = NewWithOptions(file, Options{Level: 23})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestListExtract(t *testing.T) {
+	folder := t.TempDir()
+	for _, format := range []string{"tar.gz", "zip", "gz", "tar.xz", "tar", "tar.zst", "tar.bz2"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(folder, "archive."+format)
+			file, err := os.Create(path)
+			require.NoError(t, err)
+			archive := New(file)
+			require.NoError(t, archive.Add(config.File{
+				Source:      "testdata/foo.txt",
+				Destination: "sub/foo.txt",
+			}))
+			require.NoError(t, archive.Close())
+			require.NoError(t, file.Close())
+
+			entries, err := List(path)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, "sub/foo.txt", entries[0].Name)
+			require.Equal(t, int64(4), entries[0].Size)
+
+			dir := filepath.Join(folder, format)
+			require.NoError(t, Extract(path, dir))
+			bts, err := os.ReadFile(filepath.Join(dir, "sub", "foo.txt"))
+			require.NoError(t, err)
+			require.Equal(t, "foo\n", string(bts))
+		})
+	}
+}
+
+func TestListExtractUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo.zst")
+	require.NoError(t, os.WriteFile(path, []byte("foo"), 0o644))
+	_, err := List(path)
+	require.EqualError(t, err, "failed to read "+path+": unsupported archive format")
+	require.EqualError(t, Extract(path, t.TempDir()), "failed to read "+path+": unsupported archive format")
+}
+
+func TestListNotFound(t *testing.T) {
+	_, err := List("testdata/nope.tar.gz")
+	require.EqualError(t, err, "open testdata/nope.tar.gz: no such file or directory")
+}
@@ -0,0 +1,119 @@
+// Package entry provides the types and helpers shared by the archive readers.
+package entry
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is a file inside an archive.
+type Entry struct {
+	Name     string
+	Mode     os.FileMode
+	Size     int64
+	MTime    time.Time
+	Owner    string
+	Group    string
+	Linkname string
+}
+
+// Path returns where the named entry should be extracted to inside dir.
+//
+// It fails if the entry would end up outside of dir, or if it would be written
+// through a symlink that already exists in dir.
+func Path(dir, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || strings.HasPrefix(clean, string(filepath.Separator)) {
+		return "", fmt.Errorf("%s: illegal absolute path", name)
+	}
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s: illegal path outside of the extraction directory", name)
+	}
+	if clean == "." {
+		return dir, nil
+	}
+
+	current := dir
+	for _, part := range strings.Split(clean, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("%s: illegal path through symlink %s", name, current)
+		}
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+// Extract writes the entry, with the contents read from r, inside dir.
+//
+// Only directories, regular files and symlinks pointing inside dir are
+// supported.
+func Extract(dir string, e Entry, r io.Reader) error {
+	path, err := Path(dir, e.Name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case e.Mode.IsDir():
+		return os.MkdirAll(path, perm(e.Mode, 0o755))
+	case e.Mode&os.ModeSymlink != 0:
+		return symlink(dir, path, e)
+	case e.Mode.IsRegular():
+		return file(path, e, r)
+	default:
+		return fmt.Errorf("%s: unsupported file type %s", e.Name, e.Mode.Type())
+	}
+}
+
+func symlink(dir, path string, e Entry) error {
+	target := filepath.FromSlash(e.Linkname)
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("%s: illegal absolute symlink to %s", e.Name, e.Linkname)
+	}
+	rel, err := filepath.Rel(dir, filepath.Join(filepath.Dir(path), target))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s: illegal symlink to %s outside of the extraction directory", e.Name, e.Linkname)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.Symlink(target, path)
+}
+
+func file(path string, e Entry, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm(e.Mode, 0o644))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { // nolint: gosec
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if e.MTime.IsZero() {
+		return nil
+	}
+	return os.Chtimes(path, e.MTime, e.MTime)
+}
+
+func perm(mode, def os.FileMode) os.FileMode {
+	if mode.Perm() == 0 {
+		return def
+	}
+	return mode.Perm()
+}
@@ -0,0 +1,105 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.Symlink(os.TempDir(), filepath.Join(dir, "link")))
+
+	for name, expected := range map[string]string{
+		"foo.txt":         filepath.Join(dir, "foo.txt"),
+		"./foo.txt":       filepath.Join(dir, "foo.txt"),
+		"sub/foo.txt":     filepath.Join(dir, "sub", "foo.txt"),
+		"sub/../foo.txt":  filepath.Join(dir, "foo.txt"),
+		"sub/new/foo.txt": filepath.Join(dir, "sub", "new", "foo.txt"),
+		"./":              dir,
+	} {
+		path, err := Path(dir, name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, path, name)
+	}
+
+	for name, msg := range map[string]string{
+		"/etc/passwd":       "illegal absolute path",
+		"..":                "illegal path outside of the extraction directory",
+		"../foo.txt":        "illegal path outside of the extraction directory",
+		"sub/../../foo.txt": "illegal path outside of the extraction directory",
+		"link":              "illegal path through symlink",
+		"link/foo.txt":      "illegal path through symlink",
+	} {
+		_, err := Path(dir, name)
+		require.Error(t, err, name)
+		require.Contains(t, err.Error(), msg, name)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Extract(dir, Entry{Name: "sub/", Mode: os.ModeDir | 0o700}, nil))
+	require.NoError(t, Extract(dir, Entry{Name: "sub/foo.txt", Mode: 0o600, MTime: mtime}, strings.NewReader("foo")))
+	require.NoError(t, Extract(dir, Entry{Name: "bar/bar.txt"}, strings.NewReader("bar")))
+	require.NoError(t, Extract(dir, Entry{Name: "bar/link.txt", Mode: os.ModeSymlink | 0o777, Linkname: "../sub/foo.txt"}, nil))
+
+	info, err := os.Stat(filepath.Join(dir, "sub"))
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+	require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Join(dir, "sub", "foo.txt"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	require.Equal(t, mtime, info.ModTime().UTC())
+
+	info, err = os.Stat(filepath.Join(dir, "bar", "bar.txt"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	bts, err := os.ReadFile(filepath.Join(dir, "bar", "link.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "foo", string(bts))
+}
+
+func TestExtractInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink(os.TempDir(), filepath.Join(dir, "tmp")))
+
+	for msg, e := range map[string]Entry{
+		"illegal path outside of the extraction directory": {
+			Name: "../evil.txt",
+		},
+		"illegal path through symlink": {
+			Name: "tmp/evil.txt",
+		},
+		"illegal absolute symlink": {
+			Name:     "abs",
+			Mode:     os.ModeSymlink | 0o777,
+			Linkname: "/etc/passwd",
+		},
+		"illegal symlink to ../../etc outside of the extraction directory": {
+			Name:     "sub/rel",
+			Mode:     os.ModeSymlink | 0o777,
+			Linkname: "../../etc",
+		},
+		"unsupported file type": {
+			Name: "pipe",
+			Mode: os.ModeNamedPipe | 0o644,
+		},
+	} {
+		err := Extract(dir, e, strings.NewReader("evil"))
+		require.Error(t, err, msg)
+		require.Contains(t, err.Error(), msg)
+	}
+	require.NoFileExists(t, filepath.Join(filepath.Dir(dir), "evil.txt"))
+	require.NoFileExists(t, filepath.Join(os.TempDir(), "evil.txt"))
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
@@ -56,3 +57,40 @@ func (a Archive) Add(f config.File) error {
 	_, err = io.Copy(a.gw, file)
 	return err
 }
+
+// List the file inside the gz archive read from r.
+func List(r io.Reader) ([]entry.Entry, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	size, err := io.Copy(io.Discard, gr)
+	if err != nil {
+		return nil, err
+	}
+	return []entry.Entry{{
+		Name:  gr.Header.Name,
+		Size:  size,
+		MTime: gr.Header.ModTime,
+	}}, nil
+}
+
+// Extract the file inside the gz archive read from r into dir.
+//
+// As gz does not store file modes, the file is extracted with 0644
+// permissions.
+func Extract(r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	if gr.Header.Name == "" {
+		return fmt.Errorf("gzip: archive has no file name")
+	}
+	return entry.Extract(dir, entry.Entry{
+		Name:  gr.Header.Name,
+		MTime: gr.Header.ModTime,
+	}, gr)
+}
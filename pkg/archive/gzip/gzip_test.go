@@ -84,3 +84,75 @@ func TestGzFileCustomMtime(t *testing.T) {
 	require.Equal(t, "sub1/sub2/subfoo.txt", gzf.Name)
 	require.Equal(t, now, gzf.Header.ModTime)
 }
+
+func TestGzListExtract(t *testing.T) {
+	tmp := t.TempDir()
+	mtime := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	f, err := os.Create(filepath.Join(tmp, "test.gz"))
+	require.NoError(t, err)
+	archive := New(f)
+	require.NoError(t, archive.Add(config.File{
+		Destination: "sub1/sub2/subfoo.txt",
+		Source:      "../testdata/sub1/sub2/subfoo.txt",
+		Info:        config.FileInfo{MTime: mtime},
+	}))
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	entries, err := List(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Len(t, entries, 1)
+	require.Equal(t, "sub1/sub2/subfoo.txt", entries[0].Name)
+	require.Equal(t, int64(4), entries[0].Size)
+	require.Equal(t, mtime, entries[0].MTime.UTC())
+
+	dir := filepath.Join(tmp, "out")
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.NoError(t, Extract(f, dir))
+	bts, err := os.ReadFile(filepath.Join(dir, "sub1", "sub2", "subfoo.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "sub\n", string(bts))
+}
+
+func TestGzExtractPathTraversal(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "evil.gz")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	gw := gzip.NewWriter(f)
+	gw.Name = "../evil.txt"
+	_, err = gw.Write([]byte("evil"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	err = Extract(f, filepath.Join(tmp, "out"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "illegal path outside of the extraction directory")
+	require.NoFileExists(t, filepath.Join(tmp, "evil.txt"))
+}
+
+func TestGzExtractNoName(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "noname.gz")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	gw := gzip.NewWriter(f)
+	_, err = gw.Write([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(path)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.EqualError(t, Extract(f, tmp), "gzip: archive has no file name")
+}
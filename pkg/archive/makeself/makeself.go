@@ -0,0 +1,255 @@
+// Package makeself implements the Archive interface providing self-extracting
+// shell script archives, like the ones created by makeself.
+package makeself
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/targz"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// DefaultPrefix is the default installation directory.
+const DefaultPrefix = "/usr/local"
+
+// Options of the self-extracting archive.
+type Options struct {
+	// Label is shown to the user while installing.
+	Label string
+
+	// Prefix is the default installation directory, which the user can
+	// change with --prefix.
+	Prefix string
+
+	// License, if not empty, is shown to the user, who needs to accept it
+	// before installing.
+	License string
+
+	// PostInstall, if not empty, is a shell script run inside the
+	// installation directory after extracting the files.
+	PostInstall string
+}
+
+// Archive as a self-extracting shell script.
+type Archive struct {
+	target  io.Writer
+	opts    Options
+	payload *os.File
+	tw      *targz.Archive
+	closed  *bool
+}
+
+// New self-extracting archive.
+//
+// The files are compressed into a temporary tar.gz, which is appended to the
+// installer script on Close.
+func New(target io.Writer, opts Options) (Archive, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultPrefix
+	}
+	payload, err := os.CreateTemp("", "goreleaser-makeself-*.tar.gz")
+	if err != nil {
+		return Archive{}, err
+	}
+	tw := targz.New(payload)
+	return Archive{
+		target:  target,
+		opts:    opts,
+		payload: payload,
+		tw:      &tw,
+		closed:  new(bool),
+	}, nil
+}
+
+// Close writes the installer script followed by the payload to the target.
+func (a Archive) Close() error {
+	if *a.closed {
+		return nil
+	}
+	*a.closed = true
+	defer os.Remove(a.payload.Name())
+	defer a.payload.Close()
+
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	if _, err := a.payload.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	sum := sha256.New()
+	if _, err := io.Copy(sum, a.payload); err != nil {
+		return err
+	}
+	header, err := script(a.opts, hex.EncodeToString(sum.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(a.target, header); err != nil {
+		return err
+	}
+	if _, err := a.payload.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(a.target, a.payload)
+	return err
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
+
+// script renders the installer script, which extracts the payload appended
+// right after it.
+func script(opts Options, sha256sum string) (string, error) {
+	for _, s := range []string{opts.License, opts.PostInstall} {
+		if strings.Contains(s, heredocEOF) {
+			return "", fmt.Errorf("makeself: license and post install script can't contain %q", heredocEOF)
+		}
+	}
+	data := struct {
+		Options
+		SHA256 string
+		Skip   int
+		EOF    string
+	}{
+		Options: opts,
+		SHA256:  sha256sum,
+		EOF:     heredocEOF,
+	}
+	// the number of lines doesn't depend on the skip value, so render once
+	// to count them, and again with the right value.
+	var out bytes.Buffer
+	if err := scriptTmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	data.Skip = strings.Count(out.String(), "\n") + 1
+	out.Reset()
+	if err := scriptTmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+const heredocEOF = "GORELEASER_MAKESELF_EOF"
+
+// nolint: gochecknoglobals
+var scriptTmpl = template.Must(template.New("makeself").Funcs(template.FuncMap{
+	"quote": quote,
+}).Parse(`#!/bin/sh
+# Self-extracting installer generated by GoReleaser.
+# Run it with --help to see the available options.
+set -e
+
+LABEL={{ quote .Label }}
+PREFIX={{ quote .Prefix }}
+SHA256={{ quote .SHA256 }}
+SKIP={{ .Skip }}
+ACCEPT=0
+ACTION=install
+
+usage() {
+	echo "$LABEL"
+	echo
+	echo "Usage: $0 [options]"
+	echo
+	echo "Options:"
+	echo "  --prefix DIR  install into DIR (default: $PREFIX)"
+{{- if .License }}
+	echo "  --accept      accept the license without prompting"
+{{- end }}
+	echo "  --list        list the files inside the installer"
+	echo "  --check       verify the installer checksum"
+	echo "  --help        show this help"
+}
+
+while [ $# -gt 0 ]; do
+	case "$1" in
+		--prefix) PREFIX="$2"; shift ;;
+		--prefix=*) PREFIX="${1#--prefix=}" ;;
+		--accept) ACCEPT=1 ;;
+		--list) ACTION=list ;;
+		--check) ACTION=check ;;
+		--help|-h) usage; exit 0 ;;
+		*) echo "unknown option: $1" >&2; usage >&2; exit 1 ;;
+	esac
+	shift
+done
+
+payload() {
+	tail -n +"$SKIP" "$0"
+}
+
+verify() {
+	if command -v sha256sum >/dev/null 2>&1; then
+		sum=$(payload | sha256sum | cut -d ' ' -f 1)
+	elif command -v shasum >/dev/null 2>&1; then
+		sum=$(payload | shasum -a 256 | cut -d ' ' -f 1)
+	else
+		echo "warning: sha256sum and shasum not found, skipping checksum verification" >&2
+		return 0
+	fi
+	if [ "$sum" != "$SHA256" ]; then
+		echo "error: checksum mismatch, the installer is corrupted" >&2
+		exit 1
+	fi
+}
+
+case "$ACTION" in
+	list)
+		payload | tar -tzvf -
+		exit 0
+		;;
+	check)
+		verify
+		echo "checksum OK"
+		exit 0
+		;;
+esac
+
+verify
+{{- if .License }}
+
+if [ "$ACCEPT" != 1 ]; then
+	cat <<'{{ .EOF }}'
+{{ .License }}
+{{ .EOF }}
+	if [ ! -t 0 ]; then
+		echo "error: the license must be accepted, use --accept to do so non-interactively" >&2
+		exit 1
+	fi
+	printf "Do you accept the license? [y/N] "
+	read -r answer
+	case "$answer" in
+		y|Y|yes|YES|Yes) ;;
+		*) echo "license not accepted, aborting" >&2; exit 1 ;;
+	esac
+fi
+{{- end }}
+
+echo "Installing $LABEL into $PREFIX"
+mkdir -p "$PREFIX"
+payload | tar -xzf - -C "$PREFIX"
+{{- if .PostInstall }}
+
+echo "Running post install script"
+(cd "$PREFIX" && PREFIX="$PREFIX" sh -s) <<'{{ .EOF }}'
+{{ .PostInstall }}
+{{ .EOF }}
+{{- end }}
+
+echo "Done"
+exit 0
+`))
+
+// quote single-quotes s for the shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
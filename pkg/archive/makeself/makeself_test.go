@@ -0,0 +1,152 @@
+package makeself
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeselfFile(t *testing.T) {
+	path := create(t, Options{
+		Label:       "foo 1.0.0",
+		License:     "MIT License\n\nDo whatever you want.",
+		PostInstall: "echo \"installed into $PREFIX\" > post.txt",
+	})
+
+	bts, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(bts, []byte("#!/bin/sh\n")))
+	require.Contains(t, string(bts), "LABEL='foo 1.0.0'")
+	require.Contains(t, string(bts), "PREFIX='/usr/local'")
+
+	requireShell(t)
+
+	out := run(t, path, "--help")
+	require.Contains(t, out, "--accept")
+	require.Contains(t, out, "default: /usr/local")
+
+	out = run(t, path, "--check")
+	require.Contains(t, out, "checksum OK")
+
+	out = run(t, path, "--list")
+	require.Contains(t, out, "foo.txt")
+	require.Contains(t, out, "sub1/bar.txt")
+
+	prefix := filepath.Join(t.TempDir(), "install")
+	out = run(t, path, "--accept", "--prefix", prefix)
+	require.NotContains(t, out, "MIT License")
+	require.Contains(t, out, "Installing foo 1.0.0 into "+prefix)
+
+	bts, err = os.ReadFile(filepath.Join(prefix, "foo.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "foo\n", string(bts))
+	bts, err = os.ReadFile(filepath.Join(prefix, "sub1", "bar.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "bar\n", string(bts))
+	info, err := os.Stat(filepath.Join(prefix, "sub1", "executable"))
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&0o111)
+	bts, err = os.ReadFile(filepath.Join(prefix, "post.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "installed into "+prefix+"\n", string(bts))
+}
+
+func TestMakeselfLicenseNotAccepted(t *testing.T) {
+	path := create(t, Options{License: "Some license"})
+	requireShell(t)
+
+	prefix := filepath.Join(t.TempDir(), "install")
+	cmd := exec.Command("sh", path, "--prefix="+prefix)
+	cmd.Stdin = strings.NewReader("")
+	out, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	require.Contains(t, string(out), "Some license")
+	require.Contains(t, string(out), "the license must be accepted")
+	require.NoDirExists(t, prefix)
+}
+
+func TestMakeselfNoLicense(t *testing.T) {
+	path := create(t, Options{Prefix: "/opt/it's here"})
+	bts, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(bts), `PREFIX='/opt/it'"'"'s here'`)
+	require.NotContains(t, string(bts), "Do you accept the license")
+	requireShell(t)
+
+	prefix := filepath.Join(t.TempDir(), "install")
+	run(t, path, "--prefix", prefix)
+	require.FileExists(t, filepath.Join(prefix, "foo.txt"))
+	require.NoFileExists(t, filepath.Join(prefix, "post.txt"))
+}
+
+func TestMakeselfCorrupted(t *testing.T) {
+	path := create(t, Options{})
+	requireShell(t)
+
+	bts, err := os.ReadFile(path)
+	require.NoError(t, err)
+	bts[len(bts)-10] ^= 0xff
+	require.NoError(t, os.WriteFile(path, bts, 0o755))
+
+	prefix := filepath.Join(t.TempDir(), "install")
+	out, err := exec.Command("sh", path, "--prefix", prefix).CombinedOutput()
+	require.Error(t, err)
+	require.Contains(t, string(out), "checksum mismatch")
+	require.NoDirExists(t, prefix)
+}
+
+func TestMakeselfInvalidScript(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.run"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, Options{PostInstall: "cat <<" + heredocEOF})
+	require.NoError(t, err)
+	require.EqualError(t, archive.Close(), `makeself: license and post install script can't contain "GORELEASER_MAKESELF_EOF"`)
+	require.NoError(t, archive.Close())
+}
+
+func create(tb testing.TB, opts Options) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "test.run")
+	f, err := os.Create(path)
+	require.NoError(tb, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, opts)
+	require.NoError(tb, err)
+
+	require.Error(tb, archive.Add(config.File{
+		Source:      "../testdata/nope.txt",
+		Destination: "nope.txt",
+	}))
+	for _, name := range []string{"foo.txt", "sub1", "sub1/bar.txt", "sub1/executable"} {
+		require.NoError(tb, archive.Add(config.File{
+			Source:      "../testdata/" + name,
+			Destination: name,
+		}))
+	}
+	require.NoError(tb, archive.Close())
+	require.NoError(tb, archive.Close())
+	require.NoError(tb, f.Close())
+	return path
+}
+
+func requireShell(tb testing.TB) {
+	tb.Helper()
+	if runtime.GOOS == "windows" {
+		tb.Skip("self-extracting archives need a POSIX shell")
+	}
+}
+
+func run(tb testing.TB, path string, args ...string) string {
+	tb.Helper()
+	out, err := exec.Command("sh", append([]string{path}, args...)...).CombinedOutput()
+	require.NoError(tb, err, string(out))
+	return string(out)
+}
@@ -0,0 +1,88 @@
+package archive
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// reproducible is an Archive that produces the same archive for the same
+// files, regardless of the order they were added in and of the host they
+// were created on.
+//
+// Files are only added to the underlying archive on Close, sorted by
+// destination, with normalized ownership, permissions and timestamps.
+type reproducible struct {
+	a      Archive
+	mtime  time.Time
+	files  *[]config.File
+	closed *bool
+}
+
+func newReproducible(a Archive, mtime time.Time) reproducible {
+	if mtime.IsZero() {
+		mtime = time.Unix(0, 0)
+	}
+	return reproducible{
+		a:      a,
+		mtime:  mtime.UTC(),
+		files:  &[]config.File{},
+		closed: new(bool),
+	}
+}
+
+// Add file to the archive.
+func (r reproducible) Add(f config.File) error {
+	info, err := os.Lstat(f.Source) // #nosec
+	if err != nil {
+		return err
+	}
+	if f.Info.MTime.IsZero() {
+		f.Info.MTime = r.mtime
+	}
+	if f.Info.Mode == 0 {
+		f.Info.Mode = normalizedMode(info)
+	}
+	if f.Info.Owner == "" {
+		f.Info.Owner = "root"
+	}
+	if f.Info.Group == "" {
+		f.Info.Group = "root"
+	}
+	*r.files = append(*r.files, f)
+	return nil
+}
+
+// Close adds all the files to the underlying archive and closes it.
+func (r reproducible) Close() error {
+	if *r.closed {
+		return nil
+	}
+	*r.closed = true
+	files := *r.files
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Destination < files[j].Destination
+	})
+	for _, f := range files {
+		if err := r.a.Add(f); err != nil {
+			_ = r.a.Close()
+			return err
+		}
+	}
+	return r.a.Close()
+}
+
+// normalizedMode returns 0755 for directories and executables and 0644 for
+// everything else. Symlinks are kept as is.
+func normalizedMode(info os.FileInfo) os.FileMode {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return 0
+	case info.IsDir(), info.Mode()&0o111 != 0:
+		return 0o755
+	default:
+		return 0o644
+	}
+}
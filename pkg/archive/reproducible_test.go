@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReproducible(t *testing.T) {
+	mtime := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	for _, format := range []string{"tar.gz", "zip", "tar.xz", "tar", "tar.zst", "tar.bz2"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			first := createReproducible(t, format, mtime, time.Now(), 0o600, false)
+			second := createReproducible(t, format, mtime, time.Now().Add(-time.Hour), 0o640, true)
+			require.Equal(t, first, second)
+		})
+	}
+
+	for _, format := range []string{"gz", "zst"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			first := createReproducibleSingle(t, format, mtime, time.Now(), 0o600)
+			second := createReproducibleSingle(t, format, mtime, time.Now().Add(-time.Hour), 0o640)
+			require.Equal(t, first, second)
+		})
+	}
+}
+
+func TestReproducibleHeaders(t *testing.T) {
+	mtime := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	bts := createReproducible(t, "tar", mtime, time.Now(), 0o600, false)
+
+	type entry struct {
+		mode  int64
+		owner string
+		group string
+		uid   int
+		mtime time.Time
+	}
+	entries := map[string]entry{}
+	var names []string
+	r := tar.NewReader(bytes.NewReader(bts))
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, h.Name)
+		entries[h.Name] = entry{h.Mode, h.Uname, h.Gname, h.Uid, h.ModTime.UTC()}
+	}
+
+	require.Equal(t, []string{"a/b.txt", "a/dir", "a/run.sh", "z.txt"}, names)
+	require.Equal(t, entry{0o644, "root", "root", 0, mtime}, entries["a/b.txt"])
+	require.Equal(t, entry{0o755, "root", "root", 0, mtime}, entries["a/dir"])
+	require.Equal(t, entry{0o755, "root", "root", 0, mtime}, entries["a/run.sh"])
+	require.Equal(t, entry{0o600, "me", "root", 0, time.Unix(10, 0).UTC()}, entries["z.txt"])
+}
+
+func TestReproducibleDefaultMTime(t *testing.T) {
+	bts := createReproducible(t, "tar", time.Time{}, time.Now(), 0o600, false)
+	r := tar.NewReader(bytes.NewReader(bts))
+	h, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(0, 0).UTC(), h.ModTime.UTC())
+}
+
+func TestReproducibleInvalidFile(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "a.tar.gz"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, file.Close()) })
+	archive, err := NewWithOptions(file, Options{Reproducible: true})
+	require.NoError(t, err)
+	require.Error(t, archive.Add(config.File{
+		Source:      "testdata/nope.txt",
+		Destination: "nope.txt",
+	}))
+	require.NoError(t, archive.Close())
+	require.NoError(t, archive.Close())
+}
+
+// createReproducible creates the same set of files with the given file mode
+// and mtime, archives them and returns the archive contents.
+func createReproducible(tb testing.TB, format string, mtime, fileTime time.Time, mode os.FileMode, reverse bool) []byte {
+	tb.Helper()
+	folder := tb.TempDir()
+	require.NoError(tb, os.Mkdir(filepath.Join(folder, "dir"), 0o700))
+	for name, content := range map[string]string{
+		"b.txt":  "b",
+		"run.sh": "#!/bin/sh",
+		"z.txt":  "z",
+	} {
+		path := filepath.Join(folder, name)
+		m := mode
+		if name == "run.sh" {
+			m |= 0o100
+		}
+		require.NoError(tb, os.WriteFile(path, []byte(content), m))
+		require.NoError(tb, os.Chmod(path, m))
+		require.NoError(tb, os.Chtimes(path, fileTime, fileTime))
+	}
+	require.NoError(tb, os.Chtimes(filepath.Join(folder, "dir"), fileTime, fileTime))
+
+	files := []config.File{
+		{Source: filepath.Join(folder, "b.txt"), Destination: "a/b.txt"},
+		{Source: filepath.Join(folder, "dir"), Destination: "a/dir"},
+		{Source: filepath.Join(folder, "run.sh"), Destination: "a/run.sh"},
+		{Source: filepath.Join(folder, "z.txt"), Destination: "z.txt", Info: config.FileInfo{
+			Owner: "me",
+			Mode:  0o600,
+			MTime: time.Unix(10, 0),
+		}},
+	}
+	if reverse {
+		for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+			files[i], files[j] = files[j], files[i]
+		}
+	}
+
+	return writeReproducible(tb, format, mtime, files)
+}
+
+func createReproducibleSingle(tb testing.TB, format string, mtime, fileTime time.Time, mode os.FileMode) []byte {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "bin")
+	require.NoError(tb, os.WriteFile(path, []byte("binary"), mode))
+	require.NoError(tb, os.Chmod(path, mode))
+	require.NoError(tb, os.Chtimes(path, fileTime, fileTime))
+	return writeReproducible(tb, format, mtime, []config.File{{Source: path, Destination: "bin"}})
+}
+
+func writeReproducible(tb testing.TB, format string, mtime time.Time, files []config.File) []byte {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "archive."+format)
+	file, err := os.Create(path)
+	require.NoError(tb, err)
+	archive, err := NewWithOptions(file, Options{Reproducible: true, MTime: mtime})
+	require.NoError(tb, err)
+	for _, f := range files {
+		require.NoError(tb, archive.Add(f))
+	}
+	require.NoError(tb, archive.Close())
+	require.NoError(tb, file.Close())
+	bts, err := os.ReadFile(path)
+	require.NoError(tb, err)
+	return bts
+}
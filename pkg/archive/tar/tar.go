@@ -3,9 +3,11 @@ package tar
 
 import (
 	"archive/tar"
+	"fmt"
 	"io"
 	"os"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
@@ -72,3 +74,52 @@ func (a Archive) Add(f config.File) error {
 	_, err = io.Copy(a.tw, file)
 	return err
 }
+
+// List the entries of the tar archive read from r.
+func List(r io.Reader) ([]entry.Entry, error) {
+	var entries []entry.Entry
+	err := walk(r, func(_ *tar.Header, e entry.Entry, _ io.Reader) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// Extract the tar archive read from r into dir.
+func Extract(r io.Reader, dir string) error {
+	return walk(r, func(header *tar.Header, e entry.Entry, r io.Reader) error {
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeReg, tar.TypeRegA, tar.TypeSymlink: // nolint: staticcheck
+			return entry.Extract(dir, e, r)
+		default:
+			return fmt.Errorf("%s: unsupported tar entry type %q", header.Name, header.Typeflag)
+		}
+	})
+}
+
+func walk(r io.Reader, fn func(header *tar.Header, e entry.Entry, r io.Reader) error) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		if err := fn(header, entry.Entry{
+			Name:     header.Name,
+			Mode:     header.FileInfo().Mode(),
+			Size:     header.Size,
+			MTime:    header.ModTime,
+			Owner:    header.Uname,
+			Group:    header.Gname,
+			Linkname: header.Linkname,
+		}, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -163,3 +163,86 @@ func TestTarInvalidLink(t *testing.T) {
 		Destination: "badlink.txt",
 	}), "open ../testdata/badlink.txt: no such file or directory")
 }
+
+func TestTarListExtract(t *testing.T) {
+	tmp := t.TempDir()
+	mtime := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	f, err := os.Create(filepath.Join(tmp, "test.tar"))
+	require.NoError(t, err)
+	archive := New(f)
+	for _, file := range []config.File{
+		{Source: "../testdata/foo.txt", Destination: "foo.txt"},
+		{Source: "../testdata/sub1", Destination: "sub1"},
+		{Source: "../testdata/sub1/executable", Destination: "sub1/executable"},
+		{Source: "../testdata/link.txt", Destination: "link.txt"},
+	} {
+		file.Info = config.FileInfo{Owner: "root", Group: "wheel", MTime: mtime}
+		require.NoError(t, archive.Add(file))
+	}
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	entries, err := List(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Len(t, entries, 4)
+	require.Equal(t, "foo.txt", entries[0].Name)
+	require.Equal(t, int64(4), entries[0].Size)
+	require.Equal(t, "root", entries[0].Owner)
+	require.Equal(t, "wheel", entries[0].Group)
+	require.Equal(t, mtime, entries[0].MTime.UTC())
+	require.True(t, entries[1].Mode.IsDir())
+	require.NotZero(t, entries[2].Mode&0o111)
+	require.NotZero(t, entries[3].Mode&os.ModeSymlink)
+	require.Equal(t, "regular.txt", entries[3].Linkname)
+
+	dir := filepath.Join(tmp, "out")
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.NoError(t, Extract(f, dir))
+
+	bts, err := os.ReadFile(filepath.Join(dir, "foo.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "foo\n", string(bts))
+	info, err := os.Stat(filepath.Join(dir, "sub1", "executable"))
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&0o111)
+	link, err := os.Readlink(filepath.Join(dir, "link.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "regular.txt", link)
+}
+
+func TestTarExtractPathTraversal(t *testing.T) {
+	for name, header := range map[string]*tar.Header{
+		"parent":   {Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4},
+		"absolute": {Name: "/tmp/evil.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4},
+		"symlink":  {Name: "evil", Typeflag: tar.TypeSymlink, Mode: 0o777, Linkname: "../../"},
+		"hardlink": {Name: "evil", Typeflag: tar.TypeLink, Mode: 0o644, Linkname: "/etc/passwd"},
+	} {
+		header := header
+		t.Run(name, func(t *testing.T) {
+			tmp := t.TempDir()
+			path := filepath.Join(tmp, "evil.tar")
+			f, err := os.Create(path)
+			require.NoError(t, err)
+			tw := tar.NewWriter(f)
+			require.NoError(t, tw.WriteHeader(header))
+			if header.Size > 0 {
+				_, err = tw.Write([]byte("evil"))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.Close())
+			require.NoError(t, f.Close())
+
+			f, err = os.Open(path)
+			require.NoError(t, err)
+			defer f.Close() // nolint: errcheck
+			dir := filepath.Join(tmp, "out")
+			require.Error(t, Extract(f, dir))
+			require.NoFileExists(t, filepath.Join(tmp, "evil.txt"))
+		})
+	}
+}
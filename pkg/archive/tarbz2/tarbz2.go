@@ -0,0 +1,67 @@
+// Package tarbz2 implements the Archive interface providing tar.bz2 archiving
+// and compression.
+package tarbz2
+
+import (
+	"io"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Archive as tar.bz2.
+type Archive struct {
+	bw *bzip2.Writer
+	tw *tar.Archive
+}
+
+// New tar.bz2 archive.
+//
+// The level goes from 1 (fastest) to 9 (best compression), 0 means the
+// default level.
+func New(target io.Writer, level int) (Archive, error) {
+	bw, err := bzip2.NewWriter(target, &bzip2.WriterConfig{Level: level})
+	if err != nil {
+		return Archive{}, err
+	}
+	tw := tar.New(bw)
+	return Archive{
+		bw: bw,
+		tw: &tw,
+	}, nil
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.bw.Close()
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
+
+// List the entries of the tar.bz2 archive read from r.
+func List(r io.Reader) ([]entry.Entry, error) {
+	dr, err := bzip2.NewReader(r, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.Close()
+	return tar.List(dr)
+}
+
+// Extract the tar.bz2 archive read from r into dir.
+func Extract(r io.Reader, dir string) error {
+	dr, err := bzip2.NewReader(r, nil)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+	return tar.Extract(dr, dir)
+}
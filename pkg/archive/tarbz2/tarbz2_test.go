@@ -0,0 +1,158 @@
+package tarbz2
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarBz2File(t *testing.T) {
+	tmp := t.TempDir()
+	f, err := os.Create(filepath.Join(tmp, "test.tar.bz2"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, 0)
+	require.NoError(t, err)
+	defer archive.Close() // nolint: errcheck
+
+	require.Error(t, archive.Add(config.File{
+		Source:      "../testdata/nope.txt",
+		Destination: "nope.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1",
+		Destination: "sub1",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/bar.txt",
+		Destination: "sub1/bar.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/executable",
+		Destination: "sub1/executable",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/sub2",
+		Destination: "sub1/sub2",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/sub2/subfoo.txt",
+		Destination: "sub1/sub2/subfoo.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/regular.txt",
+		Destination: "regular.txt",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/link.txt",
+		Destination: "link.txt",
+	}))
+
+	require.NoError(t, archive.Close())
+	require.Error(t, archive.Add(config.File{
+		Source:      "tar.go",
+		Destination: "tar.go",
+	}))
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+	require.Truef(t, info.Size() < 500, "archived file should be smaller than %d", info.Size())
+
+	bz2f := bzip2.NewReader(f)
+
+	var paths []string
+	r := tar.NewReader(bz2f)
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		paths = append(paths, next.Name)
+		t.Logf("%s: %v", next.Name, next.FileInfo().Mode())
+		if next.Name == "sub1/executable" {
+			ex := next.FileInfo().Mode() | 0o111
+			require.Equal(t, next.FileInfo().Mode().String(), ex.String())
+		}
+		if next.Name == "link.txt" {
+			require.Equal(t, next.Linkname, "regular.txt")
+		}
+	}
+	require.Equal(t, []string{
+		"foo.txt",
+		"sub1",
+		"sub1/bar.txt",
+		"sub1/executable",
+		"sub1/sub2",
+		"sub1/sub2/subfoo.txt",
+		"regular.txt",
+		"link.txt",
+	}, paths)
+}
+
+func TestTarBz2FileInfo(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.tar.bz2"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, 0)
+	require.NoError(t, err)
+	defer archive.Close() // nolint: errcheck
+
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "nope.txt",
+		Info: config.FileInfo{
+			Mode:  0o755,
+			Owner: "carlos",
+			Group: "root",
+			MTime: now,
+		},
+	}))
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	bz2f := bzip2.NewReader(f)
+
+	var found int
+	r := tar.NewReader(bz2f)
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		found++
+		require.Equal(t, "nope.txt", next.Name)
+		require.Equal(t, now, next.ModTime)
+		require.Equal(t, fs.FileMode(0o755), next.FileInfo().Mode())
+		require.Equal(t, "carlos", next.Uname)
+		require.Equal(t, 0, next.Uid)
+		require.Equal(t, "root", next.Gname)
+		require.Equal(t, 0, next.Gid)
+	}
+	require.Equal(t, 1, found)
+}
@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"io"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
 	"github.com/goreleaser/goreleaser/pkg/archive/tar"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
@@ -39,3 +40,23 @@ func (a Archive) Close() error {
 func (a Archive) Add(f config.File) error {
 	return a.tw.Add(f)
 }
+
+// List the entries of the tar.gz archive read from r.
+func List(r io.Reader) ([]entry.Entry, error) {
+	dr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.Close()
+	return tar.List(dr)
+}
+
+// Extract the tar.gz archive read from r into dir.
+func Extract(r io.Reader, dir string) error {
+	dr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+	return tar.Extract(dr, dir)
+}
@@ -5,6 +5,7 @@ package tarxz
 import (
 	"io"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
 	"github.com/goreleaser/goreleaser/pkg/archive/tar"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/ulikunitz/xz"
@@ -38,3 +39,21 @@ func (a Archive) Close() error {
 func (a Archive) Add(f config.File) error {
 	return a.tw.Add(f)
 }
+
+// List the entries of the tar.xz archive read from r.
+func List(r io.Reader) ([]entry.Entry, error) {
+	dr, err := xz.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return tar.List(dr)
+}
+
+// Extract the tar.xz archive read from r into dir.
+func Extract(r io.Reader, dir string) error {
+	dr, err := xz.NewReader(r)
+	if err != nil {
+		return err
+	}
+	return tar.Extract(dr, dir)
+}
@@ -0,0 +1,80 @@
+// Package tarzst implements the Archive interface providing tar.zst archiving
+// and compression.
+package tarzst
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archive as tar.zst.
+type Archive struct {
+	zw *zstd.Encoder
+	tw *tar.Archive
+}
+
+// New tar.zst archive.
+//
+// The level goes from 1 (fastest) to 22 (best compression), 0 means the
+// default level.
+func New(target io.Writer, level int) (Archive, error) {
+	zw, err := NewWriter(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	tw := tar.New(zw)
+	return Archive{
+		zw: zw,
+		tw: &tw,
+	}, nil
+}
+
+// NewWriter creates a zstd writer with the given level.
+func NewWriter(target io.Writer, level int) (*zstd.Encoder, error) {
+	opts := []zstd.EOption{}
+	if level != 0 {
+		if level < 1 || level > 22 {
+			return nil, fmt.Errorf("zstd: invalid compression level: %d", level)
+		}
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	return zstd.NewWriter(target, opts...)
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
+
+// List the entries of the tar.zst archive read from r.
+func List(r io.Reader) ([]entry.Entry, error) {
+	dr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.Close()
+	return tar.List(dr)
+}
+
+// Extract the tar.zst archive read from r into dir.
+func Extract(r io.Reader, dir string) error {
+	dr, err := zstd.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+	return tar.Extract(dr, dir)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/entry"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
@@ -67,3 +68,56 @@ func (a Archive) Add(f config.File) error {
 }
 
 // TODO: test fileinfo stuff
+
+// List the entries of the zip archive read from r.
+func List(r io.ReaderAt, size int64) ([]entry.Entry, error) {
+	var entries []entry.Entry
+	err := walk(r, size, func(e entry.Entry, _ io.Reader) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// Extract the zip archive read from r into dir.
+func Extract(r io.ReaderAt, size int64, dir string) error {
+	return walk(r, size, func(e entry.Entry, r io.Reader) error {
+		return entry.Extract(dir, e, r)
+	})
+}
+
+func walk(r io.ReaderAt, size int64, fn func(e entry.Entry, r io.Reader) error) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if err := walkFile(f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkFile(f *zip.File, fn func(e entry.Entry, r io.Reader) error) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	e := entry.Entry{
+		Name:  f.Name,
+		Mode:  f.Mode(),
+		Size:  int64(f.UncompressedSize64),
+		MTime: f.Modified,
+	}
+	if e.Mode&os.ModeSymlink != 0 {
+		// zip stores the symlink target as the file contents
+		target, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		e.Linkname = string(target)
+	}
+	return fn(e, rc)
+}
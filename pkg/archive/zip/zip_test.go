@@ -136,3 +136,87 @@ func TestZipFileInfo(t *testing.T) {
 		require.Equal(t, fs.FileMode(0o755), next.FileInfo().Mode())
 	}
 }
+
+func TestZipListExtract(t *testing.T) {
+	tmp := t.TempDir()
+	f, err := os.Create(filepath.Join(tmp, "test.zip"))
+	require.NoError(t, err)
+	archive := New(f)
+	for _, file := range []config.File{
+		{Source: "../testdata/foo.txt", Destination: "foo.txt"},
+		{Source: "../testdata/sub1/executable", Destination: "sub1/executable"},
+	} {
+		require.NoError(t, archive.Add(file))
+	}
+	require.NoError(t, archive.Close())
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	entries, err := List(f, info.Size())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "foo.txt", entries[0].Name)
+	require.Equal(t, int64(4), entries[0].Size)
+	require.NotZero(t, entries[1].Mode&0o111)
+
+	dir := filepath.Join(tmp, "out")
+	require.NoError(t, Extract(f, info.Size(), dir))
+	require.NoError(t, f.Close())
+
+	bts, err := os.ReadFile(filepath.Join(dir, "foo.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "foo\n", string(bts))
+	stat, err := os.Stat(filepath.Join(dir, "sub1", "executable"))
+	require.NoError(t, err)
+	require.NotZero(t, stat.Mode()&0o111)
+}
+
+func TestZipListExtractSymlink(t *testing.T) {
+	tmp := t.TempDir()
+	f, err := os.Create(filepath.Join(tmp, "link.zip"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	zw := zip.NewWriter(f)
+	header := &zip.FileHeader{Name: "link.txt"}
+	header.SetMode(os.ModeSymlink | 0o777)
+	w, err := zw.CreateHeader(header)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("regular.txt"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	entries, err := List(f, info.Size())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NotZero(t, entries[0].Mode&os.ModeSymlink)
+	require.Equal(t, "regular.txt", entries[0].Linkname)
+
+	dir := filepath.Join(tmp, "out")
+	require.NoError(t, Extract(f, info.Size(), dir))
+	link, err := os.Readlink(filepath.Join(dir, "link.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "regular.txt", link)
+}
+
+func TestZipExtractPathTraversal(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "evil.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("../evil.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("evil"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	info, err := f.Stat()
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	err = Extract(f, info.Size(), filepath.Join(tmp, "out"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "illegal path outside of the extraction directory")
+	require.NoFileExists(t, filepath.Join(tmp, "evil.txt"))
+}
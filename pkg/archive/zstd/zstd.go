@@ -0,0 +1,61 @@
+// Package zstd implements the Archive interface providing zst archiving
+// and compression.
+package zstd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tarzst"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archive as zst.
+type Archive struct {
+	zw   *zstd.Encoder
+	name *string
+}
+
+// New zst archive.
+//
+// The level goes from 1 (fastest) to 22 (best compression), 0 means the
+// default level.
+func New(target io.Writer, level int) (Archive, error) {
+	zw, err := tarzst.NewWriter(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{
+		zw:   zw,
+		name: new(string),
+	}, nil
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	return a.zw.Close()
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	if *a.name != "" {
+		return fmt.Errorf("zstd: failed to add %s, only one file can be archived in zst format", f.Destination)
+	}
+	file, err := os.Open(f.Source) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	*a.name = f.Destination
+	_, err = io.Copy(a.zw, file)
+	return err
+}
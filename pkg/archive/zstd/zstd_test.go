@@ -0,0 +1,74 @@
+package zstd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZstFile(t *testing.T) {
+	tmp := t.TempDir()
+	f, err := os.Create(filepath.Join(tmp, "test.zst"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, 0)
+	require.NoError(t, err)
+	defer archive.Close() // nolint: errcheck
+
+	require.NoError(t, archive.Add(config.File{
+		Destination: "sub1",
+		Source:      "../testdata/sub1",
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Destination: "sub1/sub2/subfoo.txt",
+		Source:      "../testdata/sub1/sub2/subfoo.txt",
+	}))
+	require.EqualError(t, archive.Add(config.File{
+		Destination: "foo.txt",
+		Source:      "../testdata/foo.txt",
+	}), "zstd: failed to add foo.txt, only one file can be archived in zst format")
+	require.Error(t, archive.Add(config.File{
+		Destination: "nope.txt",
+		Source:      "../testdata/nope.txt",
+	}))
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+	require.Truef(t, info.Size() < 500, "archived file should be smaller than %d", info.Size())
+
+	zstdf, err := zstd.NewReader(f)
+	require.NoError(t, err)
+	defer zstdf.Close()
+
+	bts, err := io.ReadAll(zstdf)
+	require.NoError(t, err)
+	require.Equal(t, "sub\n", string(bts))
+}
+
+func TestZstFileLevel(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.zst"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	_, err = New(f, 23)
+	require.EqualError(t, err, "zstd: invalid compression level: 23")
+
+	archive, err := New(f, 22)
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{
+		Destination: "foo.txt",
+		Source:      "../testdata/foo.txt",
+	}))
+	require.NoError(t, archive.Close())
+}
@@ -61,6 +61,34 @@ type RepoRef struct {
 	Name   string `yaml:"name,omitempty"`
 	Token  string `yaml:"token,omitempty"`
 	Branch string `yaml:"branch,omitempty"`
+
+	PullRequest PullRequest `yaml:"pull_request,omitempty"`
+	Git         GitRepoRef  `yaml:"git,omitempty"`
+}
+
+// GitRepoRef is a repository in any git server, which files are pushed to
+// with git itself instead of through the API of GitHub, GitLab or Gitea.
+type GitRepoRef struct {
+	URL        string `yaml:"url,omitempty"`
+	SSHCommand string `yaml:"ssh_command,omitempty"`
+	PrivateKey string `yaml:"private_key,omitempty"`
+}
+
+// PullRequest configures committing to a new branch and opening a pull
+// request against the branch of a RepoRef, instead of pushing to it directly.
+type PullRequest struct {
+	Enabled bool            `yaml:"enabled,omitempty"`
+	Branch  string          `yaml:"branch,omitempty"`
+	Fork    PullRequestFork `yaml:"fork,omitempty"`
+	Title   string          `yaml:"title,omitempty"`
+	Body    string          `yaml:"body,omitempty"`
+	Draft   bool            `yaml:"draft,omitempty"`
+}
+
+// PullRequestFork is the fork the pull request branch is pushed to.
+type PullRequestFork struct {
+	Owner string `yaml:"owner,omitempty"`
+	Name  string `yaml:"name,omitempty"`
 }
 
 // HomebrewDependency represents Homebrew dependency.
@@ -147,6 +175,42 @@ type Homebrew struct {
 	Goarm                 string               `yaml:"goarm,omitempty"`
 }
 
+// HomebrewCask contains the homebrew cask section.
+type HomebrewCask struct {
+	Name                  string                `yaml:"name,omitempty"`
+	Tap                   RepoRef               `yaml:"tap,omitempty"`
+	CommitAuthor          CommitAuthor          `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string                `yaml:"commit_msg_template,omitempty"`
+	Folder                string                `yaml:"folder,omitempty"`
+	Caveats               string                `yaml:"caveats,omitempty"`
+	Description           string                `yaml:"description,omitempty"`
+	Homepage              string                `yaml:"homepage,omitempty"`
+	SkipUpload            string                `yaml:"skip_upload,omitempty"`
+	URLTemplate           string                `yaml:"url_template,omitempty"`
+	IDs                   []string              `yaml:"ids,omitempty"`
+	App                   string                `yaml:"app,omitempty"`
+	Binaries              []string              `yaml:"binaries,omitempty"`
+	DependsOn             HomebrewCaskDependsOn `yaml:"depends_on,omitempty"`
+	Uninstall             HomebrewCaskUninstall `yaml:"uninstall,omitempty"`
+	Zap                   HomebrewCaskZap       `yaml:"zap,omitempty"`
+}
+
+// HomebrewCaskDependsOn configures the depends_on stanza of a cask.
+type HomebrewCaskDependsOn struct {
+	MacOS string `yaml:"macos,omitempty"`
+}
+
+// HomebrewCaskUninstall configures the uninstall stanza of a cask.
+type HomebrewCaskUninstall struct {
+	PkgUtil []string `yaml:"pkgutil,omitempty"`
+	Delete  []string `yaml:"delete,omitempty"`
+}
+
+// HomebrewCaskZap configures the zap stanza of a cask.
+type HomebrewCaskZap struct {
+	Trash []string `yaml:"trash,omitempty"`
+}
+
 // Krew contains the krew section.
 type Krew struct {
 	IDs                   []string     `yaml:"ids,omitempty"`
@@ -165,25 +229,161 @@ type Krew struct {
 
 // Scoop contains the scoop.sh section.
 type Scoop struct {
+	Name                  string          `yaml:"name,omitempty"`
+	IDs                   []string        `yaml:"ids,omitempty"`
+	Bucket                RepoRef         `yaml:"bucket,omitempty"`
+	Folder                string          `yaml:"folder,omitempty"`
+	CommitAuthor          CommitAuthor    `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string          `yaml:"commit_msg_template,omitempty"`
+	Homepage              string          `yaml:"homepage,omitempty"`
+	Description           string          `yaml:"description,omitempty"`
+	License               string          `yaml:"license,omitempty"`
+	URLTemplate           string          `yaml:"url_template,omitempty"`
+	Persist               []string        `yaml:"persist,omitempty"`
+	SkipUpload            string          `yaml:"skip_upload,omitempty"`
+	PreInstall            []string        `yaml:"pre_install,omitempty"`
+	PostInstall           []string        `yaml:"post_install,omitempty"`
+	Depends               []string        `yaml:"depends,omitempty"`
+	Shortcuts             [][]string      `yaml:"shortcuts,omitempty"`
+	BinAliases            []ScoopBinAlias `yaml:"bin_aliases,omitempty"`
+	CheckVer              ScoopCheckVer   `yaml:"checkver,omitempty"`
+	AutoUpdate            bool            `yaml:"autoupdate,omitempty"`
+}
+
+// ScoopBinAlias is a shim with a different name for a binary of a scoop
+// manifest.
+type ScoopBinAlias struct {
+	Binary string `yaml:"binary,omitempty"`
+	Alias  string `yaml:"alias,omitempty"`
+	Args   string `yaml:"args,omitempty"`
+}
+
+// ScoopCheckVer is how scoop checks for new versions of an app.
+type ScoopCheckVer struct {
+	GitHub   string `yaml:"github,omitempty"`
+	URL      string `yaml:"url,omitempty"`
+	Regex    string `yaml:"regex,omitempty"`
+	JSONPath string `yaml:"jsonpath,omitempty"`
+}
+
+// AUR contains the AUR section.
+type AUR struct {
 	Name                  string       `yaml:"name,omitempty"`
-	Bucket                RepoRef      `yaml:"bucket,omitempty"`
-	Folder                string       `yaml:"folder,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
 	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
 	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	Description           string       `yaml:"description,omitempty"`
 	Homepage              string       `yaml:"homepage,omitempty"`
+	License               string       `yaml:"license,omitempty"`
+	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	URLTemplate           string       `yaml:"url_template,omitempty"`
+	Maintainers           []string     `yaml:"maintainers,omitempty"`
+	Contributors          []string     `yaml:"contributors,omitempty"`
+	Provides              []string     `yaml:"provides,omitempty"`
+	Conflicts             []string     `yaml:"conflicts,omitempty"`
+	Depends               []string     `yaml:"depends,omitempty"`
+	OptDepends            []string     `yaml:"optdepends,omitempty"`
+	Rel                   string       `yaml:"rel,omitempty"`
+	Package               string       `yaml:"package,omitempty"`
+	Git                   GitRepoRef   `yaml:"git,omitempty"`
+
+	GitURL        string `yaml:"git_url,omitempty"`         // deprecated: use Git.URL instead
+	GitSSHCommand string `yaml:"git_ssh_command,omitempty"` // deprecated: use Git.SSHCommand instead
+	PrivateKey    string `yaml:"private_key,omitempty"`     // deprecated: use Git.PrivateKey instead
+}
+
+// Nix contains the nix section.
+type Nix struct {
+	Name                  string       `yaml:"name,omitempty"`
+	Path                  string       `yaml:"path,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
+	SkipUpload            string       `yaml:"skip_upload,omitempty"`
+	URLTemplate           string       `yaml:"url_template,omitempty"`
+	Install               string       `yaml:"install,omitempty"`
+	Completions           []string     `yaml:"completions,omitempty"`
+	Manpages              []string     `yaml:"manpages,omitempty"`
+	PostInstall           string       `yaml:"post_install,omitempty"`
+	Dependencies          []string     `yaml:"dependencies,omitempty"`
 	Description           string       `yaml:"description,omitempty"`
+	Homepage              string       `yaml:"homepage,omitempty"`
+	License               string       `yaml:"license,omitempty"`
+}
+
+// Winget contains the winget section.
+type Winget struct {
+	Name                  string       `yaml:"name,omitempty"`
+	PackageIdentifier     string       `yaml:"package_identifier,omitempty"`
+	Publisher             string       `yaml:"publisher,omitempty"`
+	PublisherURL          string       `yaml:"publisher_url,omitempty"`
+	PublisherSupportURL   string       `yaml:"publisher_support_url,omitempty"`
+	Author                string       `yaml:"author,omitempty"`
+	Copyright             string       `yaml:"copyright,omitempty"`
 	License               string       `yaml:"license,omitempty"`
+	LicenseURL            string       `yaml:"license_url,omitempty"`
+	ShortDescription      string       `yaml:"short_description,omitempty"`
+	Description           string       `yaml:"description,omitempty"`
+	Homepage              string       `yaml:"homepage,omitempty"`
+	Tags                  []string     `yaml:"tags,omitempty"`
+	ReleaseNotes          string       `yaml:"release_notes,omitempty"`
+	ReleaseNotesURL       string       `yaml:"release_notes_url,omitempty"`
+	Repository            RepoRef      `yaml:"repository,omitempty"`
+	Path                  string       `yaml:"path,omitempty"`
+	CommitAuthor          CommitAuthor `yaml:"commit_author,omitempty"`
+	CommitMessageTemplate string       `yaml:"commit_msg_template,omitempty"`
+	IDs                   []string     `yaml:"ids,omitempty"`
 	URLTemplate           string       `yaml:"url_template,omitempty"`
-	Persist               []string     `yaml:"persist,omitempty"`
 	SkipUpload            string       `yaml:"skip_upload,omitempty"`
-	PreInstall            []string     `yaml:"pre_install,omitempty"`
-	PostInstall           []string     `yaml:"post_install,omitempty"`
+}
+
+// Chocolatey contains the chocolatey section.
+type Chocolatey struct {
+	Name                     string                 `yaml:"name,omitempty"`
+	IDs                      []string               `yaml:"ids,omitempty"`
+	PackageSourceURL         string                 `yaml:"package_source_url,omitempty"`
+	Owners                   string                 `yaml:"owners,omitempty"`
+	Title                    string                 `yaml:"title,omitempty"`
+	Authors                  string                 `yaml:"authors,omitempty"`
+	ProjectURL               string                 `yaml:"project_url,omitempty"`
+	URLTemplate              string                 `yaml:"url_template,omitempty"`
+	IconURL                  string                 `yaml:"icon_url,omitempty"`
+	Copyright                string                 `yaml:"copyright,omitempty"`
+	LicenseURL               string                 `yaml:"license_url,omitempty"`
+	RequireLicenseAcceptance bool                   `yaml:"require_license_acceptance,omitempty"`
+	ProjectSourceURL         string                 `yaml:"project_source_url,omitempty"`
+	DocsURL                  string                 `yaml:"docs_url,omitempty"`
+	BugTrackerURL            string                 `yaml:"bug_tracker_url,omitempty"`
+	Tags                     string                 `yaml:"tags,omitempty"`
+	Summary                  string                 `yaml:"summary,omitempty"`
+	Description              string                 `yaml:"description,omitempty"`
+	ReleaseNotes             string                 `yaml:"release_notes,omitempty"`
+	Dependencies             []ChocolateyDependency `yaml:"dependencies,omitempty"`
+	SourceRepo               string                 `yaml:"source_repo,omitempty"`
+	APIKey                   string                 `yaml:"api_key,omitempty"`
+	SkipPublish              bool                   `yaml:"skip_publish,omitempty"`
+}
+
+// ChocolateyDependency represents a chocolatey dependency.
+type ChocolateyDependency struct {
+	ID      string `yaml:"id,omitempty"`
+	Version string `yaml:"version,omitempty"`
 }
 
 // CommitAuthor is the author of a Git commit.
 type CommitAuthor struct {
-	Name  string `yaml:"name,omitempty"`
-	Email string `yaml:"email,omitempty"`
+	Name    string        `yaml:"name,omitempty"`
+	Email   string        `yaml:"email,omitempty"`
+	Signing CommitSigning `yaml:"signing,omitempty"`
+}
+
+// CommitSigning configures how the commits of a CommitAuthor are signed.
+type CommitSigning struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Key     string `yaml:"key,omitempty"`
+	Program string `yaml:"program,omitempty"`
+	Format  string `yaml:"format,omitempty" jsonschema:"enum=openpgp,enum=x509,enum=ssh,default=openpgp"`
 }
 
 // BuildHooks define actions to run before and/or after something.
@@ -264,30 +464,65 @@ func (a FlagArray) JSONSchemaType() *jsonschema.Type {
 
 // Build contains the build configuration section.
 type Build struct {
-	ID              string          `yaml:"id,omitempty"`
-	Goos            []string        `yaml:"goos,omitempty"`
-	Goarch          []string        `yaml:"goarch,omitempty"`
-	Goarm           []string        `yaml:"goarm,omitempty"`
-	Gomips          []string        `yaml:"gomips,omitempty"`
-	Targets         []string        `yaml:"targets,omitempty"`
-	Ignore          []IgnoredBuild  `yaml:"ignore,omitempty"`
-	Dir             string          `yaml:"dir,omitempty"`
-	Main            string          `yaml:"main,omitempty"`
-	Ldflags         StringArray     `yaml:"ldflags,omitempty"`
-	Tags            FlagArray       `yaml:"tags,omitempty"`
-	Flags           FlagArray       `yaml:"flags,omitempty"`
-	Binary          string          `yaml:"binary,omitempty"`
-	Hooks           BuildHookConfig `yaml:"hooks,omitempty"`
-	Env             []string        `yaml:"env,omitempty"`
-	Builder         string          `yaml:"builder,omitempty"`
-	Asmflags        StringArray     `yaml:"asmflags,omitempty"`
-	Gcflags         StringArray     `yaml:"gcflags,omitempty"`
-	ModTimestamp    string          `yaml:"mod_timestamp,omitempty"`
-	Skip            bool            `yaml:"skip,omitempty"`
-	GoBinary        string          `yaml:"gobinary,omitempty"`
-	NoUniqueDistDir bool            `yaml:"no_unique_dist_dir,omitempty"`
-	UnproxiedMain   string          `yaml:"-"` // used by gomod.proxy
-	UnproxiedDir    string          `yaml:"-"` // used by gomod.proxy
+	ID               string           `yaml:"id,omitempty"`
+	Goos             []string         `yaml:"goos,omitempty"`
+	Goarch           []string         `yaml:"goarch,omitempty"`
+	Goarm            []string         `yaml:"goarm,omitempty"`
+	Gomips           []string         `yaml:"gomips,omitempty"`
+	Targets          []string         `yaml:"targets,omitempty"`
+	Ignore           []IgnoredBuild   `yaml:"ignore,omitempty"`
+	Dir              string           `yaml:"dir,omitempty"`
+	Main             string           `yaml:"main,omitempty"`
+	Ldflags          StringArray      `yaml:"ldflags,omitempty"`
+	Tags             FlagArray        `yaml:"tags,omitempty"`
+	Flags            FlagArray        `yaml:"flags,omitempty"`
+	Binary           string           `yaml:"binary,omitempty"`
+	Hooks            BuildHookConfig  `yaml:"hooks,omitempty"`
+	Env              []string         `yaml:"env,omitempty"`
+	Builder          string           `yaml:"builder,omitempty"`
+	Asmflags         StringArray      `yaml:"asmflags,omitempty"`
+	Gcflags          StringArray      `yaml:"gcflags,omitempty"`
+	ModTimestamp     string           `yaml:"mod_timestamp,omitempty"`
+	Skip             bool             `yaml:"skip,omitempty"`
+	GoBinary         string           `yaml:"gobinary,omitempty"`
+	NoUniqueDistDir  bool             `yaml:"no_unique_dist_dir,omitempty"`
+	DebugSymbols     DebugSymbols     `yaml:"debug_symbols,omitempty"`
+	WindowsResources WindowsResources `yaml:"windows_resources,omitempty"`
+	SizeBudget       SizeBudget       `yaml:"size_budget,omitempty"`
+	UnproxiedMain    string           `yaml:"-"` // used by gomod.proxy
+	UnproxiedDir     string           `yaml:"-"` // used by gomod.proxy
+}
+
+// DebugSymbols configures the split of debug symbols out of the built
+// binaries.
+type DebugSymbols struct {
+	Enabled      bool   `yaml:"enabled,omitempty"`
+	ID           string `yaml:"id,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
+	Upload       bool   `yaml:"upload,omitempty"`
+}
+
+// SizeBudget limits the size of the built binaries, either absolutely or
+// compared to a previous release.
+type SizeBudget struct {
+	Previous     string  `yaml:"previous,omitempty"`
+	MaxSize      string  `yaml:"max_size,omitempty"`
+	Warn         float64 `yaml:"warn,omitempty"`
+	Fail         float64 `yaml:"fail,omitempty"`
+	ReleaseNotes bool    `yaml:"release_notes,omitempty"`
+}
+
+// WindowsResources are the resources embedded into windows binaries, such
+// as the version information, an icon and a manifest.
+type WindowsResources struct {
+	Icon            string `yaml:"icon,omitempty"`
+	Manifest        string `yaml:"manifest,omitempty"`
+	ProductName     string `yaml:"product_name,omitempty"`
+	ProductVersion  string `yaml:"product_version,omitempty"`
+	FileVersion     string `yaml:"file_version,omitempty"`
+	FileDescription string `yaml:"file_description,omitempty"`
+	CompanyName     string `yaml:"company_name,omitempty"`
+	Copyright       string `yaml:"copyright,omitempty"`
 }
 
 type BuildHookConfig struct {
@@ -384,6 +619,7 @@ type File struct {
 	Destination string   `yaml:"dst,omitempty"`
 	StripParent bool     `yaml:"strip_parent,omitempty"`
 	Info        FileInfo `yaml:"info,omitempty"`
+	Template    bool     `yaml:"template,omitempty"`
 }
 
 // FileInfo is the file info of a file.
@@ -447,10 +683,20 @@ type Archive struct {
 	NameTemplate              string            `yaml:"name_template,omitempty"`
 	Replacements              map[string]string `yaml:"replacements,omitempty"`
 	Format                    string            `yaml:"format,omitempty"`
+	CompressionLevel          int               `yaml:"compression_level,omitempty"`
 	FormatOverrides           []FormatOverride  `yaml:"format_overrides,omitempty"`
 	WrapInDirectory           string            `yaml:"wrap_in_directory,omitempty"`
 	Files                     []File            `yaml:"files,omitempty"`
 	AllowDifferentBinaryCount bool              `yaml:"allow_different_binary_count,omitempty"`
+	Reproducible              bool              `yaml:"reproducible,omitempty"`
+	Makeself                  Makeself          `yaml:"makeself,omitempty"`
+}
+
+// Makeself is the configuration of self-extracting run and sh archives.
+type Makeself struct {
+	Prefix      string `yaml:"prefix,omitempty"`
+	License     string `yaml:"license,omitempty"`
+	PostInstall string `yaml:"post_install,omitempty"`
 }
 
 type ReleaseNotesMode string
@@ -499,18 +745,56 @@ type NFPM struct {
 	NFPMOverridables `yaml:",inline"`
 	Overrides        map[string]NFPMOverridables `yaml:"overrides,omitempty"`
 
-	ID          string   `yaml:"id,omitempty"`
-	Builds      []string `yaml:"builds,omitempty"`
-	Formats     []string `yaml:"formats,omitempty"`
-	Section     string   `yaml:"section,omitempty"`
-	Priority    string   `yaml:"priority,omitempty"`
-	Vendor      string   `yaml:"vendor,omitempty"`
-	Homepage    string   `yaml:"homepage,omitempty"`
-	Maintainer  string   `yaml:"maintainer,omitempty"`
-	Description string   `yaml:"description,omitempty"`
-	License     string   `yaml:"license,omitempty"`
-	Bindir      string   `yaml:"bindir,omitempty"`
-	Meta        bool     `yaml:"meta,omitempty"` // make package without binaries - only deps
+	ID          string        `yaml:"id,omitempty"`
+	Builds      []string      `yaml:"builds,omitempty"`
+	Formats     []string      `yaml:"formats,omitempty"`
+	Section     string        `yaml:"section,omitempty"`
+	Priority    string        `yaml:"priority,omitempty"`
+	Vendor      string        `yaml:"vendor,omitempty"`
+	Homepage    string        `yaml:"homepage,omitempty"`
+	Maintainer  string        `yaml:"maintainer,omitempty"`
+	Description string        `yaml:"description,omitempty"`
+	License     string        `yaml:"license,omitempty"`
+	Bindir      string        `yaml:"bindir,omitempty"`
+	Meta        bool          `yaml:"meta,omitempty"` // make package without binaries - only deps
+	Changelog   NFPMChangelog `yaml:"changelog,omitempty"`
+	Systemd     []NFPMSystemd `yaml:"systemd,omitempty"`
+	Lint        NFPMLint      `yaml:"lint,omitempty"`
+}
+
+// NFPMLint configures the checks of the deb and rpm packages.
+type NFPMLint struct {
+	Enabled bool     `yaml:"enabled,omitempty"`
+	Fail    bool     `yaml:"fail,omitempty"`
+	Ignore  []string `yaml:"ignore,omitempty"`
+}
+
+// NFPMSystemd is a systemd service installed by a linux package.
+type NFPMSystemd struct {
+	Name            string               `yaml:"name,omitempty"`
+	Description     string               `yaml:"description,omitempty"`
+	Binary          string               `yaml:"binary,omitempty"`
+	Args            []string             `yaml:"args,omitempty"`
+	User            string               `yaml:"user,omitempty"`
+	Group           string               `yaml:"group,omitempty"`
+	Restart         string               `yaml:"restart,omitempty"`
+	EnvironmentFile string               `yaml:"environment_file,omitempty"`
+	Hardening       NFPMSystemdHardening `yaml:"hardening,omitempty"`
+}
+
+// NFPMSystemdHardening are the sandboxing options of a systemd service.
+type NFPMSystemdHardening struct {
+	NoNewPrivileges bool     `yaml:"no_new_privileges,omitempty"`
+	PrivateTmp      bool     `yaml:"private_tmp,omitempty"`
+	ProtectHome     bool     `yaml:"protect_home,omitempty"`
+	ProtectSystem   string   `yaml:"protect_system,omitempty"`
+	ReadWritePaths  []string `yaml:"read_write_paths,omitempty"`
+}
+
+// NFPMChangelog configures the changelog embedded in the deb and rpm packages.
+type NFPMChangelog struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	File    string `yaml:"file,omitempty"`
 }
 
 // NFPMScripts is used to specify maintainer scripts.
@@ -610,15 +894,61 @@ type NFPMOverridables struct {
 	Recommends       []string          `yaml:"recommends,omitempty"`
 	Suggests         []string          `yaml:"suggests,omitempty"`
 	Conflicts        []string          `yaml:"conflicts,omitempty"`
+	Provides         []string          `yaml:"provides,omitempty"`
 	Replaces         []string          `yaml:"replaces,omitempty"`
 	EmptyFolders     []string          `yaml:"empty_folders,omitempty"` // deprecated
-	Contents         files.Contents    `yaml:"contents,omitempty"`
+	Contents         NFPMContents      `yaml:"contents,omitempty"`
 	Scripts          NFPMScripts       `yaml:"scripts,omitempty"`
 	RPM              NFPMRPM           `yaml:"rpm,omitempty"`
 	Deb              NFPMDeb           `yaml:"deb,omitempty"`
 	APK              NFPMAPK           `yaml:"apk,omitempty"`
 }
 
+// NFPMContent is a file or directory to be added to a linux package.
+type NFPMContent struct {
+	Source      string                 `yaml:"src,omitempty"`
+	Destination string                 `yaml:"dst,omitempty"`
+	Type        string                 `yaml:"type,omitempty"`
+	Packager    string                 `yaml:"packager,omitempty"`
+	FileInfo    *files.ContentFileInfo `yaml:"file_info,omitempty"`
+	Template    bool                   `yaml:"template,omitempty"`
+}
+
+// NFPMContents is a list of NFPMContent.
+type NFPMContents []*NFPMContent
+
+// LinuxRepo is the configuration of apt and yum repositories created from
+// linux packages.
+type LinuxRepo struct {
+	ID           string             `yaml:"id,omitempty"`
+	IDs          []string           `yaml:"ids,omitempty"`
+	NameTemplate string             `yaml:"name_template,omitempty"`
+	Index        string             `yaml:"index,omitempty"`
+	Apt          LinuxRepoApt       `yaml:"apt,omitempty"`
+	Yum          LinuxRepoYum       `yaml:"yum,omitempty"`
+	Signature    LinuxRepoSignature `yaml:"signature,omitempty"`
+}
+
+// LinuxRepoApt is the configuration of an apt repository.
+type LinuxRepoApt struct {
+	Suite       string `yaml:"suite,omitempty"`
+	Component   string `yaml:"component,omitempty"`
+	Origin      string `yaml:"origin,omitempty"`
+	Label       string `yaml:"label,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// LinuxRepoYum is the configuration of a yum repository.
+type LinuxRepoYum struct {
+	Folder string `yaml:"folder,omitempty"`
+}
+
+// LinuxRepoSignature is the configuration of the repository metadata
+// signature.
+type LinuxRepoSignature struct {
+	KeyFile string `yaml:"key_file,omitempty"`
+}
+
 // SBOM config.
 type SBOM struct {
 	ID        string   `yaml:"id,omitempty"`
@@ -645,6 +975,18 @@ type Sign struct {
 	Output      bool     `yaml:"output,omitempty"`
 }
 
+// WindowsSign config, signs windows binaries with Authenticode.
+type WindowsSign struct {
+	ID           string   `yaml:"id,omitempty"`
+	IDs          []string `yaml:"ids,omitempty"`
+	Certificate  string   `yaml:"certificate,omitempty"`
+	Key          string   `yaml:"key,omitempty"`
+	Password     string   `yaml:"password,omitempty"`
+	TimestampURL string   `yaml:"timestamp_url,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	URL          string   `yaml:"url,omitempty"`
+}
+
 // SnapcraftAppMetadata for the binaries that will be in the snap package.
 type SnapcraftAppMetadata struct {
 	Plugs            []string
@@ -823,12 +1165,19 @@ type Project struct {
 	Release         Release          `yaml:"release,omitempty"`
 	Milestones      []Milestone      `yaml:"milestones,omitempty"`
 	Brews           []Homebrew       `yaml:"brews,omitempty"`
+	HomebrewCasks   []HomebrewCask   `yaml:"homebrew_casks,omitempty"`
 	Rigs            []GoFish         `yaml:"rigs,omitempty"`
 	Krews           []Krew           `yaml:"krews,omitempty"`
-	Scoop           Scoop            `yaml:"scoop,omitempty"`
+	Scoop           Scoop            `yaml:"scoop,omitempty"` // deprecated
+	Scoops          []Scoop          `yaml:"scoops,omitempty"`
+	Winget          []Winget         `yaml:"winget,omitempty"`
+	AURs            []AUR            `yaml:"aurs,omitempty"`
+	Nix             []Nix            `yaml:"nix,omitempty"`
+	Chocolateys     []Chocolatey     `yaml:"chocolateys,omitempty"`
 	Builds          []Build          `yaml:"builds,omitempty"`
 	Archives        []Archive        `yaml:"archives,omitempty"`
 	NFPMs           []NFPM           `yaml:"nfpms,omitempty"`
+	LinuxRepos      []LinuxRepo      `yaml:"linux_repos,omitempty"`
 	Snapcrafts      []Snapcraft      `yaml:"snapcrafts,omitempty"`
 	Snapshot        Snapshot         `yaml:"snapshot,omitempty"`
 	Checksum        Checksum         `yaml:"checksum,omitempty"`
@@ -842,6 +1191,7 @@ type Project struct {
 	Dist            string           `yaml:"dist,omitempty"`
 	Signs           []Sign           `yaml:"signs,omitempty"`
 	DockerSigns     []Sign           `yaml:"docker_signs,omitempty"`
+	WindowsSigns    []WindowsSign    `yaml:"windows_signs,omitempty"`
 	EnvFiles        EnvFiles         `yaml:"env_files,omitempty"`
 	Before          Before           `yaml:"before,omitempty"`
 	Source          Source           `yaml:"source,omitempty"`